@@ -0,0 +1,42 @@
+// pocgen 根据 .pocschema 文件生成 Go struct 以及不使用反射的编解码代码
+//
+//	pocgen -pkg model -o model_poc.go model.pocschema
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/shinyes/poculum-go/pkg/schema"
+)
+
+func main() {
+	pkg := flag.String("pkg", "main", "生成代码的包名")
+	out := flag.String("o", "", "输出文件，默认输出到标准输出")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: pocgen [-pkg name] [-o file] schema.pocschema")
+		os.Exit(2)
+	}
+
+	s, err := schema.ParseFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := schema.Generate(s, *pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
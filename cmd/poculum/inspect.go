@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// inspect 列出编码数据的结构
+/*
+	每行一段：偏移、长度、按照深度缩进的类型、路径与预览，map 的键标记为 key。
+文件为 - 时从标准输入读取，使用 -hex 时输入是十六进制文本（格式见 poculum.FromHex），使用 -json 时输出 JSON 数组
+*/
+func inspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	hexInput := fs.Bool("hex", false, "输入是十六进制文本")
+	jsonOutput := fs.Bool("json", false, "输出 JSON")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("usage: poculum inspect [-hex] [-json] <file|->")
+	}
+
+	var data []byte
+	var err error
+	if fs.Arg(0) == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	if *hexInput {
+		if data, err = poculum.FromHex(string(data)); err != nil {
+			return err
+		}
+	}
+
+	segments := poculum.Explain(data)
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(segments); err != nil {
+			return err
+		}
+	} else {
+		writeSegments(os.Stdout, segments)
+	}
+	if n := len(segments); n > 0 && segments[n-1].Kind == poculum.SegmentInvalid {
+		return fmt.Errorf("invalid data at offset %d", segments[n-1].Offset)
+	}
+	return nil
+}
+
+// writeSegments 以表格的形式写入 segments
+func writeSegments(w io.Writer, segments []poculum.Segment) {
+	for _, s := range segments {
+		typ := strings.Repeat("  ", s.Depth) + s.Type
+		if s.Kind != poculum.SegmentValue {
+			typ = strings.Repeat("  ", s.Depth) + s.Kind
+		}
+		path := s.Path
+		if path == "" {
+			path = "(root)"
+		}
+		fmt.Fprintf(w, "%08x %6d  %-20s %-24s %s\n", s.Offset, s.Length, typ, path, s.Preview)
+	}
+}
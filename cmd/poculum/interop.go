@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// checkInterop 检查其他实现生成的数据
+/*
+	输入文件每行一个十六进制编码的值，空行与 # 开头的行被忽略，行内可以使用 "名称: hex" 的形式，hex 中可以包含空白。
+对每个值：解码，再使用规范编码重新编码，与原始字节比较：
+
+	OK    与规范编码完全一致
+	DIFF  可以解码并且语义一致，但字节与规范编码不同（例如 map 的键没有排序），报告第一个不同的偏移
+	FAIL  无法解码，或者重新编码后再解码得到不同的值
+
+存在 FAIL 时退出码为 1，使用 -strict 时 DIFF 也视为失败
+*/
+func checkInterop(args []string) error {
+	fs := flag.NewFlagSet("check-interop", flag.ExitOnError)
+	strict := fs.Bool("strict", false, "字节与规范编码不同也视为失败")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("usage: poculum check-interop [-strict] <hexfile>")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	failed, err := runInterop(f, os.Stdout, *strict)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d payloads failed", failed)
+	}
+	return nil
+}
+
+// runInterop 检查 r 中的每个值并把结果写入 w，返回失败的个数
+func runInterop(r io.Reader, w io.Writer, strict bool) (int, error) {
+	poc := poculum.NewPoculum(poculum.WithCanonical())
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+
+	failed, total, line := 0, 0, 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name := fmt.Sprintf("line %d", line)
+		if label, payload, ok := strings.Cut(text, ":"); ok {
+			name, text = strings.TrimSpace(label), strings.TrimSpace(payload)
+		}
+		total++
+
+		status, detail := checkPayload(poc, text)
+		if status == "FAIL" || (strict && status == "DIFF") {
+			failed++
+		}
+		if detail != "" {
+			detail = ": " + detail
+		}
+		fmt.Fprintf(w, "%-4s %s%s\n", status, name, detail)
+	}
+	if err := scanner.Err(); err != nil {
+		return failed, err
+	}
+	fmt.Fprintf(w, "%d payloads, %d failed\n", total, failed)
+	return failed, nil
+}
+
+// checkPayload 检查一个十六进制编码的值，返回状态与说明
+func checkPayload(poc *poculum.Poculum, text string) (string, string) {
+	data, err := hex.DecodeString(strings.Join(strings.Fields(text), ""))
+	if err != nil {
+		return "FAIL", fmt.Sprintf("invalid hex: %v", err)
+	}
+	value, err := poc.Load(data)
+	if err != nil {
+		return "FAIL", fmt.Sprintf("decode: %v", err)
+	}
+	canonical, err := poc.Dump(value)
+	if err != nil {
+		return "FAIL", fmt.Sprintf("re-encode: %v", err)
+	}
+
+	// 重新编码的结果再次解码、编码，字节不同说明往返过程中值发生了变化
+	again, err := poc.Load(canonical)
+	if err != nil {
+		return "FAIL", fmt.Sprintf("decode re-encoded: %v", err)
+	}
+	stable, err := poc.Dump(again)
+	if err != nil {
+		return "FAIL", fmt.Sprintf("re-encode: %v", err)
+	}
+	if !bytes.Equal(stable, canonical) {
+		return "FAIL", fmt.Sprintf("value changed after round trip at offset %d", firstDiff(stable, canonical))
+	}
+
+	if !bytes.Equal(data, canonical) {
+		offset := firstDiff(data, canonical)
+		return "DIFF", fmt.Sprintf("%d bytes, canonical %d bytes, first difference at offset %d (got %s, canonical %s)",
+			len(data), len(canonical), offset, byteAt(data, offset), byteAt(canonical, offset))
+	}
+	return "OK", ""
+}
+
+// firstDiff 返回 a 与 b 第一个不同的字节的偏移
+func firstDiff(a, b []byte) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// byteAt 返回偏移处字节的十六进制表示，超出范围时返回 EOF
+func byteAt(data []byte, offset int) string {
+	if offset >= len(data) {
+		return "EOF"
+	}
+	return fmt.Sprintf("0x%02x", data[offset])
+}
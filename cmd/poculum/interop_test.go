@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunInterop(t *testing.T) {
+	input := strings.Join([]string{
+		"# 注释与空行被忽略",
+		"",
+		"canonical: 72 3161 0101 3162 0102",
+		"unsorted: 72 3162 0102 3161 0101",
+		"truncated: 72 3161 0101",
+		"bad hex: zz",
+	}, "\n")
+
+	var out strings.Builder
+	failed, err := runInterop(strings.NewReader(input), &out, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{"OK   canonical", "DIFF unsorted", "FAIL truncated", "FAIL bad hex"}
+	if failed != 2 || len(lines) != len(want)+1 {
+		t.Fatalf("failed = %d, output:\n%s", failed, out.String())
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+	if !strings.Contains(lines[1], "first difference at offset 2") {
+		t.Errorf("unexpected DIFF detail: %q", lines[1])
+	}
+
+	failed, err = runInterop(strings.NewReader("unsorted: 72 3162 0102 3161 0101"), &out, true)
+	if err != nil || failed != 1 {
+		t.Fatalf("strict: failed = %d, %v", failed, err)
+	}
+}
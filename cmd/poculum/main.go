@@ -0,0 +1,49 @@
+// poculum 命令行工具
+//
+//	poculum gen-vectors [-o vectors.json]          生成供其他语言的实现使用的测试向量
+//	poculum check-interop [-strict] payloads.hex   检查其他实现生成的数据
+//	poculum inspect [-hex] [-json] data.poc        列出编码数据中每个值的偏移、长度与类型
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command 子命令
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"gen-vectors", "生成 JSON 格式的测试向量", genVectors},
+	{"check-interop", "检查其他实现生成的数据与规范编码是否一致", checkInterop},
+	{"inspect", "列出编码数据的结构", inspect},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: poculum <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.usage)
+	}
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "poculum %s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// 测试向量格式
+/*
+	{
+	  "version": 1,
+	  "categories": {
+	    "integers": [{"name": "uint8_max", "type": "uint8", "value": 255, "hex": "01ff"}, ...],
+	    ...
+	  }
+	}
+
+hex 是使用规范编码（map 按键排序）得到的字节，其他实现解码 hex 应当得到 value，
+按照 type 编码 value 应当得到 hex。value 的 JSON 表示：
+字节数据为十六进制字符串，NaN 与正负无穷为字符串 "NaN"、"+Inf"、"-Inf"，
+-0.0 为字符串 "-0"，较长的字符串、字节数据与 list 使用 {"repeat": 元素, "count": 个数} 表示。
+扩展类型中 IP 地址、前缀、URL、有理数与 JSON 片段为文本，时间为 RFC 3339 字符串，
+整数键的 map 的键为十进制字符串，张量为 {"shape", "data"}，高精度浮点数为 {"prec", "text"}
+*/
+
+// vector 一个测试向量
+type vector struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+	Hex   string `json:"hex"`
+
+	value any                    // 用于编码的 Go 值
+	opts  []poculum.ConfigOption // 编码时额外使用的配置，例如稀疏 list
+}
+
+// repeat 较长的重复内容的 JSON 表示
+type repeat struct {
+	Repeat any `json:"repeat"`
+	Count  int `json:"count"`
+}
+
+// repeatThreshold 超过该长度的字符串、字节数据与 list 使用 repeat 表示
+const repeatThreshold = 64
+
+func genVectors(args []string) error {
+	fs := flag.NewFlagSet("gen-vectors", flag.ExitOnError)
+	out := fs.String("o", "", "输出文件，默认输出到标准输出")
+	fs.Parse(args)
+
+	categories := buildVectors()
+	poc := poculum.NewPoculum(poculum.WithCanonical())
+	for name, vectors := range categories {
+		for i := range vectors {
+			enc := poc
+			if len(vectors[i].opts) > 0 {
+				enc = poc.With(vectors[i].opts...)
+			}
+			data, err := enc.Dump(vectors[i].value)
+			if err != nil {
+				return fmt.Errorf("%s/%s: %w", name, vectors[i].Name, err)
+			}
+			vectors[i].Hex = hex.EncodeToString(data)
+		}
+	}
+
+	data, err := json.MarshalIndent(map[string]any{"version": 1, "categories": categories}, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
+
+// buildVectors 按照类别生成测试向量
+func buildVectors() map[string][]vector {
+	v := map[string][]vector{}
+	add := func(category, name, typ string, value, jsonValue any, opts ...poculum.ConfigOption) {
+		v[category] = append(v[category], vector{Name: name, Type: typ, Value: jsonValue, value: value, opts: opts})
+	}
+
+	// 整数，包括每种宽度的边界
+	add("integers", "uint8_zero", "uint8", uint8(0), 0)
+	add("integers", "uint8_max", "uint8", uint8(math.MaxUint8), math.MaxUint8)
+	add("integers", "uint16_min_above_uint8", "uint16", uint16(math.MaxUint8+1), math.MaxUint8+1)
+	add("integers", "uint16_max", "uint16", uint16(math.MaxUint16), math.MaxUint16)
+	add("integers", "uint32_max", "uint32", uint32(math.MaxUint32), uint64(math.MaxUint32))
+	add("integers", "uint64_max", "uint64", uint64(math.MaxUint64), uint64(math.MaxUint64))
+	add("integers", "int8_min", "int8", int8(math.MinInt8), math.MinInt8)
+	add("integers", "int8_max", "int8", int8(math.MaxInt8), math.MaxInt8)
+	add("integers", "int16_min", "int16", int16(math.MinInt16), math.MinInt16)
+	add("integers", "int16_max", "int16", int16(math.MaxInt16), math.MaxInt16)
+	add("integers", "int32_min", "int32", int32(math.MinInt32), math.MinInt32)
+	add("integers", "int32_max", "int32", int32(math.MaxInt32), math.MaxInt32)
+	add("integers", "int64_min", "int64", int64(math.MinInt64), int64(math.MinInt64))
+	add("integers", "int64_max", "int64", int64(math.MaxInt64), int64(math.MaxInt64))
+
+	// 浮点数
+	add("floats", "float32_one_and_half", "float32", float32(1.5), 1.5)
+	add("floats", "float64_pi", "float64", math.Pi, math.Pi)
+	add("floats", "float64_negative_zero", "float64", math.Copysign(0, -1), "-0")
+	add("floats", "float64_positive_inf", "float64", math.Inf(1), "+Inf")
+	add("floats", "float64_negative_inf", "float64", math.Inf(-1), "-Inf")
+	add("floats", "float64_nan", "float64", math.NaN(), "NaN")
+	add("floats", "float64_max", "float64", math.MaxFloat64, math.MaxFloat64)
+	add("floats", "float64_smallest_subnormal", "float64", math.SmallestNonzeroFloat64, math.SmallestNonzeroFloat64)
+
+	// 常量
+	add("constants", "true", "bool", true, true)
+	add("constants", "false", "bool", false, false)
+	add("constants", "nil", "nil", nil, nil)
+
+	// 字符串，长度位于 fixstring、string16、string32 的边界
+	for _, n := range []int{0, 1, 15, 16, 255, 256, math.MaxUint16, math.MaxUint16 + 1} {
+		s := strings.Repeat("a", n)
+		add("strings", fmt.Sprintf("string_len_%d", n), "string", s, repeated(s, "a", n))
+	}
+	add("strings", "string_utf8_multibyte", "string", "你好，世界 🌍", "你好，世界 🌍")
+
+	// 字节数据，长度位于 bytes8、bytes16、bytes32 的边界
+	for _, n := range []int{0, 1, 255, 256, math.MaxUint16, math.MaxUint16 + 1} {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = 0xAB
+		}
+		add("bytes", fmt.Sprintf("bytes_len_%d", n), "bytes", b, repeated(hex.EncodeToString(b), "ab", n))
+	}
+
+	// list，元素个数位于 fixlist、list16、list32 的边界
+	for _, n := range []int{0, 1, 15, 16, math.MaxUint16, math.MaxUint16 + 1} {
+		items := make([]any, n)
+		for i := range items {
+			items[i] = uint8(0)
+		}
+		var jsonValue any = make([]int, n)
+		if n > repeatThreshold {
+			jsonValue = repeat{Repeat: 0, Count: n}
+		}
+		add("lists", fmt.Sprintf("list_len_%d", n), "list<uint8>", items, jsonValue)
+	}
+	add("lists", "list_mixed", "list", []any{uint8(1), "a", true, nil, 1.5}, []any{1, "a", true, nil, 1.5})
+
+	// map，元素个数位于 fixmap、map16、map32 的边界
+	for _, n := range []int{0, 1, 15, 16, 256, math.MaxUint16 + 1} {
+		m := make(map[string]any, n)
+		jm := make(map[string]int, n)
+		for i := 0; i < n; i++ {
+			key := fmt.Sprintf("k%03d", i)
+			m[key] = uint8(i % 256)
+			jm[key] = i % 256
+		}
+		add("maps", fmt.Sprintf("map_len_%d", n), "map<string,uint8>", m, jm)
+	}
+
+	// 嵌套
+	for _, depth := range []int{1, 16, 64, 128} {
+		var value any = uint8(0)
+		var jsonValue any = 0
+		for i := 0; i < depth; i++ {
+			value = []any{value}
+			jsonValue = []any{jsonValue}
+		}
+		add("nesting", fmt.Sprintf("nested_list_depth_%d", depth), "list", value, jsonValue)
+	}
+
+	// 扩展类型
+	add("extensions", "set", "set<string>", poculum.Set{"a", "b"}, []string{"a", "b"})
+	add("extensions", "tuple", "tuple<uint8,string>", poculum.Tuple{uint8(1), "x"}, []any{1, "x"})
+	add("extensions", "sparse", "sparse<uint8>",
+		[]any{uint8(0), uint8(0), uint8(0), uint8(7), uint8(0), uint8(0), uint8(0), uint8(0), uint8(0), uint8(9)},
+		[]int{0, 0, 0, 7, 0, 0, 0, 0, 0, 9}, poculum.WithSparseLists(0.5))
+	bits := []bool{true, false, true, true, false, false, false, false, true}
+	add("extensions", "bitset", "bitset", bits, bits)
+	tensor, err := poculum.NewTensorFloat32([]int{2, 2}, []float32{1, 2, 3, 4})
+	if err != nil {
+		panic(err)
+	}
+	add("extensions", "tensor", "tensor<float32>", tensor, map[string]any{"shape": []int{2, 2}, "data": []float32{1, 2, 3, 4}})
+	add("extensions", "addr_ipv4", "addr", netip.MustParseAddr("192.0.2.1"), "192.0.2.1")
+	add("extensions", "addr_ipv6", "addr", netip.MustParseAddr("2001:db8::1"), "2001:db8::1")
+	add("extensions", "prefix", "prefix", netip.MustParsePrefix("10.0.0.0/8"), "10.0.0.0/8")
+	u, err := url.Parse("https://example.com/a?b=c#d")
+	if err != nil {
+		panic(err)
+	}
+	add("extensions", "url", "url", u, u.String())
+	ts := time.Unix(1700000000, 123456789).UTC()
+	add("extensions", "time", "time", ts, ts.Format(time.RFC3339Nano))
+	f := new(big.Float).SetPrec(100).SetFloat64(1.5)
+	add("extensions", "bigfloat", "bigfloat", f, map[string]any{"prec": f.Prec(), "text": f.Text('p', 0)})
+	add("extensions", "bigrat", "bigrat", big.NewRat(1, 3), "1/3")
+	add("extensions", "intmap", "intmap<string>", map[int64]any{-1: "a", 2: "b", 300: "c"}, map[string]string{"-1": "a", "2": "b", "300": "c"})
+	add("extensions", "json", "json", json.RawMessage(`{"a":[1,2]}`), `{"a":[1,2]}`)
+	return v
+}
+
+// repeated 返回长度为 n 的重复内容的 JSON 表示，较短时直接使用 full
+func repeated(full string, unit string, n int) any {
+	if n > repeatThreshold {
+		return repeat{Repeat: unit, Count: n}
+	}
+	return full
+}
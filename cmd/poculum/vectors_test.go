@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+func TestGenVectors(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "vectors.json")
+	if err := genVectors([]string{"-o", out}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file struct {
+		Version    int                 `json:"version"`
+		Categories map[string][]vector `json:"categories"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+
+	// 每个向量都可以被本库解码，并且规范编码与 hex 一致
+	var payloads strings.Builder
+	for category, vectors := range file.Categories {
+		for _, v := range vectors {
+			raw, err := hex.DecodeString(v.Hex)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", category, v.Name, err)
+			}
+			if _, err := poculum.LoadPoculum(raw); err != nil {
+				t.Errorf("%s/%s: %v", category, v.Name, err)
+			}
+			if v.Type == "sparse<uint8>" {
+				// 稀疏编码需要 WithSparseLists，规范编码得到普通的 list
+				if status, detail := checkPayload(poculum.NewPoculum(poculum.WithCanonical()), v.Hex); status != "DIFF" {
+					t.Errorf("%s/%s: %s %s", category, v.Name, status, detail)
+				}
+				continue
+			}
+			fmt.Fprintf(&payloads, "%s: %s\n", v.Name, v.Hex)
+		}
+	}
+
+	var report strings.Builder
+	failed, err := runInterop(strings.NewReader(payloads.String()), &report, true)
+	if err != nil || failed != 0 {
+		t.Fatalf("check-interop: %d failed, %v\n%s", failed, err, report.String())
+	}
+
+	// 每个扩展标识都有测试向量
+	tags := map[string]bool{}
+	for _, v := range file.Categories["extensions"] {
+		tags[v.Hex[2:4]] = true
+	}
+	for tag := 0x01; tag <= 0x0D; tag++ {
+		if !tags[fmt.Sprintf("%02x", tag)] {
+			t.Errorf("no vector for ext tag 0x%02x", tag)
+		}
+	}
+}
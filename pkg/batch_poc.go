@@ -0,0 +1,79 @@
+package poculum
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// runBatch 使用不超过 GOMAXPROCS 个 goroutine 对 0..n-1 调用 fn，
+// 出错后不再开始新的任务，返回下标最小的错误
+func runBatch(n int, fn func(i int) error) error {
+	workers := min(runtime.GOMAXPROCS(0), n)
+	errs := make([]error, n)
+	var next atomic.Int64
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				if errs[i] = fn(i); errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("batch item %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// EncodeBatch 使用默认实例并发编码多个值
+func EncodeBatch(values []any) ([][]byte, error) {
+	return Default().EncodeBatch(values)
+}
+
+// EncodeBatch 并发编码多个值，结果与 values 的顺序一致。
+// 同时运行的 goroutine 不超过 GOMAXPROCS 个，出错时返回下标最小的错误，
+// 可以使用 IsKind 与 errors.As 判断其中的 PoculumError
+func (poc *Poculum) EncodeBatch(values []any) ([][]byte, error) {
+	out := make([][]byte, len(values))
+	err := runBatch(len(values), func(i int) (err error) {
+		out[i], err = poc.dump(values[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecodeBatch 使用默认实例并发解码多个值
+func DecodeBatch(items [][]byte) ([]any, error) {
+	return Default().DecodeBatch(items)
+}
+
+// DecodeBatch 并发解码多个值，结果与 items 的顺序一致，并发与错误的规则与 EncodeBatch 相同
+func (poc *Poculum) DecodeBatch(items [][]byte) ([]any, error) {
+	out := make([]any, len(items))
+	err := runBatch(len(items), func(i int) (err error) {
+		out[i], err = poc.load(items[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,29 @@
+package poculum
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	values := make([]any, 1000)
+	for i := range values {
+		values[i] = map[string]any{"i": uint32(i)}
+	}
+	encoded, err := EncodeBatch(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeBatch(encoded)
+	if err != nil || !reflect.DeepEqual(decoded, values) {
+		t.Fatalf("round trip failed: %v", err)
+	}
+
+	encoded[500] = []byte{0xFF}
+	encoded[700] = []byte{0xFF}
+	_, err = DecodeBatch(encoded)
+	if !IsKind(err, UnknownTypeID) || !strings.Contains(err.Error(), "item 500") {
+		t.Fatalf("expected error for item 500, got %v", err)
+	}
+}
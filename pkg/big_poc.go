@@ -0,0 +1,91 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// 高精度数字扩展类型
+/*
+	*big.Float 编码为带有高精度浮点数扩展标识的 list：[精度, 文本]，
+文本使用 Text('p', 0) 得到的十六进制尾数形式，可以精确还原任意精度的值。
+*big.Rat 编码为带有有理数扩展标识的字符串 "分子/分母"。
+解码分别得到 *big.Float 与 *big.Rat，nil 指针编码为 nil
+*/
+
+// encodeBigFloat 编码 *big.Float
+func (poc *Poculum) encodeBigFloat(f *big.Float, buf *bytes.Buffer, depth int) error {
+	if f == nil {
+		return buf.WriteByte(typeNil)
+	}
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extBigFloat)
+	writeListHeader(buf, 2)
+	if err := poc.encodeValue(f.Prec(), buf, depth+2); err != nil {
+		return err
+	}
+	return poc.encodeString(f.Text('p', 0), buf)
+}
+
+// decodeBigFloat 解码 *big.Float
+func (poc *Poculum) decodeBigFloat(reader *bytes.Reader, depth int) (*big.Float, error) {
+	items, err := expectList(reader, "BigFloat")
+	if err != nil {
+		return nil, err
+	}
+	if items != 2 {
+		return nil, newError(InvalidBigNumber, fmt.Sprintf("BigFloat must contain 2 items, got %d", items))
+	}
+
+	precValue, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	prec, ok := toUint64(precValue)
+	if !ok || prec > big.MaxPrec || prec > math.MaxUint32 {
+		return nil, newError(InvalidBigNumber, fmt.Sprintf("Invalid BigFloat precision: %v", precValue))
+	}
+	textValue, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := textValue.(string)
+	if !ok {
+		return nil, newError(InvalidBigNumber, fmt.Sprintf("BigFloat text must be a string, got %T", textValue))
+	}
+
+	f, _, err := new(big.Float).SetPrec(uint(prec)).Parse(text, 0)
+	if err != nil {
+		return nil, newError(InvalidBigNumber, fmt.Sprintf("Invalid BigFloat %q: %v", text, err))
+	}
+	return f, nil
+}
+
+// encodeBigRat 编码 *big.Rat
+func (poc *Poculum) encodeBigRat(r *big.Rat, buf *bytes.Buffer) error {
+	if r == nil {
+		return buf.WriteByte(typeNil)
+	}
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extBigRat)
+	return poc.encodeString(r.String(), buf)
+}
+
+// decodeBigRat 解码 *big.Rat
+func (poc *Poculum) decodeBigRat(reader *bytes.Reader, depth int) (*big.Rat, error) {
+	value, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := value.(string)
+	if !ok {
+		return nil, newError(InvalidBigNumber, fmt.Sprintf("BigRat must contain a string, got %T", value))
+	}
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, newError(InvalidBigNumber, fmt.Sprintf("Invalid BigRat %q", text))
+	}
+	return r, nil
+}
@@ -0,0 +1,30 @@
+package poculum
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBigNumbers(t *testing.T) {
+	type Measurement struct {
+		Value *big.Float
+		Ratio *big.Rat
+	}
+	value, _, _ := big.ParseFloat("3.14159265358979323846264338327950288419716939937510", 10, 200, big.ToNearestEven)
+	in := Measurement{Value: value, Ratio: big.NewRat(-22, 7)}
+	data, err := DumpPoculum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out Measurement
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Value.Cmp(in.Value) != 0 || out.Value.Prec() != 200 {
+		t.Fatalf("BigFloat: got %s (prec %d)", out.Value.Text('g', 50), out.Value.Prec())
+	}
+	if out.Ratio.Cmp(in.Ratio) != 0 {
+		t.Fatalf("BigRat: got %s", out.Ratio)
+	}
+}
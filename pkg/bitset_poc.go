@@ -0,0 +1,89 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// []bool 的位压缩编码
+/*
+	[]bool 编码为带有位集合扩展标识的 list：[元素个数, 压缩后的字节数据]
+第 i 个元素存放在第 i/8 个字节的第 i%8 位（从低位开始），最后一个字节中多余的位为 0。
+相比每个元素一个类型字节，位压缩编码约小 8 倍，解码时还原为 []bool
+*/
+
+// packBools 把 []bool 压缩为字节数据
+func packBools(bools []bool) []byte {
+	packed := make([]byte, (len(bools)+7)/8)
+	for i, b := range bools {
+		if b {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// encodeBools 使用位压缩编码写入 []bool
+func (poc *Poculum) encodeBools(bools []bool, buf *bytes.Buffer, depth int) error {
+	if len(bools) > poc.maxContainerItems {
+		return newError(DataTooLarge, fmt.Sprintf("Bool slice too long: %d items (max %d)", len(bools), poc.maxContainerItems))
+	}
+
+	if poc.noPacked {
+		writeListHeader(buf, len(bools))
+		for _, b := range bools {
+			if b {
+				buf.WriteByte(typeTrue)
+			} else {
+				buf.WriteByte(typeFalse)
+			}
+		}
+		return nil
+	}
+
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extBitset)
+	writeListHeader(buf, 2)
+	if err := poc.encodeValue(compactUint(len(bools)), buf, depth+2); err != nil {
+		return err
+	}
+	return poc.encodeBytes(packBools(bools), buf)
+}
+
+// decodeBools 解码位压缩的 []bool
+func (poc *Poculum) decodeBools(reader *bytes.Reader, depth int) ([]bool, error) {
+	items, err := expectList(reader, "Bitset")
+	if err != nil {
+		return nil, err
+	}
+	if items != 2 {
+		return nil, newError(InvalidBitset, fmt.Sprintf("Bitset must contain 2 items, got %d", items))
+	}
+
+	countValue, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	count, ok := toUint64(countValue)
+	if !ok {
+		return nil, newError(InvalidBitset, fmt.Sprintf("Bitset count must be an unsigned integer, got %T", countValue))
+	}
+	if count > uint64(poc.maxContainerItems) {
+		return nil, newError(DataTooLarge, fmt.Sprintf("Bitset too large: %d items (max %d)", count, poc.maxContainerItems))
+	}
+
+	packedValue, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	packed, ok := packedValue.([]byte)
+	if !ok || uint64(len(packed)) != (count+7)/8 {
+		return nil, newError(InvalidBitset, fmt.Sprintf("Bitset data does not match count %d", count))
+	}
+
+	bools := make([]bool, count)
+	for i := range bools {
+		bools[i] = packed[i/8]&(1<<(i%8)) != 0
+	}
+	return bools, nil
+}
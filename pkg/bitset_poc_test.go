@@ -0,0 +1,34 @@
+package poculum
+
+import (
+	"testing"
+)
+
+func TestBitset(t *testing.T) {
+	bools := make([]bool, 100)
+	for i := range bools {
+		bools[i] = i%3 == 0
+	}
+	data, err := DumpPoculum(map[string]any{"flags": bools})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > 40 {
+		t.Fatalf("bitset not packed: %d bytes", len(data))
+	}
+
+	value, _ := LoadPoculum(data)
+	got := value.(map[string]any)["flags"].([]bool)
+	for i := range bools {
+		if got[i] != bools[i] {
+			t.Fatalf("mismatch at %d", i)
+		}
+	}
+
+	var out struct {
+		Flags [100]bool `poculum:"flags"`
+	}
+	if err := Unmarshal(data, &out); err != nil || !out.Flags[99] || out.Flags[98] {
+		t.Fatalf("got %v, %v", out, err)
+	}
+}
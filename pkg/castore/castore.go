@@ -0,0 +1,321 @@
+// Package castore 把 poculum 值按照内容的哈希存储，相同的子文档只存储一次
+/*
+	值使用规范编码（map 按键排序），其中编码后不小于 MinChunkSize 字节的 list 与 map
+被单独存储，并在父文档中替换为引用它的扩展类型 Ext{Tag: RefTag, Value: 哈希}，
+所以多个快照之间相同的部分只占用一份空间。存储的值中不能包含扩展标识为 RefTag 的扩展类型，
+否则读取时无法与引用区分，Put 返回 ErrReservedTag。
+
+存储为追加写入的记录：
+
+	[32 字节 SHA-256 哈希][4 字节大端序长度][规范编码的数据]
+
+任何同时实现 io.ReaderAt 与 io.Writer 的存储都可以使用，例如以 O_APPEND 打开的 *os.File：
+
+	f, _ := os.OpenFile("snapshots.cas", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	info, _ := f.Stat()
+	store, err := castore.Open(f, info.Size())
+	h, err := store.Put(state)
+	data, err := store.Get(h) // 还原后的完整编码数据
+*/
+package castore
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// RefTag 子文档引用使用的扩展标识，位于应用自定义的范围内
+const RefTag = 0xC5
+
+// MinChunkSize 单独存储的子文档的最小字节数，更小的子文档直接内联在父文档中
+const MinChunkSize = 64
+
+// recordHeaderSize 记录头部的字节数
+const recordHeaderSize = sha256.Size + 4
+
+// maxRefDepth 还原引用时的最大嵌套深度
+const maxRefDepth = 1024
+
+// Hash 值的 SHA-256 哈希
+type Hash [sha256.Size]byte
+
+// String 返回十六进制表示
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// ParseHash 解析十六进制表示的哈希
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("castore: invalid hash %q", s)
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// ErrNotFound 存储中没有该哈希
+var ErrNotFound = errors.New("castore: not found")
+
+// ErrReservedTag 值中包含扩展标识为 RefTag 的扩展类型
+var ErrReservedTag = errors.New("castore: value uses the reference ext tag")
+
+// Backend 存储的后端
+type Backend interface {
+	io.ReaderAt
+	io.Writer
+}
+
+// location 记录中数据的位置
+type location struct {
+	offset int64
+	length int
+}
+
+// Store 内容寻址的存储，可以并发使用
+type Store struct {
+	mu      sync.RWMutex
+	backend Backend
+	size    int64 // 后端中已有数据的字节数
+	index   map[Hash]location
+	poc     *poculum.Poculum
+	err     error // 后端写入了不完整的记录，之后的写入都返回该错误
+}
+
+// Open 打开 backend 中前 size 个字节的已有记录并建立索引，新记录追加在其后
+func Open(backend Backend, size int64) (*Store, error) {
+	s := &Store{
+		backend: backend,
+		index:   make(map[Hash]location),
+		poc:     poculum.NewPoculum(poculum.WithCanonical()),
+	}
+
+	var header [recordHeaderSize]byte
+	for s.size < size {
+		if _, err := backend.ReadAt(header[:], s.size); err != nil {
+			return nil, fmt.Errorf("castore: read record at %d: %w", s.size, err)
+		}
+		var h Hash
+		copy(h[:], header[:sha256.Size])
+		length := int(binary.BigEndian.Uint32(header[sha256.Size:]))
+		offset := s.size + recordHeaderSize
+		if offset+int64(length) > size {
+			return nil, fmt.Errorf("castore: truncated record at %d", s.size)
+		}
+		s.index[h] = location{offset: offset, length: length}
+		s.size = offset + int64(length)
+	}
+	return s, nil
+}
+
+// Has 判断存储中是否有该哈希
+func (s *Store) Has(h Hash) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.index[h]
+	return ok
+}
+
+// Len 返回存储中的记录数
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.index)
+}
+
+// Put 存储一个值，返回它的哈希。相同的值总是得到相同的哈希，已经存在的内容不会重复写入
+func (s *Store) Put(value any) (Hash, error) {
+	data, err := s.poc.Dump(value)
+	if err != nil {
+		return Hash{}, err
+	}
+	tree, err := s.poc.Load(data)
+	if err != nil {
+		return Hash{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chunked, err := s.chunk(tree)
+	if err != nil {
+		return Hash{}, err
+	}
+	data, err = s.poc.Dump(chunked)
+	if err != nil {
+		return Hash{}, err
+	}
+	return s.write(data)
+}
+
+// chunk 把足够大的子文档单独存储，并替换为引用
+func (s *Store) chunk(value any) (any, error) {
+	var node any
+	switch v := value.(type) {
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			chunked, err := s.chunk(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = chunked
+		}
+		node = items
+	case map[string]any:
+		obj := make(map[string]any, len(v))
+		for key, item := range v {
+			chunked, err := s.chunk(item)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = chunked
+		}
+		node = obj
+	case poculum.Ext:
+		if v.Tag == RefTag {
+			return nil, ErrReservedTag
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+
+	data, err := s.poc.Dump(node)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < MinChunkSize {
+		return node, nil
+	}
+	h, err := s.write(data)
+	if err != nil {
+		return nil, err
+	}
+	return poculum.Ext{Tag: RefTag, Value: h[:]}, nil
+}
+
+// write 写入一条记录，内容已经存在时直接返回哈希，调用时需要持有写锁
+func (s *Store) write(data []byte) (Hash, error) {
+	h := Hash(sha256.Sum256(data))
+	if _, ok := s.index[h]; ok {
+		return h, nil
+	}
+	if s.err != nil {
+		return Hash{}, s.err
+	}
+
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(data))
+	copy(record, h[:])
+	binary.BigEndian.PutUint32(record[sha256.Size:], uint32(len(data)))
+	record = append(record, data...)
+	n, err := s.backend.Write(record)
+	if err == nil && n < len(record) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		err = fmt.Errorf("castore: write record: %w", err)
+		if n > 0 {
+			// 后端中的数据与 size 不再一致，之后的记录的偏移都是错误的
+			s.err = err
+		}
+		return Hash{}, err
+	}
+
+	s.index[h] = location{offset: s.size + recordHeaderSize, length: len(data)}
+	s.size += int64(len(record))
+	return h, nil
+}
+
+// read 读取一条记录的数据
+func (s *Store) read(h Hash) ([]byte, error) {
+	s.mu.RLock()
+	loc, ok := s.index[h]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, h)
+	}
+
+	data := make([]byte, loc.length)
+	if _, err := s.backend.ReadAt(data, loc.offset); err != nil {
+		return nil, fmt.Errorf("castore: read %s: %w", h, err)
+	}
+	if Hash(sha256.Sum256(data)) != h {
+		return nil, fmt.Errorf("castore: checksum mismatch for %s", h)
+	}
+	return data, nil
+}
+
+// resolve 把引用替换为存储中的子文档
+func (s *Store) resolve(value any, depth int) (any, error) {
+	if depth > maxRefDepth {
+		return nil, fmt.Errorf("castore: references nested deeper than %d", maxRefDepth)
+	}
+
+	switch v := value.(type) {
+	case poculum.Ext:
+		ref, ok := v.Value.([]byte)
+		if v.Tag != RefTag || !ok || len(ref) != sha256.Size {
+			return value, nil
+		}
+		data, err := s.read(Hash(ref))
+		if err != nil {
+			return nil, err
+		}
+		child, err := s.poc.Load(data)
+		if err != nil {
+			return nil, err
+		}
+		return s.resolve(child, depth+1)
+	case []any:
+		for i, item := range v {
+			resolved, err := s.resolve(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+	case map[string]any:
+		for key, item := range v {
+			resolved, err := s.resolve(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+	}
+	return value, nil
+}
+
+// Get 返回哈希对应的值的完整规范编码，其中的引用都已经还原
+func (s *Store) Get(h Hash) ([]byte, error) {
+	data, err := s.read(h)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := s.poc.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := s.resolve(tree, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.poc.Dump(resolved)
+}
+
+// Load 把哈希对应的值解码到 dst 中
+func (s *Store) Load(h Hash, dst any) error {
+	data, err := s.Get(h)
+	if err != nil {
+		return err
+	}
+	return s.poc.Unmarshal(data, dst)
+}
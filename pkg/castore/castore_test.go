@@ -0,0 +1,146 @@
+package castore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// memBackend 内存中的存储后端
+type memBackend struct {
+	data []byte
+}
+
+func (m *memBackend) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *memBackend) Write(p []byte) (int, error) {
+	m.data = append(m.data, p...)
+	return len(p), nil
+}
+
+func TestStoreDedup(t *testing.T) {
+	backend := &memBackend{}
+	store, err := Open(backend, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	world := map[string]any{"terrain": strings.Repeat("grass ", 20), "seed": uint32(42)}
+	first, err := store.Put(map[string]any{"tick": uint8(1), "world": world})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, size := store.Len(), len(backend.data)
+
+	second, err := store.Put(map[string]any{"tick": uint8(2), "world": world})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatal("different values have the same hash")
+	}
+	// 第二个快照只新增了根文档，world 被复用
+	if store.Len() != records+1 || len(backend.data)-size > 100 {
+		t.Fatalf("world not deduplicated: %d records, %d new bytes", store.Len(), len(backend.data)-size)
+	}
+
+	var out struct {
+		Tick  uint8          `poculum:"tick"`
+		World map[string]any `poculum:"world"`
+	}
+	if err := store.Load(second, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Tick != 2 || out.World["terrain"] != world["terrain"] {
+		t.Fatalf("got %+v", out)
+	}
+
+	// 重新打开后索引仍然完整，相同的值得到相同的哈希
+	reopened, err := Open(backend, int64(len(backend.data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := reopened.Put(map[string]any{"world": world, "tick": uint8(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first || reopened.Len() != store.Len() {
+		t.Fatalf("reopened store: hash %s, %d records", again, reopened.Len())
+	}
+
+	canonical, _ := poculum.NewPoculum(poculum.WithCanonical()).Dump(map[string]any{"tick": uint8(1), "world": world})
+	got, err := reopened.Get(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, canonical) {
+		t.Fatal("Get did not return the canonical encoding")
+	}
+
+	if _, err := store.Get(Hash{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStoreReservedTag(t *testing.T) {
+	store, err := Open(&memBackend{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := poculum.Ext{Tag: RefTag, Value: make([]byte, 32)}
+	if _, err := store.Put(map[string]any{"v": fake}); !errors.Is(err, ErrReservedTag) {
+		t.Fatalf("expected ErrReservedTag, got %v", err)
+	}
+	if _, err := store.Put(fake); !errors.Is(err, ErrReservedTag) {
+		t.Fatalf("expected ErrReservedTag, got %v", err)
+	}
+	if _, err := store.Put(poculum.Ext{Tag: RefTag + 1, Value: make([]byte, 32)}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// shortBackend 只写入一部分数据
+type shortBackend struct {
+	memBackend
+	limit int
+}
+
+func (b *shortBackend) Write(p []byte) (int, error) {
+	if len(p) > b.limit {
+		p = p[:b.limit]
+	}
+	return b.memBackend.Write(p)
+}
+
+func TestStoreShortWrite(t *testing.T) {
+	backend := &shortBackend{limit: 10}
+	store, err := Open(backend, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Put("a"); !errors.Is(err, io.ErrShortWrite) {
+		t.Fatalf("expected io.ErrShortWrite, got %v", err)
+	}
+	if store.Len() != 0 || store.size != 0 {
+		t.Fatalf("short write advanced the store: %d records, size %d", store.Len(), store.size)
+	}
+
+	// 后端中留下了不完整的记录，之后的写入不能使用错误的偏移
+	backend.limit = 1 << 20
+	if _, err := store.Put("b"); !errors.Is(err, io.ErrShortWrite) {
+		t.Fatalf("expected the earlier error, got %v", err)
+	}
+}
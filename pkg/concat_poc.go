@@ -0,0 +1,111 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ConcatLists 使用默认实例合并已经编码的 list
+func ConcatLists(parts ...[]byte) ([]byte, error) {
+	return Default().ConcatLists(parts...)
+}
+
+// ConcatLists 把多个已经编码的 list 合并为一个 list，只重写头部的元素个数，元素的字节原样复制。
+// 每个部分都必须是一个完整的普通 list（不能是 Set 等扩展类型），会检查结构但不解码元素
+func (poc *Poculum) ConcatLists(parts ...[]byte) ([]byte, error) {
+	bodies := make([][]byte, len(parts))
+	total, size := 0, 0
+	for i, part := range parts {
+		if err := poc.validRaw(part); err != nil {
+			return nil, err
+		}
+		reader := bytes.NewReader(part)
+		h, err := readHeader(reader)
+		if err != nil {
+			return nil, err
+		}
+		if !h.isList() {
+			return nil, newError(TypeMismatch, fmt.Sprintf("part %d: expected list, got %s", i, typeName(h.typeByte)))
+		}
+		bodies[i] = part[len(part)-reader.Len():]
+		total += h.length
+		size += len(bodies[i])
+	}
+	if total > poc.maxContainerItems {
+		return nil, newError(DataTooLarge, fmt.Sprintf("Array too large: %d items (max %d)", total, poc.maxContainerItems))
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(5 + size)
+	writeListHeader(&buf, total)
+	for _, body := range bodies {
+		buf.Write(body)
+	}
+	return buf.Bytes(), nil
+}
+
+// listHeaderSize 返回 n 个元素的 list 头部的字节数
+func listHeaderSize(n int) int {
+	switch {
+	case n <= 15:
+		return 1
+	case n <= 0xFFFF:
+		return 3
+	default:
+		return 5
+	}
+}
+
+// SplitList 使用默认实例拆分已经编码的 list
+func SplitList(data []byte, maxSize int) ([][]byte, error) {
+	return Default().SplitList(data, maxSize)
+}
+
+// SplitList 把已经编码的 list 按照顺序拆分为多个独立的 list，每个不超过 maxSize 字节，
+// 可以分别作为消息发送（例如 Kafka 默认 1MB 的消息大小限制），接收方使用 ConcatLists 还原。
+// 元素的字节原样复制，单个元素加上头部超过 maxSize 时返回错误。空 list 拆分为一个空 list
+func (poc *Poculum) SplitList(data []byte, maxSize int) ([][]byte, error) {
+	if err := poc.validRaw(data); err != nil {
+		return nil, err
+	}
+	reader := bytes.NewReader(data)
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isList() {
+		return nil, newError(TypeMismatch, fmt.Sprintf("expected list, got %s", typeName(h.typeByte)))
+	}
+
+	var parts [][]byte
+	var body bytes.Buffer
+	count := 0
+	flush := func() {
+		var part bytes.Buffer
+		part.Grow(listHeaderSize(count) + body.Len())
+		writeListHeader(&part, count)
+		part.Write(body.Bytes())
+		parts = append(parts, part.Bytes())
+		body.Reset()
+		count = 0
+	}
+
+	for i := 0; i < h.length; i++ {
+		item, err := poc.readRaw(reader, 1)
+		if err != nil {
+			return nil, err
+		}
+		if 1+len(item) > maxSize {
+			return nil, newError(DataTooLarge, fmt.Sprintf("item %d is %d bytes, does not fit in %d", i, len(item), maxSize))
+		}
+		if count > 0 && listHeaderSize(count+1)+body.Len()+len(item) > maxSize {
+			flush()
+		}
+		body.Write(item)
+		count++
+	}
+	if count > 0 || len(parts) == 0 {
+		flush()
+	}
+	return parts, nil
+}
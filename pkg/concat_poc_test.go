@@ -0,0 +1,61 @@
+package poculum
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConcatLists(t *testing.T) {
+	long := make([]any, 20)
+	for i := range long {
+		long[i] = uint8(i)
+	}
+	parts := [][]byte{MustDump([]any{"a"}), MustDump([]any{}), MustDump(long)}
+	data, err := ConcatLists(parts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := MustLoad(data).([]any)
+	if len(got) != 21 || got[0] != "a" || got[20] != uint8(19) {
+		t.Fatalf("got %v", got)
+	}
+
+	if _, err := ConcatLists(MustDump([]any{"a"}), MustDump("b")); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+	if _, err := ConcatLists(MustDump([]any{"a"})[:1]); err == nil {
+		t.Fatal("expected error for truncated part")
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	items := make([]any, 100)
+	for i := range items {
+		items[i] = strings.Repeat("x", i%20)
+	}
+	data := MustDump(items)
+	parts, err := SplitList(data, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) < 2 {
+		t.Fatalf("expected several parts, got %d", len(parts))
+	}
+	for i, part := range parts {
+		if len(part) > 64 {
+			t.Fatalf("part %d is %d bytes", i, len(part))
+		}
+	}
+	joined, err := ConcatLists(parts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(joined, data) {
+		t.Fatal("reassembled list differs from original")
+	}
+
+	if _, err := SplitList(MustDump([]any{strings.Repeat("y", 100)}), 64); !IsKind(err, DataTooLarge) {
+		t.Fatalf("expected DataTooLarge, got %v", err)
+	}
+}
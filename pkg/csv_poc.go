@@ -0,0 +1,193 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CSV 导出
+/*
+	ToCSV 把元素为 map 的 list 写成 CSV，第一行为列名：
+
+	f, _ := os.Create("orders.csv")
+	err := poculum.ToCSV(f, data, poculum.WithCSVColumns("id", "customer", "total"))
+
+默认的列为所有行的键的并集，按照键在数据中第一次出现的顺序排列，行中缺少的键写为空。单元格的格式：
+
+	nil               空
+	字符串、布尔值      原样
+	整数与浮点数        十进制，浮点数使用能精确还原的最短表示
+	字节数据           标准 Base64
+	time.Time         RFC 3339（纳秒精度）
+	JSON 片段          原始的 JSON 文本
+	其它扩展类型        String() 的结果，例如大整数、URL 与 IP 地址
+
+map 必须是平的，值为 list、map、集合、张量等容器时返回 TypeMismatch 错误
+*/
+
+// CSVOption 配置 ToCSV 的选项
+type CSVOption func(*csvConfig)
+
+type csvConfig struct {
+	columns  []string // 为 nil 时使用所有键的并集
+	sorted   bool
+	noHeader bool
+	comma    rune
+}
+
+// WithCSVColumns 只写出 columns 中的列并按照给定的顺序排列，其它键被忽略
+func WithCSVColumns(columns ...string) CSVOption {
+	return func(c *csvConfig) {
+		c.columns = columns
+	}
+}
+
+// WithCSVSortedColumns 列按照列名排序，而不是按照第一次出现的顺序
+func WithCSVSortedColumns() CSVOption {
+	return func(c *csvConfig) {
+		c.sorted = true
+	}
+}
+
+// WithCSVNoHeader 不写出列名行
+func WithCSVNoHeader() CSVOption {
+	return func(c *csvConfig) {
+		c.noHeader = true
+	}
+}
+
+// WithCSVComma 使用 comma 作为分隔符，例如 '\t' 或者 ';'
+func WithCSVComma(comma rune) CSVOption {
+	return func(c *csvConfig) {
+		c.comma = comma
+	}
+}
+
+// ToCSV 使用默认实例把元素为 map 的 list 写成 CSV
+func ToCSV(w io.Writer, data []byte, opts ...CSVOption) error {
+	return Default().ToCSV(w, data, opts...)
+}
+
+// ToCSV 把元素为 map 的 list 写成 CSV。所有行解码并格式化之后才开始写入，数据不合法时不会写出部分内容
+func (poc *Poculum) ToCSV(w io.Writer, data []byte, opts ...CSVOption) error {
+	cfg := csvConfig{comma: ','}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if poc.maxInputSize > 0 && len(data) > poc.maxInputSize {
+		return newError(DataTooLarge, fmt.Sprintf("Input too large: %d bytes (max %d)", len(data), poc.maxInputSize))
+	}
+
+	reader := bytes.NewReader(data)
+	count, err := expectList(reader, "CSV data")
+	if err != nil {
+		return err
+	}
+	if err := poc.checkItems(reader, count, "List"); err != nil {
+		return err
+	}
+
+	var union []string
+	seen := map[string]bool{}
+	rows := make([]map[string]string, count)
+	for i := range rows {
+		// 逐个读取键，保留键在数据中的顺序
+		h, err := readHeader(reader)
+		if err != nil {
+			return err
+		}
+		if !h.isMap() {
+			return newError(TypeMismatch, fmt.Sprintf("CSV row %d must be a map, got %s", i, typeName(h.typeByte)))
+		}
+		if err := poc.checkItems(reader, h.length, "CSV row"); err != nil {
+			return err
+		}
+		row := make(map[string]string, h.length)
+		for j := 0; j < h.length; j++ {
+			key, err := poc.readKey(reader)
+			if err != nil {
+				return err
+			}
+			value, err := poc.decodeValue(reader, 2)
+			if err != nil {
+				return err
+			}
+			if row[key], err = csvCell(value); err != nil {
+				return newError(TypeMismatch, fmt.Sprintf("%s: %v", joinPath(strconv.Itoa(i), key), err))
+			}
+			if !seen[key] {
+				seen[key] = true
+				union = append(union, key)
+			}
+		}
+		rows[i] = row
+	}
+
+	columns := cfg.columns
+	if columns == nil {
+		columns = union
+		if cfg.sorted {
+			sort.Strings(columns)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = cfg.comma
+	if !cfg.noHeader {
+		cw.Write(columns)
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return wrapError(IOError, "write CSV", err)
+	}
+	return nil
+}
+
+// csvCell 把标量格式化为单元格的内容
+func csvCell(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case json.RawMessage:
+		return string(v), nil
+	case *big.Float:
+		return v.Text('g', -1), nil
+	case fmt.Stringer:
+		// 大整数、有理数、URL、IP 地址等
+		return v.String(), nil
+	}
+	if n, ok := toInt64(value); ok {
+		return strconv.FormatInt(n, 10), nil
+	}
+	if n, ok := toUint64(value); ok {
+		return strconv.FormatUint(n, 10), nil
+	}
+	return "", fmt.Errorf("CSV cell must be a scalar, got %T", value)
+}
@@ -0,0 +1,49 @@
+package poculum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestToCSV(t *testing.T) {
+	data := MustDump([]any{
+		map[string]any{"id": 1, "name": "a,b"},
+		map[string]any{"id": -2, "score": 1.5, "ok": true},
+		map[string]any{"name": nil, "raw": []byte("hi")},
+	})
+
+	var buf bytes.Buffer
+	if err := ToCSV(&buf, data, WithCSVSortedColumns()); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,name,ok,raw,score\n1,\"a,b\",,,\n-2,,true,,1.5\n,,,aGk=,\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := ToCSV(&buf, data, WithCSVColumns("score", "id"), WithCSVNoHeader(), WithCSVComma(';')); err != nil {
+		t.Fatal(err)
+	}
+	if want := ";1\n1.5;-2\n;\n"; buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+
+	// 默认按照键第一次出现的顺序排列
+	ordered := MustDump([]any{map[string]any{"b": 1}, map[string]any{"a": 2, "b": 3}})
+	buf.Reset()
+	if err := ToCSV(&buf, ordered); err != nil {
+		t.Fatal(err)
+	}
+	if want := "b,a\n1,\n3,2\n"; buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+
+	nested := MustDump([]any{map[string]any{"tags": []any{"x"}}})
+	if err := ToCSV(&buf, nested); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+	if err := ToCSV(&buf, MustDump(map[string]any{})); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+}
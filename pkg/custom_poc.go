@@ -0,0 +1,98 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// 自定义编解码函数
+/*
+	RegisterEncoder 与 RegisterDecoder 为某个类型注册自定义的编解码函数，
+可以改变无法修改源码的类型的编码方式，例如只编码一个很大的 struct 的 ID：
+
+	poc := poculum.NewPoculum()
+	poculum.RegisterEncoder(poc, func(u *User, e *poculum.Encoder) error {
+		return e.Encode(u.ID)
+	})
+	poculum.RegisterDecoder(poc, func(src any) (*User, error) {
+		id, ok := src.(uint32)
+		if !ok {
+			return nil, fmt.Errorf("unexpected user id %T", src)
+		}
+		return loadUser(id)
+	})
+
+注册的函数优先于内置的编码方式（包括枚举与扩展类型）。解码函数在 Unmarshal 写入该类型的目标时调用，
+参数为通用解码得到的值，可能为 nil。注册应当在使用 Poculum 编解码之前完成
+*/
+
+// Encoder 在自定义编码函数中写入值
+type Encoder struct {
+	poc   *Poculum
+	buf   *bytes.Buffer
+	depth int
+}
+
+// Encode 写入一个值，自定义编码函数应当恰好写入一个值
+func (e *Encoder) Encode(value any) error {
+	return e.poc.encodeValue(value, e.buf, e.depth+1)
+}
+
+// RegisterEncoder 为 T 注册自定义编码函数
+func RegisterEncoder[T any](poc *Poculum, fn func(T, *Encoder) error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if poc.encoders == nil {
+		poc.encoders = make(map[reflect.Type]func(any, *Encoder) error)
+	}
+	poc.encoders[t] = func(value any, e *Encoder) error {
+		return fn(value.(T), e)
+	}
+}
+
+// RegisterDecoder 为 T 注册自定义解码函数
+func RegisterDecoder[T any](poc *Poculum, fn func(src any) (T, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if poc.decoders == nil {
+		poc.decoders = make(map[reflect.Type]func(any) (any, error))
+	}
+	poc.decoders[t] = func(src any) (any, error) {
+		return fn(src)
+	}
+}
+
+// encodeCustom 使用注册的编码函数编码，没有注册时返回 false
+func (poc *Poculum) encodeCustom(value any, buf *bytes.Buffer, depth int) (bool, error) {
+	fn, ok := poc.encoders[reflect.TypeOf(value)]
+	if !ok {
+		return false, nil
+	}
+
+	start := buf.Len()
+	if err := fn(value, &Encoder{poc: poc, buf: buf, depth: depth}); err != nil {
+		return true, err
+	}
+	if buf.Len() == start {
+		return true, newError(InvalidEncoder, fmt.Sprintf("Encoder for %T wrote no value", value))
+	}
+	return true, nil
+}
+
+// assignCustom 使用注册的解码函数写入 dst，没有注册时返回 false
+func (poc *Poculum) assignCustom(dst reflect.Value, src any, path string) (bool, error) {
+	fn, ok := poc.decoders[dst.Type()]
+	if !ok {
+		return false, nil
+	}
+
+	value, err := fn(src)
+	if err != nil {
+		return true, newError(DecoderError, fmt.Sprintf("%s: %v", displayPath(path), err))
+	}
+	if value == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+	} else {
+		dst.Set(reflect.ValueOf(value))
+	}
+	return true, nil
+}
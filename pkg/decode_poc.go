@@ -12,20 +12,38 @@ func (poc *Poculum) load(data []byte) (any, error) {
 	if len(data) == 0 {
 		return nil, nil
 	}
+	if poc.maxInputSize > 0 && len(data) > poc.maxInputSize {
+		return nil, newError(DataTooLarge, fmt.Sprintf("Input too large: %d bytes (max %d)", len(data), poc.maxInputSize))
+	}
 
 	reader := bytes.NewReader(data)
-	return poc.decodeValue(reader, 0)
+	var value any
+	var err error
+	if poc.fields != nil {
+		value, err = poc.decodeProjected(reader, 0, poc.fields)
+	} else {
+		value, err = poc.decodeValue(reader, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if poc.reporter != nil {
+		if report, err := poc.report(data); err == nil {
+			poc.reporter(report)
+		}
+	}
+	return poc.afterDecode(value), nil
 }
 
 // decodeValue 从bytes.Reader中解码出值
 func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 	if depth > poc.maxRecursionDepth {
-		return nil, newError("MaxRecursionDepth", "Maximum recursion depth exceeded while parsing nested structure")
+		return nil, newError(MaxRecursionDepth, "Maximum recursion depth exceeded while parsing nested structure")
 	}
 
 	typeByte, err := reader.ReadByte()
 	if err != nil {
-		return nil, newError("InsufficientData", "No type byte")
+		return nil, newError(InsufficientData, "No type byte")
 	}
 
 	switch typeByte {
@@ -33,70 +51,70 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 		var value uint8
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "uint8")
+			return nil, newError(InsufficientData, "uint8")
 		}
 		return value, nil
 	case typeUInt16:
 		var value uint16
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "uint16")
+			return nil, newError(InsufficientData, "uint16")
 		}
 		return value, nil
 	case typeUInt32:
 		var value uint32
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "uint32")
+			return nil, newError(InsufficientData, "uint32")
 		}
 		return value, nil
 	case typeUInt64:
 		var value uint64
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "uint64")
+			return nil, newError(InsufficientData, "uint64")
 		}
 		return value, nil
 	case typeInt8:
 		var value int8
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "int8")
+			return nil, newError(InsufficientData, "int8")
 		}
 		return value, nil
 	case typeInt16:
 		var value int16
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "int16")
+			return nil, newError(InsufficientData, "int16")
 		}
 		return value, nil
 	case typeInt32:
 		var value int32
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "int32")
+			return nil, newError(InsufficientData, "int32")
 		}
 		return value, nil
 	case typeInt64:
 		var value int64
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "int64")
+			return nil, newError(InsufficientData, "int64")
 		}
 		return value, nil
 	case typeFloat32:
 		var value float32
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "float32")
+			return nil, newError(InsufficientData, "float32")
 		}
 		return value, nil
 	case typeFloat64:
 		var value float64
 		err := binary.Read(reader, binary.BigEndian, &value)
 		if err != nil {
-			return nil, newError("InsufficientData", "float64")
+			return nil, newError(InsufficientData, "float64")
 		}
 		return value, nil
 	case typeTrue:
@@ -105,6 +123,8 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 		return false, nil
 	case typeNil:
 		return nil, nil
+	case typeExt:
+		return poc.decodeExt(reader, depth)
 	default:
 		// 处理字符串类型
 		if typeByte >= typeFixStringBase && typeByte <= typeFixStringBase+15 {
@@ -115,7 +135,7 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 			var length uint16
 			err := binary.Read(reader, binary.BigEndian, &length)
 			if err != nil {
-				return nil, newError("InsufficientData", "string16 length")
+				return nil, newError(InsufficientData, "string16 length")
 			}
 			return poc.decodeString(reader, int(length))
 		}
@@ -123,10 +143,10 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 			var length uint32
 			err := binary.Read(reader, binary.BigEndian, &length)
 			if err != nil {
-				return nil, newError("InsufficientData", "string32 length")
+				return nil, newError(InsufficientData, "string32 length")
 			}
 			if int(length) > poc.maxStringSize {
-				return nil, newError("DataTooLarge", fmt.Sprintf("String32 length too large: %d", length))
+				return nil, newError(DataTooLarge, fmt.Sprintf("String32 length too large: %d", length))
 			}
 			return poc.decodeString(reader, int(length))
 		}
@@ -140,7 +160,7 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 			var length uint16
 			err := binary.Read(reader, binary.BigEndian, &length)
 			if err != nil {
-				return nil, newError("InsufficientData", "list16 length")
+				return nil, newError(InsufficientData, "list16 length")
 			}
 			return poc.decodeArray(reader, int(length), depth)
 		}
@@ -148,7 +168,7 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 			var length uint32
 			err := binary.Read(reader, binary.BigEndian, &length)
 			if err != nil {
-				return nil, newError("InsufficientData", "list32 length")
+				return nil, newError(InsufficientData, "list32 length")
 			}
 			return poc.decodeArray(reader, int(length), depth)
 		}
@@ -162,7 +182,7 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 			var length uint16
 			err := binary.Read(reader, binary.BigEndian, &length)
 			if err != nil {
-				return nil, newError("InsufficientData", "map16 length")
+				return nil, newError(InsufficientData, "map16 length")
 			}
 			return poc.decodeMap(reader, int(length), depth)
 		}
@@ -170,7 +190,7 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 			var length uint32
 			err := binary.Read(reader, binary.BigEndian, &length)
 			if err != nil {
-				return nil, newError("InsufficientData", "map32 length")
+				return nil, newError(InsufficientData, "map32 length")
 			}
 			return poc.decodeMap(reader, int(length), depth)
 		}
@@ -180,7 +200,7 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 			var length uint8
 			err := binary.Read(reader, binary.BigEndian, &length)
 			if err != nil {
-				return nil, newError("InsufficientData", "bytes8 length")
+				return nil, newError(InsufficientData, "bytes8 length")
 			}
 			return poc.decodeBytes(reader, int(length))
 		}
@@ -188,7 +208,7 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 			var length uint16
 			err := binary.Read(reader, binary.BigEndian, &length)
 			if err != nil {
-				return nil, newError("InsufficientData", "bytes16 length")
+				return nil, newError(InsufficientData, "bytes16 length")
 			}
 			return poc.decodeBytes(reader, int(length))
 		}
@@ -196,12 +216,12 @@ func (poc *Poculum) decodeValue(reader *bytes.Reader, depth int) (any, error) {
 			var length uint32
 			err := binary.Read(reader, binary.BigEndian, &length)
 			if err != nil {
-				return nil, newError("InsufficientData", "bytes32 length")
+				return nil, newError(InsufficientData, "bytes32 length")
 			}
 			return poc.decodeBytes(reader, int(length))
 		}
 
-		return nil, newError("UnknownTypeId", fmt.Sprintf("Unknown type identifier: 0x%02x", typeByte))
+		return nil, newError(UnknownTypeID, fmt.Sprintf("Unknown type identifier: 0x%02x", typeByte))
 	}
 }
 
@@ -210,24 +230,46 @@ func (poc *Poculum) decodeString(reader *bytes.Reader, length int) (string, erro
 	if length == 0 {
 		return "", nil
 	}
+	if length > poc.maxStringSize {
+		return "", newError(DataTooLarge, fmt.Sprintf("String length too large: %d bytes (max %d)", length, poc.maxStringSize))
+	}
+	// 先确认数据足够，避免伪造的长度导致巨大的内存分配
+	if length > reader.Len() {
+		return "", newError(InsufficientData, "string data")
+	}
 
 	data := make([]byte, length)
 	n, err := reader.Read(data)
 	if err != nil || n != length {
-		return "", newError("InsufficientData", "string data")
+		return "", newError(InsufficientData, "string data")
 	}
 
-	if !utf8.Valid(data) {
-		return "", newError("Utf8Error", "Invalid UTF-8 string")
+	if !poc.trusted && !utf8.Valid(data) {
+		return "", newError(Utf8Error, "Invalid UTF-8 string")
 	}
 
 	return string(data), nil
 }
 
+// maxSizeHint 按照头部声明的元素个数预分配 map 时的上限，更多的元素在解码时按需增长
+const maxSizeHint = 1024
+
+// checkItems 在分配内存之前检查容器头部声明的元素个数：不超过上限，
+// 并且每个元素至少占用一个字节，不能超过剩余的数据
+func (poc *Poculum) checkItems(reader *bytes.Reader, length int, what string) error {
+	if length > poc.maxContainerItems {
+		return newError(DataTooLarge, fmt.Sprintf("%s length too large: %d items (max %d)", what, length, poc.maxContainerItems))
+	}
+	if length > reader.Len() {
+		return newError(InsufficientData, fmt.Sprintf("%s of %d items", what, length))
+	}
+	return nil
+}
+
 // decodeArray 解码数组
 func (poc *Poculum) decodeArray(reader *bytes.Reader, length int, depth int) ([]any, error) {
-	if length > poc.maxContainerItems {
-		return nil, newError("DataTooLarge", fmt.Sprintf("Array length too large: %d items (max %d)", length, poc.maxContainerItems))
+	if err := poc.checkItems(reader, length, "Array"); err != nil {
+		return nil, err
 	}
 
 	arr := make([]any, length)
@@ -236,7 +278,10 @@ func (poc *Poculum) decodeArray(reader *bytes.Reader, length int, depth int) ([]
 		if err != nil {
 			return nil, err
 		}
-		arr[i] = value
+		arr[i] = poc.afterDecode(value)
+	}
+	if poc.numericList != nil {
+		return poc.normalizeNumbers(arr)
 	}
 
 	return arr, nil
@@ -244,8 +289,8 @@ func (poc *Poculum) decodeArray(reader *bytes.Reader, length int, depth int) ([]
 
 // decodeMap 解码对象
 func (poc *Poculum) decodeMap(reader *bytes.Reader, length int, depth int) (map[string]any, error) {
-	if length > poc.maxContainerItems {
-		return nil, newError("DataTooLarge", fmt.Sprintf("Object length too large: %d items (max %d)", length, poc.maxContainerItems))
+	if err := poc.checkItems(reader, length, "Object"); err != nil {
+		return nil, err
 	}
 
 	obj := make(map[string]any)
@@ -257,7 +302,10 @@ func (poc *Poculum) decodeMap(reader *bytes.Reader, length int, depth int) (map[
 		}
 		key, ok := keyValue.(string)
 		if !ok {
-			return nil, newError("UnsupportedType", "Object key must be string")
+			return nil, newError(UnsupportedType, "Object key must be string")
+		}
+		if key, err = poc.mapKey(obj, key); err != nil {
+			return nil, err
 		}
 
 		// 解码值
@@ -265,7 +313,7 @@ func (poc *Poculum) decodeMap(reader *bytes.Reader, length int, depth int) (map[
 		if err != nil {
 			return nil, err
 		}
-		obj[key] = value
+		obj[key] = poc.afterDecode(value)
 	}
 
 	return obj, nil
@@ -273,16 +321,23 @@ func (poc *Poculum) decodeMap(reader *bytes.Reader, length int, depth int) (map[
 
 // decodeBytes 解码字节数据
 func (poc *Poculum) decodeBytes(reader *bytes.Reader, length int) ([]byte, error) {
+	if length == 0 {
+		// 数据末尾读取 0 个字节时 Read 返回 io.EOF
+		return []byte{}, nil
+	}
+	if length > reader.Len() {
+		return nil, newError(InsufficientData, "bytes data")
+	}
 	data := make([]byte, length)
 	n, err := reader.Read(data)
 	if err != nil || n != length {
-		return nil, newError("InsufficientData", "bytes data")
+		return nil, newError(InsufficientData, "bytes data")
 	}
 
 	return data, nil
 }
 
 func DumpPoculum(value any) ([]byte, error) {
-	poc := NewPoculum()
+	poc := Default()
 	return poc.dump(value)
 }
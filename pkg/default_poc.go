@@ -0,0 +1,23 @@
+package poculum
+
+import "sync/atomic"
+
+// defaultPoculum 包级函数使用的 Poculum 实例，为 nil 时使用默认配置
+var defaultPoculum atomic.Pointer[Poculum]
+
+// standardPoculum 默认配置的实例
+var standardPoculum = NewPoculum()
+
+// SetDefault 设置 DumpPoculum、LoadPoculum、Unmarshal、GetRaw、Describe 与 NewUnion 等包级函数使用的实例，
+// 传入 nil 恢复默认配置。应当在程序启动时设置，设置之后不要再修改该实例的配置
+func SetDefault(poc *Poculum) {
+	defaultPoculum.Store(poc)
+}
+
+// Default 返回包级函数使用的实例
+func Default() *Poculum {
+	if poc := defaultPoculum.Load(); poc != nil {
+		return poc
+	}
+	return standardPoculum
+}
@@ -0,0 +1,163 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Summary 是对编码数据结构的统计，由 Describe 生成
+type Summary struct {
+	Types       map[string]int // 各类型值出现的次数，键为类型名称，例如 "uint8"、"string"、"list"
+	Values      int            // 值的总数，包括容器本身
+	MaxDepth    int            // 最大嵌套深度，顶层值的深度为 0
+	Lists       int            // list 的数量
+	Maps        int            // map 的数量
+	ListItems   int            // 所有 list 中元素的总数
+	MapEntries  int            // 所有 map 中键值对的总数
+	LargestList int            // 元素最多的 list 的元素个数
+	LargestMap  int            // 键值对最多的 map 的键值对个数
+
+	LargestString       int // 最长字符串的字节数（不含 map 的键）
+	LargestStringOffset int // 最长字符串在数据中的偏移，没有字符串时为 -1
+	LargestBytes        int // 最长字节数据的字节数
+	LargestBytesOffset  int // 最长字节数据在数据中的偏移，没有字节数据时为 -1
+}
+
+// typeName 返回类型字节对应的类型名称
+func typeName(typeByte byte) string {
+	switch {
+	case typeByte == typeUInt8:
+		return "uint8"
+	case typeByte == typeUInt16:
+		return "uint16"
+	case typeByte == typeUInt32:
+		return "uint32"
+	case typeByte == typeUInt64:
+		return "uint64"
+	case typeByte == typeInt8:
+		return "int8"
+	case typeByte == typeInt16:
+		return "int16"
+	case typeByte == typeInt32:
+		return "int32"
+	case typeByte == typeInt64:
+		return "int64"
+	case typeByte == typeFloat32:
+		return "float32"
+	case typeByte == typeFloat64:
+		return "float64"
+	case typeByte == typeTrue || typeByte == typeFalse:
+		return "bool"
+	case typeByte == typeNil:
+		return "nil"
+	case (typeByte >= typeFixStringBase && typeByte <= typeFixStringBase+15) ||
+		typeByte == typeString16 || typeByte == typeString32:
+		return "string"
+	case (typeByte >= typeFixListBase && typeByte <= typeFixListBase+15) ||
+		typeByte == typeList16 || typeByte == typeList32:
+		return "list"
+	case (typeByte >= typeFixMapBase && typeByte <= typeFixMapBase+15) ||
+		typeByte == typeMap16 || typeByte == typeMap32:
+		return "map"
+	case typeByte == typeBytes8 || typeByte == typeBytes16 || typeByte == typeBytes32:
+		return "bytes"
+	case typeByte == typeExt:
+		return "ext"
+	default:
+		return fmt.Sprintf("unknown(0x%02x)", typeByte)
+	}
+}
+
+// describeValue 统计一个值，map 的键只检查类型，不计入统计。
+// ext 为经过的扩展类型头部的个数，不计入 MaxDepth，但是与 depth 一起受最大递归深度的限制
+func (poc *Poculum) describeValue(reader *bytes.Reader, size int, depth, ext int, s *Summary) error {
+	if depth+ext > poc.maxRecursionDepth {
+		return newError(MaxRecursionDepth, "Maximum recursion depth exceeded while parsing nested structure")
+	}
+
+	offset := size - reader.Len()
+	h, err := readHeader(reader)
+	if err != nil {
+		return err
+	}
+
+	name := typeName(h.typeByte)
+	s.Types[name]++
+	s.Values++
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+
+	switch {
+	case h.typeByte == typeExt:
+		// 扩展类型中的值与扩展类型本身处于同一深度
+		s.Values--
+		return poc.describeValue(reader, size, depth, ext+1, s)
+	case h.isList():
+		s.Lists++
+		s.ListItems += h.length
+		s.LargestList = max(s.LargestList, h.length)
+		for i := 0; i < h.length; i++ {
+			if err := poc.describeValue(reader, size, depth+1, ext, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	case h.isMap():
+		s.Maps++
+		s.MapEntries += h.length
+		s.LargestMap = max(s.LargestMap, h.length)
+		for i := 0; i < h.length; i++ {
+			if _, err := poc.readKey(reader); err != nil {
+				return err
+			}
+			if err := poc.describeValue(reader, size, depth+1, ext, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if h.length > reader.Len() {
+		return newError(InsufficientData, fmt.Sprintf("%s data", name))
+	}
+	switch name {
+	case "string":
+		if s.LargestStringOffset < 0 || h.length > s.LargestString {
+			s.LargestString = h.length
+			s.LargestStringOffset = offset
+		}
+	case "bytes":
+		if s.LargestBytesOffset < 0 || h.length > s.LargestBytes {
+			s.LargestBytes = h.length
+			s.LargestBytesOffset = offset
+		}
+	}
+	_, err = reader.Seek(int64(h.length), io.SeekCurrent)
+	return err
+}
+
+// describe 生成 data 的结构统计
+func (poc *Poculum) describe(data []byte) (Summary, error) {
+	s := Summary{
+		Types:               make(map[string]int),
+		LargestStringOffset: -1,
+		LargestBytesOffset:  -1,
+	}
+	if len(data) == 0 {
+		return s, nil
+	}
+
+	reader := bytes.NewReader(data)
+	if err := poc.describeValue(reader, len(data), 0, 0, &s); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// Describe 统计编码数据的类型分布、最大深度、元素数量以及最长的字符串与字节数据，
+// 只读取类型与长度信息，不会构造其中的值，可以用于容量规划与数据审计
+func Describe(data []byte) (Summary, error) {
+	return Default().describe(data)
+}
@@ -0,0 +1,78 @@
+package poculum
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	data, err := NewPoculum(WithCanonical()).Dump(map[string]any{
+		"a": []any{uint8(1), uint8(2), "hi"},
+		"b": map[string]any{"c": []byte{1, 2, 3}, "d": nil},
+		"e": strings.Repeat("x", 20),
+		"f": Set{true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Describe(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTypes := map[string]int{"map": 2, "list": 2, "uint8": 2, "string": 2, "bytes": 1, "nil": 1, "ext": 1, "bool": 1}
+	if !reflect.DeepEqual(s.Types, wantTypes) {
+		t.Errorf("Types = %v, want %v", s.Types, wantTypes)
+	}
+	// 扩展类型与其中的值算作一个值，处于同一深度
+	if s.Values != 11 || s.MaxDepth != 2 {
+		t.Errorf("Values = %d, MaxDepth = %d", s.Values, s.MaxDepth)
+	}
+	if s.Lists != 2 || s.ListItems != 4 || s.LargestList != 3 {
+		t.Errorf("lists: %d, %d items, largest %d", s.Lists, s.ListItems, s.LargestList)
+	}
+	if s.Maps != 2 || s.MapEntries != 6 || s.LargestMap != 4 {
+		t.Errorf("maps: %d, %d entries, largest %d", s.Maps, s.MapEntries, s.LargestMap)
+	}
+
+	long := strings.Repeat("x", 20)
+	if s.LargestString != 20 || !bytes.HasPrefix(data[s.LargestStringOffset+3:], []byte(long)) {
+		t.Errorf("LargestString = %d at %d", s.LargestString, s.LargestStringOffset)
+	}
+	if s.LargestBytes != 3 || !bytes.HasPrefix(data[s.LargestBytesOffset:], []byte{typeBytes8, 3, 1, 2, 3}) {
+		t.Errorf("LargestBytes = %d at %d", s.LargestBytes, s.LargestBytesOffset)
+	}
+
+	s, err = Describe(MustDump(uint8(1)))
+	if err != nil || s.LargestStringOffset != -1 || s.LargestBytesOffset != -1 || s.MaxDepth != 0 {
+		t.Fatalf("scalar: %+v, %v", s, err)
+	}
+}
+
+func TestDescribeInvalid(t *testing.T) {
+	data := MustDump([]any{"abc", uint8(1)})
+	for i := 1; i < len(data); i++ {
+		if _, err := Describe(data[:i]); !IsKind(err, InsufficientData) {
+			t.Fatalf("truncated at %d: expected InsufficientData, got %v", i, err)
+		}
+	}
+
+	// map 的键必须是字符串
+	if _, err := Describe([]byte{typeFixMapBase + 1, typeUInt8, 1, typeNil}); !IsKind(err, UnsupportedType) {
+		t.Fatalf("expected UnsupportedType, got %v", err)
+	}
+}
+
+func TestDescribeExtChain(t *testing.T) {
+	// 扩展类型头部的链受最大递归深度的限制，但是不计入 MaxDepth
+	data := append(bytes.Repeat([]byte{typeExt, extSet}, 1<<16), typeNil)
+	if _, err := NewPoculumSecure().describe(data); !IsKind(err, MaxRecursionDepth) {
+		t.Fatalf("expected MaxRecursionDepth, got %v", err)
+	}
+	s, err := Describe(MustDump(Set{Set{}}))
+	if err != nil || s.MaxDepth != 1 {
+		t.Fatalf("got %+v, %v", s, err)
+	}
+}
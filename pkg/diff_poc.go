@@ -0,0 +1,238 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// 结构化差异
+/*
+	Diff 比较两份编码数据，得到把旧数据变为新数据的 Patch。
+map 按照键逐个比较，长度相同的 list 按照下标逐个比较，其它不同的值（包括长度变化的 list 与扩展类型）整体替换。
+Patch 中的值是新数据中的原始字节，不需要解码，可以使用 Patch.Marshal 编码后传输，
+接收方使用 UnmarshalPatch 解码，再使用 ApplyPatch 应用到旧数据上
+*/
+
+// PatchOp Patch 中的一个操作
+type PatchOp struct {
+	Path   []string // 路径的各段，map 为键，list 为十进制下标，空路径代表顶层值
+	Value  Raw      // 新的值，Delete 为 true 时为 nil
+	Delete bool     // 删除 map 中的键
+}
+
+// Patch 结构化差异，操作按照顺序应用
+type Patch []PatchOp
+
+// rawEntry 容器中一个元素在数据中的位置
+type rawEntry struct {
+	key        string // map 的键，list 为空
+	start      int    // 元素的开头，map 为键的开头
+	valueStart int    // 值的开头
+	end        int    // 值的结尾
+}
+
+// rawEntries 解析 data 开头的容器，返回头部、头部的字节数以及各元素的位置
+func (poc *Poculum) rawEntries(data []byte, depth int) (valueHeader, int, []rawEntry, error) {
+	reader := bytes.NewReader(data)
+	h, err := readHeader(reader)
+	if err != nil {
+		return h, 0, nil, err
+	}
+	if !h.isContainer() {
+		return h, 0, nil, newError(TypeMismatch, fmt.Sprintf("Expected list or map, got %s", typeName(h.typeByte)))
+	}
+	if h.length > reader.Len() {
+		return h, 0, nil, newError(InsufficientData, "container items")
+	}
+
+	headerLen := len(data) - reader.Len()
+	entries := make([]rawEntry, h.length)
+	for i := range entries {
+		e := &entries[i]
+		e.start = len(data) - reader.Len()
+		if h.isMap() {
+			if e.key, err = poc.readKey(reader); err != nil {
+				return h, 0, nil, err
+			}
+		}
+		e.valueStart = len(data) - reader.Len()
+		if err := poc.skipValue(reader, depth+1); err != nil {
+			return h, 0, nil, err
+		}
+		e.end = len(data) - reader.Len()
+	}
+	return h, headerLen, entries, nil
+}
+
+// diff 比较 before 与 after 两个完整的值，把差异追加到 patch 中
+func (poc *Poculum) diff(before, after []byte, path []string, patch *Patch, depth int) error {
+	if depth > poc.maxRecursionDepth {
+		return newError(MaxRecursionDepth, "Maximum recursion depth exceeded while comparing nested structure")
+	}
+	if bytes.Equal(before, after) {
+		return nil
+	}
+
+	bh, err := readHeader(bytes.NewReader(before))
+	if err != nil {
+		return err
+	}
+	ah, err := readHeader(bytes.NewReader(after))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case bh.isMap() && ah.isMap():
+		_, _, beforeEntries, err := poc.rawEntries(before, depth)
+		if err != nil {
+			return err
+		}
+		_, _, afterEntries, err := poc.rawEntries(after, depth)
+		if err != nil {
+			return err
+		}
+
+		beforeValues := make(map[string][]byte, len(beforeEntries))
+		for _, e := range beforeEntries {
+			beforeValues[e.key] = before[e.valueStart:e.end]
+		}
+		afterKeys := make(map[string]bool, len(afterEntries))
+		for _, e := range afterEntries {
+			afterKeys[e.key] = true
+		}
+
+		for _, e := range beforeEntries {
+			if !afterKeys[e.key] {
+				*patch = append(*patch, PatchOp{Path: appendPath(path, e.key), Delete: true})
+			}
+		}
+		for _, e := range afterEntries {
+			value := after[e.valueStart:e.end]
+			beforeValue, ok := beforeValues[e.key]
+			if !ok {
+				*patch = append(*patch, PatchOp{Path: appendPath(path, e.key), Value: Raw(value)})
+				continue
+			}
+			if err := poc.diff(beforeValue, value, appendPath(path, e.key), patch, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case bh.isList() && ah.isList() && bh.length == ah.length:
+		_, _, beforeEntries, err := poc.rawEntries(before, depth)
+		if err != nil {
+			return err
+		}
+		_, _, afterEntries, err := poc.rawEntries(after, depth)
+		if err != nil {
+			return err
+		}
+		for i := range afterEntries {
+			b, a := beforeEntries[i], afterEntries[i]
+			if err := poc.diff(before[b.valueStart:b.end], after[a.valueStart:a.end], appendPath(path, strconv.Itoa(i)), patch, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	*patch = append(*patch, PatchOp{Path: clonePath(path), Value: Raw(after)})
+	return nil
+}
+
+// appendPath 返回追加了一段的新路径，不修改 path
+func appendPath(path []string, seg string) []string {
+	return append(clonePath(path), seg)
+}
+
+// clonePath 复制路径
+func clonePath(path []string) []string {
+	return append(make([]string, 0, len(path)+1), path...)
+}
+
+// Diff 比较两份编码数据，返回把 before 变为 after 的 Patch，两份数据相同时返回空的 Patch
+func Diff(before, after []byte) (Patch, error) {
+	return Default().Diff(before, after)
+}
+
+// Diff 比较两份编码数据，返回把 before 变为 after 的 Patch
+func (poc *Poculum) Diff(before, after []byte) (Patch, error) {
+	if err := poc.validRaw(before); err != nil {
+		return nil, err
+	}
+	if err := poc.validRaw(after); err != nil {
+		return nil, err
+	}
+
+	patch := Patch{}
+	if err := poc.diff(before, after, nil, &patch, 0); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// Marshal 把 Patch 编码为 list，每个操作为 [路径] （删除）或者 [路径, 值]
+func (p Patch) Marshal() ([]byte, error) {
+	ops := make([]any, len(p))
+	for i, op := range p {
+		path := make([]any, len(op.Path))
+		for j, seg := range op.Path {
+			path[j] = seg
+		}
+		if op.Delete {
+			ops[i] = []any{path}
+		} else {
+			ops[i] = []any{path, RawMessage(op.Value)}
+		}
+	}
+	return Default().dump(ops)
+}
+
+// UnmarshalPatch 解码 Patch.Marshal 得到的数据
+func UnmarshalPatch(data []byte) (Patch, error) {
+	poc := Default()
+	reader := bytes.NewReader(data)
+	count, err := expectList(reader, "Patch")
+	if err != nil {
+		return nil, err
+	}
+
+	patch := make(Patch, 0, min(count, reader.Len()))
+	for i := 0; i < count; i++ {
+		items, err := expectList(reader, "Patch operation")
+		if err != nil {
+			return nil, err
+		}
+		if items != 1 && items != 2 {
+			return nil, newError(InvalidPatch, fmt.Sprintf("Patch operation %d has %d items", i, items))
+		}
+
+		pathValue, err := poc.decodeValue(reader, 2)
+		if err != nil {
+			return nil, err
+		}
+		segments, ok := pathValue.([]any)
+		if !ok {
+			return nil, newError(InvalidPatch, fmt.Sprintf("Patch operation %d path must be a list, got %T", i, pathValue))
+		}
+		op := PatchOp{Path: make([]string, len(segments)), Delete: items == 1}
+		for j, seg := range segments {
+			if op.Path[j], ok = seg.(string); !ok {
+				return nil, newError(InvalidPatch, fmt.Sprintf("Patch operation %d path segment must be a string, got %T", i, seg))
+			}
+		}
+		if !op.Delete {
+			raw, err := poc.readRaw(reader, 2)
+			if err != nil {
+				return nil, err
+			}
+			op.Value = Raw(raw)
+		}
+		patch = append(patch, op)
+	}
+	if reader.Len() != 0 {
+		return nil, newError(InvalidPatch, fmt.Sprintf("%d trailing bytes after patch", reader.Len()))
+	}
+	return patch, nil
+}
@@ -0,0 +1,97 @@
+package poculum
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before, _ := DumpPoculum(map[string]any{
+		"name":  "ann",
+		"hp":    uint8(10),
+		"pos":   []any{uint8(1), uint8(2)},
+		"items": []any{"a"},
+		"gone":  true,
+	})
+	after, _ := DumpPoculum(map[string]any{
+		"name":  "ann",
+		"hp":    uint8(7),
+		"pos":   []any{uint8(1), uint8(3)},
+		"items": []any{"a", "b"},
+		"new":   "x",
+	})
+
+	patch, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]PatchOp)
+	for _, op := range patch {
+		got[strings.Join(op.Path, ".")] = op
+	}
+	if len(got) != 5 || !got["gone"].Delete || got["pos.1"].Value == nil || got["name"].Path != nil {
+		t.Fatalf("unexpected patch: %+v", patch)
+	}
+	if v, _ := LoadPoculum(got["items"].Value); !reflect.DeepEqual(v, []any{"a", "b"}) {
+		t.Fatalf("items replaced with %v", v)
+	}
+
+	data, err := patch.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := UnmarshalPatch(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, patch) {
+		t.Fatalf("patch round trip: got %+v, want %+v", decoded, patch)
+	}
+
+	if same, err := Diff(after, after); err != nil || len(same) != 0 {
+		t.Fatalf("identical documents: %v %v", same, err)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	before := map[string]any{
+		"name":  "ann",
+		"stats": map[string]any{"hp": uint8(10), "mp": uint8(3)},
+		"pos":   []any{uint8(1), uint8(2)},
+		"items": []any{"a"},
+		"gone":  true,
+	}
+	after := map[string]any{
+		"name":  "ann",
+		"stats": map[string]any{"hp": uint8(7), "xp": uint16(300)},
+		"pos":   []any{uint8(1), uint8(3)},
+		"items": []any{"a", "b"},
+		"new":   "x",
+	}
+	beforeData, _ := DumpPoculum(before)
+	afterData, _ := DumpPoculum(after)
+
+	patch, err := Diff(beforeData, afterData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched, err := ApplyPatch(beforeData, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadPoculum(patched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, after) {
+		t.Fatalf("got %v, want %v", got, after)
+	}
+
+	if _, err := ApplyPatch(beforeData, Patch{{Path: []string{"missing", "x"}, Value: Raw{typeNil}}}); err == nil {
+		t.Fatal("expected error for missing parent")
+	}
+	if _, err := ApplyPatch(beforeData, Patch{{Path: []string{"pos", "0"}, Delete: true}}); err == nil {
+		t.Fatal("expected error deleting list element")
+	}
+}
@@ -3,16 +3,32 @@ package poculum
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
 	"reflect"
+	"sort"
+	"sync"
+	"time"
 	"unicode/utf8"
 )
 
 // 编码值到缓冲区
 func (poc *Poculum) encodeValue(value any, buf *bytes.Buffer, depth int) error {
 	if depth > poc.maxRecursionDepth {
-		return newError("MaxRecursionDepth", "Maximum recursion depth exceeded")
+		return newError(MaxRecursionDepth, "Maximum recursion depth exceeded")
+	}
+	if len(poc.encoders) > 0 && value != nil {
+		if ok, err := poc.encodeCustom(value, buf, depth); ok {
+			return err
+		}
+	}
+	if poc.encodeNilContainer(value, buf) {
+		return nil
 	}
 
 	switch v := value.(type) {
@@ -42,6 +58,9 @@ func (poc *Poculum) encodeValue(value any, buf *bytes.Buffer, depth int) error {
 		binary.Write(buf, binary.BigEndian, v)
 	case int:
 		// Go 的 int 类型，转换为适当的整数类型
+		if poc.fixedInts {
+			return poc.encodeValue(int64(v), buf, depth)
+		}
 		if v >= 0 {
 			if v <= math.MaxUint32 {
 				return poc.encodeValue(uint32(v), buf, depth)
@@ -57,6 +76,9 @@ func (poc *Poculum) encodeValue(value any, buf *bytes.Buffer, depth int) error {
 		}
 	case uint:
 		// Go 的 uint 类型
+		if poc.fixedInts {
+			return poc.encodeValue(uint64(v), buf, depth)
+		}
 		if v <= math.MaxUint32 {
 			return poc.encodeValue(uint32(v), buf, depth)
 		} else {
@@ -66,6 +88,9 @@ func (poc *Poculum) encodeValue(value any, buf *bytes.Buffer, depth int) error {
 		buf.WriteByte(typeFloat32)
 		binary.Write(buf, binary.BigEndian, v)
 	case float64:
+		if poc.compactFloats && float64(float32(v)) == v {
+			return poc.encodeValue(float32(v), buf, depth)
+		}
 		buf.WriteByte(typeFloat64)
 		binary.Write(buf, binary.BigEndian, v)
 	case string:
@@ -76,6 +101,45 @@ func (poc *Poculum) encodeValue(value any, buf *bytes.Buffer, depth int) error {
 		return poc.encodeMap(v, buf, depth)
 	case []byte:
 		return poc.encodeBytes(v, buf)
+	case []bool:
+		return poc.encodeBools(v, buf, depth)
+	case RawMessage:
+		return poc.encodeRaw(v, buf)
+	case Ext:
+		return poc.encodeExt(v, buf, depth)
+	case Set:
+		return poc.encodeSet(v, buf, depth)
+	case Tuple:
+		return poc.encodeTuple(v, buf, depth)
+	case *Tensor:
+		if v == nil {
+			return buf.WriteByte(typeNil)
+		}
+		return poc.encodeTensor(v, buf, depth)
+	case Tensor:
+		return poc.encodeTensor(&v, buf, depth)
+	case netip.Addr:
+		return poc.encodeAddr(v, buf)
+	case netip.Prefix:
+		return poc.encodePrefix(v, buf)
+	case net.IP:
+		return poc.encodeNetIP(v, buf)
+	case *url.URL:
+		return poc.encodeURL(v, buf)
+	case url.URL:
+		return poc.encodeURL(&v, buf)
+	case time.Time:
+		return poc.encodeTime(v, buf, depth)
+	case json.RawMessage:
+		return poc.encodeJSON(v, buf)
+	case json.Number:
+		return poc.encodeJSONNumber(v, buf, depth)
+	case *big.Float:
+		return poc.encodeBigFloat(v, buf, depth)
+	case *big.Rat:
+		return poc.encodeBigRat(v, buf)
+	case *sync.Map:
+		return poc.encodeSyncMap(v, buf, depth)
 	case bool:
 		// 布尔值
 		if v {
@@ -93,9 +157,40 @@ func (poc *Poculum) encodeValue(value any, buf *bytes.Buffer, depth int) error {
 	return nil
 }
 
+// basicTypes 基本类型的 reflect.Type，用于转换命名类型
+var basicTypes = map[reflect.Kind]reflect.Type{
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+}
+
 // encodeWithReflection 使用反射编码未知类型
 func (poc *Poculum) encodeWithReflection(value any, buf *bytes.Buffer, depth int) error {
+	if o, ok := value.(optional); ok {
+		return poc.encodeOption(o, buf, depth)
+	}
+	if ok, err := poc.encodeDriverValue(value, buf, depth); ok {
+		return err
+	}
+	if t, ok := value.(typedTuple); ok {
+		return poc.encodeTuple(t.tupleItems(), buf, depth)
+	}
+
 	rv := reflect.ValueOf(value)
+	if info, ok := poc.enums[rv.Type()]; ok {
+		return poc.encodeEnum(info, rv, buf, depth)
+	}
+
 	switch rv.Kind() {
 	case reflect.Bool:
 		// 处理布尔类型，保持与主分支一致
@@ -105,18 +200,49 @@ func (poc *Poculum) encodeWithReflection(value any, buf *bytes.Buffer, depth int
 			buf.WriteByte(typeFalse)
 		}
 		return nil
-	case reflect.Slice:
-		// 处理切片类型
+	case reflect.Slice, reflect.Array:
+		// 处理切片与数组类型，元素为布尔值时使用位压缩编码
 		length := rv.Len()
+		if rv.Type().Elem().Kind() == reflect.Bool {
+			bools := make([]bool, length)
+			for i := 0; i < length; i++ {
+				bools[i] = rv.Index(i).Bool()
+			}
+			return poc.encodeBools(bools, buf, depth)
+		}
 		values := make([]any, length)
 		for i := 0; i < length; i++ {
 			values[i] = rv.Index(i).Interface()
 		}
 		return poc.encodeArray(values, buf, depth)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		// 处理以基本类型定义的命名类型，例如 type Status string
+		return poc.encodeValue(rv.Convert(basicTypes[rv.Kind()]).Interface(), buf, depth)
+	case reflect.Pointer:
+		// 处理指针类型，nil 指针编码为 nil
+		if rv.IsNil() {
+			return buf.WriteByte(typeNil)
+		}
+		return poc.encodeValue(rv.Elem().Interface(), buf, depth)
+	case reflect.Struct:
+		// 处理结构体类型，编码为 map
+		return poc.encodeStruct(rv, buf, depth)
 	case reflect.Map:
-		// 处理映射类型
+		// 处理映射类型，map[T]struct{} 编码为集合，整数键的 map 使用整数键 map 扩展类型
+		if isSetMap(rv.Type()) {
+			set := make(Set, 0, rv.Len())
+			for _, key := range rv.MapKeys() {
+				set = append(set, key.Interface())
+			}
+			return poc.encodeSet(set, buf, depth)
+		}
+		if isIntKind(rv.Type().Key().Kind()) {
+			return poc.encodeIntMap(rv, buf, depth)
+		}
 		if rv.Type().Key().Kind() != reflect.String {
-			return newError("UnsupportedType", "Map keys must be strings")
+			return newError(UnsupportedType, "Map keys must be strings or integers")
 		}
 		values := make(map[string]any)
 		for _, key := range rv.MapKeys() {
@@ -126,39 +252,41 @@ func (poc *Poculum) encodeWithReflection(value any, buf *bytes.Buffer, depth int
 		}
 		return poc.encodeMap(values, buf, depth)
 	default:
-		return newError("UnsupportedType", fmt.Sprintf("Unsupported type: %T", value))
+		return newError(UnsupportedType, fmt.Sprintf("Unsupported type: %T", value))
 	}
 }
 
 // encodeString 编码字符串
 func (poc *Poculum) encodeString(s string, buf *bytes.Buffer) error {
-	data := []byte(s)
-	length := len(data)
+	length := len(s)
 
 	if length > poc.maxStringSize {
-		return newError("DataTooLarge", fmt.Sprintf("String too long: %d bytes (max %d)", length, poc.maxStringSize))
+		return newError(DataTooLarge, fmt.Sprintf("String too long: %d bytes (max %d)", length, poc.maxStringSize))
 	}
 
-	if !utf8.Valid(data) {
-		return newError("Utf8Error", "Invalid UTF-8 string")
+	if !poc.trusted && !utf8.ValidString(s) {
+		return newError(Utf8Error, "Invalid UTF-8 string")
 	}
 
-	if length <= 15 {
-		// fixstring
-		buf.WriteByte(typeFixStringBase + byte(length))
-		buf.Write(data)
-	} else if length <= 0xFFFF {
-		// string16
-		buf.WriteByte(typeString16)
-		binary.Write(buf, binary.BigEndian, uint16(length))
-		buf.Write(data)
-	} else {
-		// string32
-		buf.WriteByte(typeString32)
-		binary.Write(buf, binary.BigEndian, uint32(length))
-		buf.Write(data)
-	}
+	// 类型字节、长度与内容一次写入
+	b := appendHeader(buf.AvailableBuffer(), typeFixStringBase, typeString16, typeString32, length)
+	buf.Write(append(b, s...))
+	return nil
+}
 
+// encodeKey 编码 map 的键。键通常是较短的 ASCII 字符串，逐字节检查比 utf8.ValidString 更快，
+// 只有含有非 ASCII 字节的键才需要完整的 UTF-8 校验
+func (poc *Poculum) encodeKey(key string, buf *bytes.Buffer) error {
+	if len(key) > 15 || len(key) > poc.maxStringSize {
+		return poc.encodeString(key, buf)
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] >= utf8.RuneSelf {
+			return poc.encodeString(key, buf)
+		}
+	}
+	b := append(buf.AvailableBuffer(), typeFixStringBase+byte(len(key)))
+	buf.Write(append(b, key...))
 	return nil
 }
 
@@ -167,23 +295,25 @@ func (poc *Poculum) encodeArray(arr []any, buf *bytes.Buffer, depth int) error {
 	length := len(arr)
 
 	if length > poc.maxContainerItems {
-		return newError("DataTooLarge", fmt.Sprintf("Array too long: %d items (max %d)", length, poc.maxContainerItems))
+		return newError(DataTooLarge, fmt.Sprintf("Array too long: %d items (max %d)", length, poc.maxContainerItems))
 	}
 
-	// 先把类型字节与长度写入到字节缓冲区
-	if length <= 15 {
-		// fixlist
-		buf.WriteByte(typeFixListBase + byte(length))
-	} else if length <= 0xFFFF {
-		// list16
-		buf.WriteByte(typeList16)
-		binary.Write(buf, binary.BigEndian, uint16(length))
-	} else {
-		// list32
-		buf.WriteByte(typeList32)
-		binary.Write(buf, binary.BigEndian, uint32(length))
+	if poc.hooks.BeforeEncode != nil {
+		hooked := make([]any, length)
+		for i, item := range arr {
+			hooked[i] = poc.beforeEncode(item)
+		}
+		arr = hooked
+	}
+
+	// 零值较多时使用稀疏编码
+	if fill, fills, ok := poc.sparseFill(arr); ok {
+		return poc.encodeSparse(arr, fill, fills, buf, depth)
 	}
 
+	// 先把类型字节与长度写入到字节缓冲区
+	writeListHeader(buf, length)
+
 	// 再逐个序列化数组中的项
 	for _, item := range arr {
 		err := poc.encodeValue(item, buf, depth+1)
@@ -195,34 +325,63 @@ func (poc *Poculum) encodeArray(arr []any, buf *bytes.Buffer, depth int) error {
 	return nil
 }
 
+// writeListHeader 写入 list 的类型字节与长度
+func writeListHeader(buf *bytes.Buffer, length int) {
+	buf.Write(appendHeader(buf.AvailableBuffer(), typeFixListBase, typeList16, typeList32, length))
+}
+
+// writeMapHeader 写入 map 的类型字节与长度
+func writeMapHeader(buf *bytes.Buffer, length int) {
+	buf.Write(appendHeader(buf.AvailableBuffer(), typeFixMapBase, typeMap16, typeMap32, length))
+}
+
+// appendHeader 把字符串、list 或者 map 的类型字节与长度追加到 b 中，
+// 长度不超过 15 时使用 fixBase 加上长度，否则使用 type16 或者 type32 加上大端序的长度
+func appendHeader(b []byte, fixBase, type16, type32 byte, length int) []byte {
+	switch {
+	case length <= 15:
+		return append(b, fixBase+byte(length))
+	case length <= 0xFFFF:
+		return binary.BigEndian.AppendUint16(append(b, type16), uint16(length))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, type32), uint32(length))
+	}
+}
+
 // encodeMap 编码对象
 func (poc *Poculum) encodeMap(obj map[string]any, buf *bytes.Buffer, depth int) error {
 	length := len(obj)
 
 	if length > poc.maxContainerItems {
-		return newError("DataTooLarge", fmt.Sprintf("Object too large: %d items (max %d)", length, poc.maxContainerItems))
+		return newError(DataTooLarge, fmt.Sprintf("Object too large: %d items (max %d)", length, poc.maxContainerItems))
 	}
 
 	// 先把类型字节写入到字节缓冲区
-	if length <= 15 {
-		// fixmap
-		buf.WriteByte(typeFixMapBase + byte(length))
-	} else if length <= 0xFFFF {
-		// map16
-		buf.WriteByte(typeMap16)
-		binary.Write(buf, binary.BigEndian, uint16(length))
-	} else {
-		// map32
-		buf.WriteByte(typeMap32)
-		binary.Write(buf, binary.BigEndian, uint32(length))
+	writeMapHeader(buf, length)
+
+	// 再逐个序列化键与值，规范编码时按照键排序
+	if poc.canonical {
+		keys := make([]string, 0, length)
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := poc.encodeKey(key, buf); err != nil {
+				return err
+			}
+			if err := poc.encodeValue(poc.beforeEncode(obj[key]), buf, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
-	// 再逐个序列化键与值
 	for key, value := range obj {
-		err := poc.encodeString(key, buf)
+		err := poc.encodeKey(key, buf)
 		if err != nil {
 			return err
 		}
-		err = poc.encodeValue(value, buf, depth+1)
+		err = poc.encodeValue(poc.beforeEncode(value), buf, depth+1)
 		if err != nil {
 			return err
 		}
@@ -258,14 +417,20 @@ func (poc *Poculum) encodeBytes(data []byte, buf *bytes.Buffer) error {
 // 序列化值为字节数组
 func (poc *Poculum) dump(value any) ([]byte, error) {
 	var buf bytes.Buffer
-	err := poc.encodeValue(value, &buf, 0)
+	if poc.redactPaths != nil {
+		value = poc.redactPath(value, poc.redactPaths)
+	}
+	err := poc.encodeValue(poc.beforeEncode(value), &buf, 0)
 	if err != nil {
 		return nil, err
 	}
+	if poc.optimize {
+		return poc.optimizeEncoded(buf.Bytes())
+	}
 	return buf.Bytes(), nil
 }
 
 func LoadPoculum(data []byte) (any, error) {
-	mb := NewPoculum()
+	mb := Default()
 	return mb.load(data)
 }
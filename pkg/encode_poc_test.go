@@ -0,0 +1,77 @@
+package poculum
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCompactFloats(t *testing.T) {
+	poc := NewPoculum(WithCompactFloats())
+	values := []float64{0.5, -1024.25, 1e10, math.Inf(-1), 0.1, math.NaN(), math.MaxFloat64}
+	data, err := poc.Dump(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0.5、-1024.25、1e10、-Inf 可以无损转换，0.1、NaN 与 MaxFloat64 不能
+	if want := 1 + 4*5 + 3*9; len(data) != want {
+		t.Fatalf("got %d bytes, want %d", len(data), want)
+	}
+
+	var got []float64
+	if err := poc.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range values {
+		if got[i] != v && !(math.IsNaN(v) && math.IsNaN(got[i])) {
+			t.Errorf("item %d: got %v, want %v", i, got[i], v)
+		}
+	}
+}
+
+func BenchmarkEncodeMap(b *testing.B) {
+	row := map[string]any{}
+	for i := 0; i < 20; i++ {
+		row[fmt.Sprintf("field_%02d", i)] = i
+	}
+	row["description"] = strings.Repeat("text ", 10)
+	rows := make([]any, 100)
+	for i := range rows {
+		rows[i] = row
+	}
+	poc := NewPoculum()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := poc.Dump(rows); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFixedIntWidth(t *testing.T) {
+	type level int
+	poc := NewPoculum(WithFixedIntWidth(), WithCanonical())
+	for _, c := range []struct {
+		value any
+		want  byte
+	}{
+		{1, typeInt64},
+		{-1, typeInt64},
+		{1 << 40, typeInt64},
+		{uint(1), typeUInt64},
+		{level(2), typeInt64},
+		{int32(1), typeInt32}, // 定宽类型不受影响
+	} {
+		data, err := poc.Dump(c.value)
+		if err != nil || data[0] != c.want {
+			t.Fatalf("%T(%v): got %x, %v", c.value, c.value, data, err)
+		}
+	}
+	// 不同大小的值编码后的长度相同
+	small, _ := poc.Dump(map[string]any{"n": 1})
+	large, _ := poc.Dump(map[string]any{"n": 1 << 40})
+	if len(small) != len(large) {
+		t.Fatalf("lengths differ: %d vs %d", len(small), len(large))
+	}
+}
@@ -0,0 +1,104 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// 枚举类型
+/*
+	使用 RegisterEnum 为命名类型注册允许的取值后，该类型的值编码为它在取值列表中的下标（uint8 或者 uint16），
+而不是字符串本身；解码到该类型时，不在取值列表中的下标或者值都会返回 UnknownEnumValue 错误。
+
+	type Color string
+	const (
+		Red   Color = "red"
+		Green Color = "green"
+	)
+	poc := poculum.NewPoculum()
+	poculum.RegisterEnum(poc, Red, Green)
+
+解码时整数总是先被当作下标，只有下标越界时才会当作取值本身进行匹配。
+注意直接 Load 得到的是下标，取值列表只能追加，不能调整已有取值的顺序。
+注册应当在使用 Poculum 编解码之前完成
+*/
+
+// Enum 可以注册为枚举的类型
+type Enum interface {
+	~string | ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// enumInfo 一个枚举类型允许的取值
+type enumInfo struct {
+	values []reflect.Value
+	index  map[any]int // 取值 -> 下标
+}
+
+// RegisterEnum 为 T 注册允许的取值，取值的顺序决定了编码使用的下标
+func RegisterEnum[T Enum](poc *Poculum, values ...T) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if len(values) == 0 {
+		return newError(InvalidEnum, fmt.Sprintf("Enum %s has no values", t))
+	}
+	if len(values) > 0xFFFF+1 {
+		return newError(InvalidEnum, fmt.Sprintf("Enum %s has too many values: %d", t, len(values)))
+	}
+
+	info := &enumInfo{index: make(map[any]int, len(values))}
+	for i, v := range values {
+		if _, dup := info.index[v]; dup {
+			return newError(InvalidEnum, fmt.Sprintf("Duplicate value %v in enum %s", v, t))
+		}
+		info.index[v] = i
+		info.values = append(info.values, reflect.ValueOf(v))
+	}
+
+	if poc.enums == nil {
+		poc.enums = make(map[reflect.Type]*enumInfo)
+	}
+	poc.enums[t] = info
+	return nil
+}
+
+// encodeEnum 把枚举值编码为下标
+func (poc *Poculum) encodeEnum(info *enumInfo, rv reflect.Value, buf *bytes.Buffer, depth int) error {
+	i, ok := info.index[rv.Interface()]
+	if !ok {
+		return newError(UnknownEnumValue, fmt.Sprintf("%v is not a registered value of %s", rv.Interface(), rv.Type()))
+	}
+	if i <= 0xFF {
+		return poc.encodeValue(uint8(i), buf, depth)
+	}
+	return poc.encodeValue(uint16(i), buf, depth)
+}
+
+// assignEnum 把下标或者取值本身解码为枚举值
+func (poc *Poculum) assignEnum(info *enumInfo, dst reflect.Value, src any, path string) error {
+	if isInteger(src) {
+		if i, ok := toUint64(src); ok && i < uint64(len(info.values)) {
+			dst.Set(info.values[i])
+			return nil
+		}
+	}
+
+	// 兼容直接写入取值本身的数据，例如其它实现写入的字符串
+	candidate := reflect.New(dst.Type()).Elem()
+	converted := false
+	if str, ok := src.(string); ok && candidate.Kind() == reflect.String {
+		candidate.SetString(str)
+		converted = true
+	} else if n, ok := toInt64(src); ok && candidate.CanInt() && !candidate.OverflowInt(n) {
+		candidate.SetInt(n)
+		converted = true
+	} else if n, ok := toUint64(src); ok && candidate.CanUint() && !candidate.OverflowUint(n) {
+		candidate.SetUint(n)
+		converted = true
+	}
+	if i, ok := info.index[candidate.Interface()]; converted && ok {
+		dst.Set(info.values[i])
+		return nil
+	}
+
+	return newError(UnknownEnumValue, fmt.Sprintf("%s: %v is not a valid %s", displayPath(path), src, dst.Type()))
+}
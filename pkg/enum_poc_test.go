@@ -0,0 +1,47 @@
+package poculum
+
+import (
+	"testing"
+)
+
+type testColor string
+
+func TestEnum(t *testing.T) {
+	poc := NewPoculum()
+	if err := RegisterEnum(poc, testColor("red"), testColor("green")); err != nil {
+		t.Fatal(err)
+	}
+
+	type paint struct {
+		Color testColor `poculum:"color"`
+	}
+	data, err := poc.Dump(paint{Color: "green"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _ := poc.Load(data)
+	if value.(map[string]any)["color"] != uint8(1) {
+		t.Fatalf("enum not encoded as index: %v", value)
+	}
+
+	var out paint
+	if err := poc.Unmarshal(data, &out); err != nil || out.Color != "green" {
+		t.Fatalf("got %v, %v", out, err)
+	}
+
+	// 其它实现直接写入的字符串
+	data, _ = DumpPoculum(map[string]any{"color": "red"})
+	if err := poc.Unmarshal(data, &out); err != nil || out.Color != "red" {
+		t.Fatalf("got %v, %v", out, err)
+	}
+
+	for _, bad := range []any{"blue", uint8(7)} {
+		data, _ = DumpPoculum(map[string]any{"color": bad})
+		if err := poc.Unmarshal(data, &out); err == nil {
+			t.Errorf("expected error for %v", bad)
+		}
+	}
+	if _, err := poc.Dump(paint{Color: "blue"}); err == nil {
+		t.Error("expected error when encoding unregistered value")
+	}
+}
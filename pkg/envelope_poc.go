@@ -0,0 +1,143 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// 消息信封
+/*
+	通过消息队列发送 poculum 数据时使用统一的信封，编码为 map：
+
+	{"topic": 字符串, "headers": {字符串: 字符串}, "content_type": 字符串, "schema_id": 整数, "body": 任意值}
+
+为空的字段不写入，body 是已经编码的值，解码信封时不会解码 body。
+解码时忽略未知的键，以后可以增加字段
+
+	env, err := poculum.NewEnvelope("orders", order)
+	env.Headers["source"] = "checkout"
+	data, err := env.Marshal()
+
+	env, err := poculum.UnmarshalEnvelope(data)
+	var order Order
+	err = env.Decode(&order)
+*/
+
+// ContentTypePoculum 信封中 body 为 poculum 编码时的 content_type
+const ContentTypePoculum = "application/poculum"
+
+// Envelope 消息信封
+type Envelope struct {
+	Topic       string            // 主题
+	Headers     map[string]string // 消息头，例如追踪信息
+	ContentType string            // body 的格式，为空时视为 ContentTypePoculum
+	SchemaID    uint32            // body 的 schema 编号，为 0 时表示没有
+	Body        RawMessage        // 已经编码的消息体
+}
+
+// NewEnvelope 使用默认实例编码 body，创建主题为 topic 的信封
+func NewEnvelope(topic string, body any) (*Envelope, error) {
+	return Default().NewEnvelope(topic, body)
+}
+
+// NewEnvelope 编码 body，创建主题为 topic 的信封
+func (poc *Poculum) NewEnvelope(topic string, body any) (*Envelope, error) {
+	data, err := poc.dump(body)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{Topic: topic, Headers: map[string]string{}, ContentType: ContentTypePoculum, Body: data}, nil
+}
+
+// Decode 使用默认实例把 body 解码到 dst 中
+func (e *Envelope) Decode(dst any) error {
+	return Default().Unmarshal(e.Body, dst)
+}
+
+// Marshal 编码信封
+func (e *Envelope) Marshal() ([]byte, error) {
+	return Default().MarshalEnvelope(e)
+}
+
+// MarshalEnvelope 编码信封，body 原样写入
+func (poc *Poculum) MarshalEnvelope(e *Envelope) ([]byte, error) {
+	m := make(map[string]any, 5)
+	if e.Topic != "" {
+		m["topic"] = e.Topic
+	}
+	if len(e.Headers) > 0 {
+		m["headers"] = e.Headers
+	}
+	if e.ContentType != "" {
+		m["content_type"] = e.ContentType
+	}
+	if e.SchemaID != 0 {
+		m["schema_id"] = e.SchemaID
+	}
+	if len(e.Body) > 0 {
+		if err := poc.validRaw(e.Body); err != nil {
+			return nil, err
+		}
+		m["body"] = e.Body
+	}
+	return poc.dump(m)
+}
+
+// UnmarshalEnvelope 使用默认实例解码信封
+func UnmarshalEnvelope(data []byte) (*Envelope, error) {
+	return Default().UnmarshalEnvelope(data)
+}
+
+// UnmarshalEnvelope 解码信封，body 保留为原始字节
+func (poc *Poculum) UnmarshalEnvelope(data []byte) (*Envelope, error) {
+	if poc.maxInputSize > 0 && len(data) > poc.maxInputSize {
+		return nil, newError(DataTooLarge, fmt.Sprintf("Input too large: %d bytes (max %d)", len(data), poc.maxInputSize))
+	}
+	reader := bytes.NewReader(data)
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isMap() {
+		return nil, newError(TypeMismatch, fmt.Sprintf("Envelope must be a map, got %s", typeName(h.typeByte)))
+	}
+
+	e := &Envelope{}
+	targets := map[string]any{
+		"topic":        &e.Topic,
+		"headers":      &e.Headers,
+		"content_type": &e.ContentType,
+		"schema_id":    &e.SchemaID,
+	}
+	for i := 0; i < h.length; i++ {
+		key, err := poc.readKey(reader)
+		if err != nil {
+			return nil, err
+		}
+		if key == "body" {
+			if e.Body, err = poc.readRaw(reader, 1); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		target, ok := targets[key]
+		if !ok {
+			if err := poc.skipValue(reader, 1); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		value, err := poc.decodeValue(reader, 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := poc.assign(reflect.ValueOf(target).Elem(), value, key); err != nil {
+			return nil, err
+		}
+	}
+	if e.Headers == nil {
+		e.Headers = map[string]string{}
+	}
+	return e, nil
+}
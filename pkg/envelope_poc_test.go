@@ -0,0 +1,41 @@
+package poculum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	type order struct {
+		ID    int      `poculum:"id"`
+		Items []string `poculum:"items"`
+	}
+	env, err := NewEnvelope("orders", order{ID: 7, Items: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Headers["source"] = "checkout"
+	env.SchemaID = 42
+	data, err := env.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := UnmarshalEnvelope(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Topic != "orders" || got.Headers["source"] != "checkout" || got.SchemaID != 42 ||
+		got.ContentType != ContentTypePoculum || !bytes.Equal(got.Body, env.Body) {
+		t.Fatalf("got %+v", got)
+	}
+	var o order
+	if err := got.Decode(&o); err != nil || o.ID != 7 || o.Items[0] != "a" {
+		t.Fatalf("got %+v, %v", o, err)
+	}
+
+	data = MustDump(map[string]any{"topic": "t", "future": []any{1, 2}})
+	if got, err = UnmarshalEnvelope(data); err != nil || got.Topic != "t" || got.Body != nil {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,134 @@
+package poculum
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorKind 错误的类别，可以用于 switch 穷举处理
+type ErrorKind uint8
+
+const (
+	InsufficientData  ErrorKind = iota + 1 // 数据提前结束
+	UnknownTypeID                          // 未知的类型字节
+	DataTooLarge                           // 超出长度、元素个数或者深度之外的大小限制
+	UnsupportedType                        // 无法编码或者解码的类型
+	Utf8Error                              // 字符串不是合法的 UTF-8
+	MaxRecursionDepth                      // 超出最大嵌套深度
+	TypeMismatch                           // 数据中的类型与期望的类型不一致
+	ValueOutOfRange                        // 数值超出目标类型的范围
+	InvalidTarget                          // Unmarshal 的目标不是非 nil 指针
+	InvalidTag                             // struct 标签无法解析
+	ValidationError                        // 不满足 struct 标签中的校验规则
+	InvalidRaw                             // RawMessage 不是一个完整的值
+	PathNotFound                           // 路径指向的值不存在
+	InvalidPath                            // 路径不合法
+	InvalidEnum                            // 枚举注册不合法
+	UnknownEnumValue                       // 不是已注册的枚举取值
+	InvalidUnion                           // 联合类型注册不合法
+	UnknownVariant                         // 未知的联合类型变体
+	DuplicateItem                          // 集合中存在重复元素
+	InvalidSparse                          // 稀疏 list 不合法
+	InvalidBitset                          // 位压缩的 []bool 不合法
+	InvalidTensor                          // 张量不合法
+	InvalidAddr                            // IP 地址或者前缀不合法
+	InvalidURL                             // URL 无法解析
+	InvalidTime                            // 时间戳不合法
+	NonUTCTime                             // 时区策略要求 UTC 时间
+	InvalidBigNumber                       // 高精度数字不合法
+	InvalidIntMap                          // 整数键的 map 不合法
+	InvalidEncoder                         // 自定义编码函数没有写入值
+	DecoderError                           // 自定义解码函数返回错误
+	InvalidPatch                           // Patch 不合法
+	InvalidSnapshot                        // 快照格式不合法
+	ChecksumMismatch                       // 校验和不一致
+	IOError                                // 读写数据时的 I/O 错误
+	InvalidFrame                           // 帧头不合法
+	InvalidJSON                            // 嵌入的 JSON 片段不合法
+	InvalidText                            // 十六进制等文本形式的数据无法解析
+	IncompatiblePeer                       // 握手时双方没有共同支持的格式版本
+)
+
+// errorKindNames 错误类别的名称，与引入 ErrorKind 之前的 Type 字符串一致
+var errorKindNames = [...]string{
+	InsufficientData:  "InsufficientData",
+	UnknownTypeID:     "UnknownTypeId",
+	DataTooLarge:      "DataTooLarge",
+	UnsupportedType:   "UnsupportedType",
+	Utf8Error:         "Utf8Error",
+	MaxRecursionDepth: "MaxRecursionDepth",
+	TypeMismatch:      "TypeMismatch",
+	ValueOutOfRange:   "ValueOutOfRange",
+	InvalidTarget:     "InvalidTarget",
+	InvalidTag:        "InvalidTag",
+	ValidationError:   "ValidationError",
+	InvalidRaw:        "InvalidRaw",
+	PathNotFound:      "PathNotFound",
+	InvalidPath:       "InvalidPath",
+	InvalidEnum:       "InvalidEnum",
+	UnknownEnumValue:  "UnknownEnumValue",
+	InvalidUnion:      "InvalidUnion",
+	UnknownVariant:    "UnknownVariant",
+	DuplicateItem:     "DuplicateItem",
+	InvalidSparse:     "InvalidSparse",
+	InvalidBitset:     "InvalidBitset",
+	InvalidTensor:     "InvalidTensor",
+	InvalidAddr:       "InvalidAddr",
+	InvalidURL:        "InvalidURL",
+	InvalidTime:       "InvalidTime",
+	NonUTCTime:        "NonUTCTime",
+	InvalidBigNumber:  "InvalidBigNumber",
+	InvalidIntMap:     "InvalidIntMap",
+	InvalidEncoder:    "InvalidEncoder",
+	DecoderError:      "DecoderError",
+	InvalidPatch:      "InvalidPatch",
+	InvalidSnapshot:   "InvalidSnapshot",
+	ChecksumMismatch:  "ChecksumMismatch",
+	IOError:           "IOError",
+	InvalidFrame:      "InvalidFrame",
+	InvalidJSON:       "InvalidJSON",
+	InvalidText:       "InvalidText",
+	IncompatiblePeer:  "IncompatiblePeer",
+}
+
+func (k ErrorKind) String() string {
+	if int(k) < len(errorKindNames) && errorKindNames[k] != "" {
+		return errorKindNames[k]
+	}
+	return fmt.Sprintf("ErrorKind(%d)", uint8(k))
+}
+
+// PoculumError 错误类型
+type PoculumError struct {
+	Kind    ErrorKind
+	Message string
+	Cause   error // 导致该错误的底层错误，例如读取数据时的 I/O 错误，可能为 nil
+}
+
+func (e *PoculumError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Kind, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+// Unwrap 返回底层错误，可以使用 errors.Is 判断例如 io.ErrUnexpectedEOF 或者网络超时
+func (e *PoculumError) Unwrap() error {
+	return e.Cause
+}
+
+// IsKind 判断 err 的错误链中是否有类别为 kind 的 PoculumError
+func IsKind(err error, kind ErrorKind) bool {
+	var pe *PoculumError
+	return errors.As(err, &pe) && pe.Kind == kind
+}
+
+// 错误构造函数
+func newError(kind ErrorKind, message string) *PoculumError {
+	return &PoculumError{Kind: kind, Message: message}
+}
+
+// wrapError 构造带有底层错误的错误
+func wrapError(kind ErrorKind, message string, cause error) *PoculumError {
+	return &PoculumError{Kind: kind, Message: message, Cause: cause}
+}
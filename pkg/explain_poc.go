@@ -0,0 +1,199 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Segment 编码数据中的一段，由 Explain 生成
+type Segment struct {
+	Offset int    `json:"offset"` // 在数据中的偏移
+	Length int    `json:"length"` // 字节数，标量、字符串与字节数据是整个值，list、map 与扩展类型只包括头部
+	Header int    `json:"header"` // 其中类型字节、长度字段与扩展标识的字节数
+	Depth  int    `json:"depth"`  // 嵌套深度，顶层值为 0，map 的键与对应的值深度相同
+	Path   string `json:"path"`   // 值的路径，使用与 Get 相同的语法，map 的键的路径是对应的值的路径
+	Kind   string `json:"kind"`   // SegmentValue、SegmentKey 或者 SegmentInvalid
+	Type   string `json:"type"`   // 线上类型，例如 "uint8"、"fixstr"、"list16"，扩展类型为 "ext"
+	// Preview 值的简短描述：标量为解码的值，较长的字符串与字节数据被截断，
+	// list 与 map 为元素个数，扩展类型为扩展标识，SegmentInvalid 为错误信息
+	Preview string `json:"preview"`
+}
+
+// Segment 的种类
+const (
+	SegmentValue   = "value"
+	SegmentKey     = "key"
+	SegmentInvalid = "invalid" // 无法解析的剩余数据，总是最后一段
+)
+
+// extNames 内置扩展类型的名称
+var extNames = map[byte]string{
+	extSet:      "set",
+	extTuple:    "tuple",
+	extSparse:   "sparse",
+	extBitset:   "bitset",
+	extTensor:   "tensor",
+	extAddr:     "addr",
+	extPrefix:   "prefix",
+	extURL:      "url",
+	extTime:     "time",
+	extBigFloat: "bigfloat",
+	extBigRat:   "bigrat",
+	extIntMap:   "intmap",
+	extJSON:     "json",
+}
+
+// 预览中字符串与字节数据的最大字节数
+const (
+	previewString = 40
+	previewBytes  = 16
+)
+
+// Explain 使用默认实例解析编码数据的结构
+func Explain(data []byte) []Segment {
+	return Default().Explain(data)
+}
+
+// Explain 按照在数据中的顺序列出 data 中每个值与 map 的键的偏移、长度、类型与预览，
+// 用于命令行的 inspect 命令以及调试工具的可视化。data 可以包含多个首尾相接的值，
+// 数据不合法时已经解析的部分仍然返回，最后一段为 SegmentInvalid
+func (poc *Poculum) Explain(data []byte) []Segment {
+	e := explainer{poc: poc, data: data, reader: bytes.NewReader(data)}
+	for e.reader.Len() > 0 {
+		if err := e.value("", 0, SegmentValue); err != nil {
+			offset := e.start
+			e.segments = append(e.segments, Segment{
+				Offset: offset, Length: len(data) - offset, Path: e.path, Kind: SegmentInvalid, Preview: err.Error(),
+			})
+			break
+		}
+	}
+	return e.segments
+}
+
+// explainer 保存解析的状态
+type explainer struct {
+	poc      *Poculum
+	data     []byte
+	reader   *bytes.Reader
+	segments []Segment
+	path     string // 正在解析的值的路径
+	start    int    // 正在解析的值的偏移
+	ext      int    // 正在解析的扩展类型的层数，不计入 Depth，但是受最大递归深度的限制
+}
+
+func (e *explainer) offset() int {
+	return len(e.data) - e.reader.Len()
+}
+
+// value 解析下一个值，kind 为 SegmentKey 时值必须是字符串
+func (e *explainer) value(path string, depth int, kind string) error {
+	e.path, e.start = path, e.offset()
+	if depth+e.ext > e.poc.maxRecursionDepth {
+		return newError(MaxRecursionDepth, "Maximum recursion depth exceeded while parsing nested structure")
+	}
+
+	start := e.start
+	h, err := readHeader(e.reader)
+	if err != nil {
+		return err
+	}
+	seg := Segment{Offset: start, Header: e.offset() - start, Depth: depth, Path: path, Kind: kind, Type: wireTypeName(h.typeByte)}
+	if kind == SegmentKey && typeName(h.typeByte) != "string" {
+		return newError(UnsupportedType, "Object key must be string")
+	}
+
+	switch {
+	case h.typeByte == typeExt:
+		seg.Length = seg.Header
+		seg.Preview = fmt.Sprintf("0x%02x", h.tag)
+		if name, ok := extNames[h.tag]; ok {
+			seg.Preview = name + " (" + seg.Preview + ")"
+		}
+		e.segments = append(e.segments, seg)
+		e.ext++
+		err := e.value(path, depth, SegmentValue)
+		e.ext--
+		return err
+
+	case h.isList():
+		seg.Length = seg.Header
+		seg.Preview = fmt.Sprintf("%d items", h.length)
+		e.segments = append(e.segments, seg)
+		for i := 0; i < h.length; i++ {
+			if err := e.value(joinPath(path, strconv.Itoa(i)), depth+1, SegmentValue); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case h.isMap():
+		seg.Length = seg.Header
+		seg.Preview = fmt.Sprintf("%d entries", h.length)
+		e.segments = append(e.segments, seg)
+		for i := 0; i < h.length; i++ {
+			if err := e.value(path, depth+1, SegmentKey); err != nil {
+				return err
+			}
+			if err := e.value(e.segments[len(e.segments)-1].Path, depth+1, SegmentValue); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if h.length > e.reader.Len() {
+		return newError(InsufficientData, fmt.Sprintf("%s data", typeName(h.typeByte)))
+	}
+	body := e.data[e.offset():][:h.length]
+	e.reader.Seek(int64(h.length), io.SeekCurrent)
+	seg.Length = seg.Header + h.length
+	seg.Preview = previewScalar(h.typeByte, body)
+	if kind == SegmentKey {
+		seg.Path = joinPath(path, string(body))
+	}
+	e.segments = append(e.segments, seg)
+	return nil
+}
+
+// previewScalar 返回标量、字符串或者字节数据的预览
+func previewScalar(typeByte byte, body []byte) string {
+	switch typeByte {
+	case typeUInt8, typeUInt16, typeUInt32, typeUInt64:
+		return strconv.FormatUint(readUintBytes(body), 10)
+	case typeInt8, typeInt16, typeInt32, typeInt64:
+		shift := 64 - 8*len(body)
+		return strconv.FormatInt(int64(readUintBytes(body)<<shift)>>shift, 10)
+	case typeFloat32:
+		return strconv.FormatFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(body))), 'g', -1, 32)
+	case typeFloat64:
+		return strconv.FormatFloat(math.Float64frombits(binary.BigEndian.Uint64(body)), 'g', -1, 64)
+	case typeTrue:
+		return "true"
+	case typeFalse:
+		return "false"
+	case typeNil:
+		return "nil"
+	case typeBytes8, typeBytes16, typeBytes32:
+		if len(body) > previewBytes {
+			return fmt.Sprintf("%s… (%d bytes)", hex.EncodeToString(body[:previewBytes]), len(body))
+		}
+		return hex.EncodeToString(body)
+	}
+
+	// 字符串在 UTF-8 字符的边界处截断
+	if len(body) <= previewString {
+		return strconv.Quote(string(body))
+	}
+	cut := previewString
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s… (%d bytes)", strconv.Quote(string(body[:cut])), len(body))
+}
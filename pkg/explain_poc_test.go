@@ -0,0 +1,43 @@
+package poculum
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	data, err := NewPoculum(WithCanonical()).Dump(map[string]any{"tags": Set{"a"}, "note": strings.Repeat("é", 30)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Explain(data)
+	want := []Segment{
+		{Offset: 0, Length: 1, Header: 1, Path: "", Kind: SegmentValue, Type: "fixmap", Preview: "2 entries"},
+		{Offset: 1, Length: 5, Header: 1, Depth: 1, Path: "note", Kind: SegmentKey, Type: "fixstr", Preview: `"note"`},
+		{Offset: 6, Length: 63, Header: 3, Depth: 1, Path: "note", Kind: SegmentValue, Type: "str16",
+			Preview: `"` + strings.Repeat("é", 20) + `"… (60 bytes)`},
+		{Offset: 69, Length: 5, Header: 1, Depth: 1, Path: "tags", Kind: SegmentKey, Type: "fixstr", Preview: `"tags"`},
+		{Offset: 74, Length: 2, Header: 2, Depth: 1, Path: "tags", Kind: SegmentValue, Type: "ext", Preview: "set (0x01)"},
+		{Offset: 76, Length: 1, Header: 1, Depth: 1, Path: "tags", Kind: SegmentValue, Type: "fixlist", Preview: "1 items"},
+		{Offset: 77, Length: 2, Header: 1, Depth: 2, Path: "tags.0", Kind: SegmentValue, Type: "fixstr", Preview: `"a"`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got\n%+v\nwant\n%+v", got, want)
+	}
+
+	got = Explain(data[:len(data)-1])
+	if last := got[len(got)-1]; last.Kind != SegmentInvalid || last.Offset != 77 || last.Path != "tags.0" {
+		t.Fatalf("truncated data: %+v", last)
+	}
+}
+
+func TestExplainExtChain(t *testing.T) {
+	data := append(bytes.Repeat([]byte{typeExt, extSet}, 1<<16), typeNil)
+	segments := NewPoculumSecure().Explain(data)
+	last := segments[len(segments)-1]
+	if last.Kind != SegmentInvalid || !strings.Contains(last.Preview, "recursion depth") {
+		t.Fatalf("got %+v", last)
+	}
+}
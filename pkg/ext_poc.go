@@ -0,0 +1,101 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// 扩展类型说明
+/*
+	扩展类型由类型字节 0xB0、一个字节的扩展标识以及一个普通的值组成：
+
+	0xB0 <扩展标识> <值>
+
+不认识某个扩展标识的实现仍然可以把其中的值当作普通值解码或者跳过。
+0x00~0x7F 由 poculum 保留给内置的扩展类型，应用可以使用 0x80~0xFF
+*/
+
+// 内置的扩展标识
+const (
+	extSet      = 0x01 // 集合，值为元素不重复的 list
+	extTuple    = 0x02 // 元组，值为固定元素个数的 list
+	extSparse   = 0x03 // 稀疏 list，值为 [逻辑长度, 填充值, 下标, 值, ...]
+	extBitset   = 0x04 // 位压缩的 []bool，值为 [元素个数, 字节数据]
+	extTensor   = 0x05 // 张量，值为 [元素类型, 形状, 数据]
+	extAddr     = 0x06 // IP 地址，值为 4 或 16 个字节
+	extPrefix   = 0x07 // IP 前缀，值为地址字节加一个字节的前缀长度
+	extURL      = 0x08 // URL，值为字符串
+	extTime     = 0x09 // 时间戳，值为 [Unix 秒数, 纳秒] 或者 [Unix 秒数, 纳秒, 偏移秒数]
+	extBigFloat = 0x0A // 高精度浮点数，值为 [精度, 文本]
+	extBigRat   = 0x0B // 有理数，值为 "分子/分母" 字符串
+	extIntMap   = 0x0C // 整数键的 map，值为 [键, 值, 键, 值, ...]
+	extJSON     = 0x0D // JSON 片段，值为 JSON 文本的字符串
+)
+
+// Ext 未知扩展标识的扩展类型值，也可以用来编码应用自定义的扩展类型
+type Ext struct {
+	Tag   uint8
+	Value any
+}
+
+// encodeExt 编码扩展类型
+func (poc *Poculum) encodeExt(ext Ext, buf *bytes.Buffer, depth int) error {
+	buf.WriteByte(typeExt)
+	buf.WriteByte(ext.Tag)
+	return poc.encodeValue(ext.Value, buf, depth+1)
+}
+
+// decodeExt 解码扩展类型，类型字节已经被读取
+func (poc *Poculum) decodeExt(reader *bytes.Reader, depth int) (any, error) {
+	tag, err := reader.ReadByte()
+	if err != nil {
+		return nil, newError(InsufficientData, "ext tag")
+	}
+
+	switch tag {
+	case extSet:
+		return poc.decodeSet(reader, depth+1)
+	case extTuple:
+		return poc.decodeTuple(reader, depth+1)
+	case extSparse:
+		return poc.decodeSparse(reader, depth+1)
+	case extBitset:
+		return poc.decodeBools(reader, depth+1)
+	case extTensor:
+		return poc.decodeTensor(reader, depth+1)
+	case extAddr:
+		return poc.decodeAddr(reader, depth+1)
+	case extPrefix:
+		return poc.decodePrefix(reader, depth+1)
+	case extURL:
+		return poc.decodeURL(reader, depth+1)
+	case extTime:
+		return poc.decodeTime(reader, depth+1)
+	case extBigFloat:
+		return poc.decodeBigFloat(reader, depth+1)
+	case extBigRat:
+		return poc.decodeBigRat(reader, depth+1)
+	case extIntMap:
+		return poc.decodeIntMap(reader, depth+1)
+	case extJSON:
+		return poc.decodeJSON(reader, depth+1)
+	}
+
+	value, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	return Ext{Tag: tag, Value: value}, nil
+}
+
+// expectList 读取 list 的头部，扩展类型要求其中的值是 list 时使用
+func expectList(reader *bytes.Reader, what string) (int, error) {
+	h, err := readHeader(reader)
+	if err != nil {
+		return 0, err
+	}
+	if !h.isList() {
+		return 0, newError(TypeMismatch, fmt.Sprintf("%s must contain a list, got %s", what, typeName(h.typeByte)))
+	}
+	return h.length, nil
+}
@@ -0,0 +1,69 @@
+package poculum
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// instance 返回应用了 opts 的实例，没有 opts 时直接使用默认实例
+func instance(opts []ConfigOption) *Poculum {
+	if len(opts) == 0 {
+		return Default()
+	}
+	return Default().With(opts...)
+}
+
+// LoadFile 读取 path 处的文件并解码到 dst 中，struct 标签中的 default、required 等规则同样生效。
+// 文件不存在等 I/O 错误原样返回，可以使用 errors.Is(err, os.ErrNotExist) 判断
+func LoadFile(path string, dst any, opts ...ConfigOption) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return instance(opts).Unmarshal(data, dst)
+}
+
+// SaveFile 把 v 编码后原子地写入 path：先写入同一目录中的临时文件并刷新到磁盘，再重命名覆盖，
+// 所以读取方要么看到旧文件，要么看到完整的新文件。已有文件的权限会被保留，新文件的权限为 0644
+func SaveFile(path string, v any, opts ...ConfigOption) error {
+	data, err := instance(opts).dump(v)
+	if err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	ok := false
+	defer func() {
+		if !ok {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	ok = true
+	return nil
+}
@@ -0,0 +1,70 @@
+package poculum
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadFile(t *testing.T) {
+	type Config struct {
+		Addr    string `poculum:"addr,required"`
+		Workers int    `poculum:"workers,default=4"`
+	}
+	path := filepath.Join(t.TempDir(), "app.poc")
+
+	var cfg Config
+	if err := LoadFile(path, &cfg); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist, got %v", err)
+	}
+
+	if err := SaveFile(path, map[string]any{"addr": ":8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadFile(path, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != ":8080" || cfg.Workers != 4 {
+		t.Fatalf("got %+v", cfg)
+	}
+
+	if err := SaveFile(path, map[string]any{"workers": 8}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadFile(path, &cfg); err == nil {
+		t.Fatal("expected required field error")
+	}
+	if err := LoadFile(path, &map[string]any{}, WithMaxContainerItems(0)); err == nil {
+		t.Fatal("options were not applied")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestMust(t *testing.T) {
+	data := MustDump(map[string]any{"port": uint16(9000)})
+	if m := MustLoad(data).(map[string]any); m["port"] != uint16(9000) {
+		t.Fatalf("got %v", m)
+	}
+
+	type Config struct {
+		Port int `poculum:"port"`
+	}
+	if cfg := LoadAs[Config](data).Must(); cfg.Port != 9000 {
+		t.Fatalf("got %+v", cfg)
+	}
+	if port := LoadAs[int](data).Or(8080); port != 8080 {
+		t.Fatalf("Or fallback: got %d", port)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustLoad did not panic on invalid data")
+		}
+	}()
+	MustLoad([]byte{0xFF})
+}
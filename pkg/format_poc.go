@@ -0,0 +1,143 @@
+package poculum
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// 字段格式
+/*
+	struct 标签中的 format 选项指定字段在数据中的表示方式，方便与其他语言的实现交互：
+
+	type Event struct {
+		Created time.Time `poculum:"created,format=unix_ms"` // 编码为 Unix 毫秒数
+		Digest  []byte    `poculum:"digest,format=hex"`      // 编码为十六进制字符串
+	}
+
+time.Time（以及 *time.Time）字段支持：
+	unix、unix_ms、unix_us、unix_ns  编码为对应精度的 Unix 时间整数，解码为 UTC 时间
+	rfc3339                         编码为 RFC 3339 字符串，保留纳秒与时区偏移
+
+[]byte 字段支持：
+	base64、base64url               编码为标准或者 URL 安全的 Base64 字符串（带填充）
+	hex                             编码为小写的十六进制字符串
+
+nil 指针与 nil 切片编码为 nil。format 与字段类型不匹配时编码与解码都会返回错误
+*/
+
+// 支持的字段格式
+var fieldFormats = map[string]reflect.Type{
+	"unix":      timeType,
+	"unix_ms":   timeType,
+	"unix_us":   timeType,
+	"unix_ns":   timeType,
+	"rfc3339":   timeType,
+	"base64":    bytesType,
+	"base64url": bytesType,
+	"hex":       bytesType,
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// checkFormat 检查字段类型是否支持 format
+func checkFormat(format string, t reflect.Type) error {
+	want, ok := fieldFormats[format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if t.Kind() == reflect.Pointer && want == timeType {
+		t = t.Elem()
+	}
+	if t != want {
+		return fmt.Errorf("format %s is not supported for %s", format, t)
+	}
+	return nil
+}
+
+// formatValue 把字段的值转换为 format 指定的表示
+func formatValue(format string, field reflect.Value) (any, error) {
+	if err := checkFormat(format, field.Type()); err != nil {
+		return nil, err
+	}
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil, nil
+		}
+		field = field.Elem()
+	}
+
+	switch v := field.Interface().(type) {
+	case time.Time:
+		switch format {
+		case "unix":
+			return v.Unix(), nil
+		case "unix_ms":
+			return v.UnixMilli(), nil
+		case "unix_us":
+			return v.UnixMicro(), nil
+		case "unix_ns":
+			return v.UnixNano(), nil
+		}
+		return v.Format(time.RFC3339Nano), nil
+	case []byte:
+		if v == nil {
+			return nil, nil
+		}
+		switch format {
+		case "base64":
+			return base64.StdEncoding.EncodeToString(v), nil
+		case "base64url":
+			return base64.URLEncoding.EncodeToString(v), nil
+		}
+		return hex.EncodeToString(v), nil
+	}
+	return nil, fmt.Errorf("format %s is not supported for %s", format, field.Type())
+}
+
+// parseFormat 把 format 指定的表示还原为字段类型的值，t 是字段的类型
+func parseFormat(format string, src any, t reflect.Type) (any, error) {
+	if err := checkFormat(format, t); err != nil {
+		return nil, err
+	}
+
+	if fieldFormats[format] == timeType {
+		if format == "rfc3339" {
+			s, ok := src.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected RFC 3339 string, got %T", src)
+			}
+			return time.Parse(time.RFC3339Nano, s)
+		}
+		n, ok := toInt64(src)
+		if !ok {
+			return nil, fmt.Errorf("expected integer timestamp, got %T", src)
+		}
+		switch format {
+		case "unix":
+			return time.Unix(n, 0).UTC(), nil
+		case "unix_ms":
+			return time.UnixMilli(n).UTC(), nil
+		case "unix_us":
+			return time.UnixMicro(n).UTC(), nil
+		}
+		return time.Unix(0, n).UTC(), nil
+	}
+
+	s, ok := src.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected %s string, got %T", format, src)
+	}
+	switch format {
+	case "base64":
+		return base64.StdEncoding.DecodeString(s)
+	case "base64url":
+		return base64.URLEncoding.DecodeString(s)
+	}
+	return hex.DecodeString(s)
+}
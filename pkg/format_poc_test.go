@@ -0,0 +1,50 @@
+package poculum
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFieldFormat(t *testing.T) {
+	type event struct {
+		Created time.Time  `poculum:"created,format=unix_ms"`
+		Updated *time.Time `poculum:"updated,format=rfc3339"`
+		Digest  []byte     `poculum:"digest,format=hex"`
+		Body    []byte     `poculum:"body,format=base64"`
+	}
+	created := time.UnixMilli(1700000000123).UTC()
+	updated := time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("", 3600))
+	in := event{Created: created, Updated: &updated, Digest: []byte{0xde, 0xad}}
+
+	data, err := DumpPoculum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := LoadPoculum(data)
+	want := map[string]any{
+		"created": int64(1700000000123),
+		"updated": "2024-01-02T03:04:05.000000006+01:00",
+		"digest":  "dead",
+		"body":    nil,
+	}
+	if !reflect.DeepEqual(raw, want) {
+		t.Fatalf("wire form = %#v", raw)
+	}
+
+	var out event
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Created.Equal(created) || !out.Updated.Equal(updated) || !bytes.Equal(out.Digest, in.Digest) || out.Body != nil {
+		t.Fatalf("got %+v", out)
+	}
+
+	type bad struct {
+		N int `poculum:"n,format=hex"`
+	}
+	if _, err := DumpPoculum(bad{}); !IsKind(err, InvalidTag) {
+		t.Fatalf("expected InvalidTag, got %v", err)
+	}
+}
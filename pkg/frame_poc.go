@@ -0,0 +1,274 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// 帧格式
+/*
+	FrameWriter 把每个值写为一帧，FrameReader 逐帧读取：
+
+	0xB5 0x0C | 数据长度 4 字节 | CRC32 4 字节 | 数据
+
+数据为一个完整的编码值，CRC32（IEEE）针对数据计算，多字节字段使用大端序。
+与首尾相接的值不同，帧带有边界与校验和，数据损坏后可以在下一帧的开头重新同步：
+使用 WithResync 创建的 FrameReader 遇到损坏的帧时跳过它，向后查找下一个校验通过的帧，
+并通过回调报告跳过的字节范围，一条坏记录不会中断长时间运行的消费者。
+长度字段超过上限（见 WithMaxFrameSize）的帧同样视为损坏，缓冲区只随着实际读到的数据增长
+*/
+
+const (
+	frameMagic0     = 0xB5
+	frameMagic1     = 0x0C
+	frameHeaderSize = 2 + 4 + 4
+
+	frameReadSize    = 64 << 10 // 每次从底层 reader 读取的最大字节数，缓冲区随着实际收到的数据增长
+	defaultFrameSize = 64 << 20 // 实例没有限制输入大小时帧数据的最大字节数
+)
+
+// FrameWriter 把值写为帧
+type FrameWriter struct {
+	poc *Poculum
+	w   io.Writer
+}
+
+// NewFrameWriter 使用默认实例创建写入 w 的 FrameWriter
+func NewFrameWriter(w io.Writer) *FrameWriter {
+	return Default().NewFrameWriter(w)
+}
+
+// NewFrameWriter 创建写入 w 的 FrameWriter
+func (poc *Poculum) NewFrameWriter(w io.Writer) *FrameWriter {
+	return &FrameWriter{poc: poc, w: w}
+}
+
+// Write 编码 value 并写为一帧
+func (fw *FrameWriter) Write(value any) error {
+	data, err := fw.poc.dump(value)
+	if err != nil {
+		return err
+	}
+	return fw.WriteRaw(data)
+}
+
+// WriteRaw 把已经编码好的值写为一帧
+func (fw *FrameWriter) WriteRaw(raw RawMessage) error {
+	if err := fw.poc.validRaw(raw); err != nil {
+		return err
+	}
+	if uint64(len(raw)) > math.MaxUint32 {
+		return newError(DataTooLarge, fmt.Sprintf("Frame too large: %d bytes", len(raw)))
+	}
+
+	frame := make([]byte, frameHeaderSize, frameHeaderSize+len(raw))
+	frame[0], frame[1] = frameMagic0, frameMagic1
+	binary.BigEndian.PutUint32(frame[2:], uint32(len(raw)))
+	binary.BigEndian.PutUint32(frame[6:], crc32.ChecksumIEEE(raw))
+	frame = append(frame, raw...)
+	if _, err := fw.w.Write(frame); err != nil {
+		return wrapError(IOError, "write frame", err)
+	}
+	return nil
+}
+
+// SkippedRange 重新同步时跳过的字节范围
+type SkippedRange struct {
+	Offset int64 // 跳过的第一个字节在流中的偏移
+	Length int64 // 跳过的字节数
+	Err    error // 导致跳过的错误
+}
+
+// FrameReaderOption 配置 FrameReader 的选项
+type FrameReaderOption func(*FrameReader)
+
+// WithMaxFrameSize 设置帧数据的最大字节数，长度字段超过它的帧被视为损坏。
+// 默认使用实例的 maxInputSize，没有设置时为 64MB
+func WithMaxFrameSize(n int) FrameReaderOption {
+	return func(fr *FrameReader) {
+		fr.maxSize = n
+	}
+}
+
+// WithResync 遇到损坏的帧或者无法解码的数据时跳过并重新同步，每跳过一段数据调用一次 onSkip，onSkip 可以为 nil
+func WithResync(onSkip func(SkippedRange)) FrameReaderOption {
+	return func(fr *FrameReader) {
+		fr.resync = true
+		fr.onSkip = onSkip
+	}
+}
+
+// FrameReader 逐帧读取值
+type FrameReader struct {
+	poc    *Poculum
+	r      io.Reader
+	buf    []byte // 已经读取但还没有处理的数据
+	offset int64  // buf[0] 在流中的偏移
+	eof    bool   // 底层 reader 已经返回 io.EOF
+
+	maxSize int // 帧数据的最大字节数，为 0 时使用默认值
+
+	resync bool
+	onSkip func(SkippedRange)
+}
+
+// NewFrameReader 使用默认实例创建从 r 读取的 FrameReader
+func NewFrameReader(r io.Reader, opts ...FrameReaderOption) *FrameReader {
+	return Default().NewFrameReader(r, opts...)
+}
+
+// NewFrameReader 创建从 r 读取的 FrameReader
+func (poc *Poculum) NewFrameReader(r io.Reader, opts ...FrameReaderOption) *FrameReader {
+	fr := &FrameReader{poc: poc, r: r}
+	for _, opt := range opts {
+		opt(fr)
+	}
+	return fr
+}
+
+// fill 确保 buf 中至少有 n 个字节，数据不足时返回底层的错误
+func (fr *FrameReader) fill(n int) error {
+	for len(fr.buf) < n {
+		if fr.eof {
+			return io.EOF
+		}
+		// 分块读取，损坏的长度字段不会导致按照它一次分配内存
+		chunk := make([]byte, min(max(n-len(fr.buf), 4096), frameReadSize))
+		m, err := fr.r.Read(chunk)
+		fr.buf = append(fr.buf, chunk[:m]...)
+		if err == io.EOF {
+			fr.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// consume 丢弃 buf 开头的 n 个字节
+func (fr *FrameReader) consume(n int) {
+	fr.buf = fr.buf[n:]
+	fr.offset += int64(n)
+}
+
+// maxFrameSize 帧数据的最大字节数
+func (fr *FrameReader) maxFrameSize() int64 {
+	switch {
+	case fr.maxSize > 0:
+		return int64(fr.maxSize)
+	case fr.poc.maxInputSize > 0:
+		return int64(fr.poc.maxInputSize)
+	}
+	return defaultFrameSize
+}
+
+// frameAt 检查 buf 开头的帧，返回帧的总字节数
+func (fr *FrameReader) frameAt() (int, error) {
+	if err := fr.fill(frameHeaderSize); err != nil {
+		return 0, err
+	}
+	if fr.buf[0] != frameMagic0 || fr.buf[1] != frameMagic1 {
+		return 0, newError(InvalidFrame, fmt.Sprintf("Bad frame magic at offset %d", fr.offset))
+	}
+	length := int64(binary.BigEndian.Uint32(fr.buf[2:]))
+	if length > fr.maxFrameSize() {
+		return 0, newError(DataTooLarge, fmt.Sprintf("Frame too large at offset %d: %d bytes (max %d)", fr.offset, length, fr.maxFrameSize()))
+	}
+	size := frameHeaderSize + int(length)
+	if err := fr.fill(size); err != nil {
+		return 0, err
+	}
+	if crc32.ChecksumIEEE(fr.buf[frameHeaderSize:size]) != binary.BigEndian.Uint32(fr.buf[6:]) {
+		return 0, newError(ChecksumMismatch, fmt.Sprintf("Frame checksum mismatch at offset %d", fr.offset))
+	}
+	if err := fr.poc.validRaw(fr.buf[frameHeaderSize:size]); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// ReadRaw 读取下一帧中的值，没有更多的帧时返回 io.EOF
+func (fr *FrameReader) ReadRaw() (RawMessage, error) {
+	var skipped *SkippedRange
+	for {
+		if len(fr.buf) == 0 {
+			if err := fr.fill(1); err == io.EOF {
+				if skipped != nil {
+					fr.report(skipped)
+				}
+				return nil, io.EOF
+			} else if err != nil {
+				return nil, wrapError(IOError, "read frame", err)
+			}
+		}
+
+		size, err := fr.frameAt()
+		if err == nil {
+			if skipped != nil {
+				fr.report(skipped)
+			}
+			raw := append(RawMessage(nil), fr.buf[frameHeaderSize:size]...)
+			fr.consume(size)
+			return raw, nil
+		}
+
+		if _, ok := err.(*PoculumError); !ok {
+			// 底层 reader 的错误，只有数据提前结束时才可以重新同步
+			if err != io.EOF {
+				return nil, wrapError(IOError, "read frame", err)
+			}
+			err = wrapError(InsufficientData, fmt.Sprintf("frame at offset %d", fr.offset), io.ErrUnexpectedEOF)
+		}
+		if !fr.resync {
+			return nil, err
+		}
+
+		// 跳过一个字节，再查找下一个帧头
+		if skipped == nil {
+			skipped = &SkippedRange{Offset: fr.offset, Err: err}
+		}
+		fr.consume(1)
+		skipped.Length++
+		for len(fr.buf) == 0 || fr.buf[0] != frameMagic0 {
+			if len(fr.buf) == 0 && fr.fill(1) != nil {
+				// 数据结束或者读取出错，由外层循环处理
+				break
+			}
+			if i := bytes.IndexByte(fr.buf, frameMagic0); i >= 0 {
+				fr.consume(i)
+				skipped.Length += int64(i)
+				break
+			}
+			skipped.Length += int64(len(fr.buf))
+			fr.consume(len(fr.buf))
+		}
+	}
+}
+
+// report 报告跳过的数据
+func (fr *FrameReader) report(skipped *SkippedRange) {
+	if fr.onSkip != nil {
+		fr.onSkip(*skipped)
+	}
+}
+
+// Decode 读取下一帧并解码到 dst 中，没有更多的帧时返回 io.EOF。
+// 启用重新同步时，校验通过但无法解码到 dst 的帧同样被跳过并报告
+func (fr *FrameReader) Decode(dst any) error {
+	for {
+		raw, err := fr.ReadRaw()
+		if err != nil {
+			return err
+		}
+		err = fr.poc.Unmarshal(raw, dst)
+		if err == nil || !fr.resync {
+			return err
+		}
+		size := int64(frameHeaderSize + len(raw))
+		fr.report(&SkippedRange{Offset: fr.offset - size, Length: size, Err: err})
+	}
+}
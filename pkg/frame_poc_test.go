@@ -0,0 +1,130 @@
+package poculum
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFrameResync(t *testing.T) {
+	var stream bytes.Buffer
+	fw := NewFrameWriter(&stream)
+	for i := 0; i < 5; i++ {
+		if err := fw.Write(map[string]any{"seq": uint8(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := stream.Bytes()
+	frameSize := len(data) / 5
+
+	// 破坏第 2 帧的数据
+	corrupt := append([]byte(nil), data...)
+	corrupt[frameSize+frameHeaderSize+2] ^= 0xFF
+
+	// 不重新同步时返回错误
+	fr := NewFrameReader(bytes.NewReader(corrupt))
+	var v map[string]any
+	if err := fr.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if err := fr.Decode(&v); !IsKind(err, ChecksumMismatch) {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+
+	var skips []SkippedRange
+	fr = NewFrameReader(iotest.HalfReader(bytes.NewReader(corrupt)), WithResync(func(s SkippedRange) {
+		skips = append(skips, s)
+	}))
+	var seqs []uint8
+	for {
+		var rec struct {
+			Seq uint8 `poculum:"seq"`
+		}
+		err := fr.Decode(&rec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		seqs = append(seqs, rec.Seq)
+	}
+	if len(seqs) != 4 || seqs[1] != 2 {
+		t.Fatalf("got %v", seqs)
+	}
+	if len(skips) != 1 || skips[0].Offset != int64(frameSize) || skips[0].Length != int64(frameSize) {
+		t.Fatalf("skips = %+v (frame size %d)", skips, frameSize)
+	}
+}
+
+func TestFrameTruncated(t *testing.T) {
+	var stream bytes.Buffer
+	NewFrameWriter(&stream).Write("hello")
+	truncated := stream.Bytes()[:stream.Len()-2]
+
+	_, err := NewFrameReader(bytes.NewReader(truncated)).ReadRaw()
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	var skips []SkippedRange
+	_, err = NewFrameReader(bytes.NewReader(truncated), WithResync(func(s SkippedRange) { skips = append(skips, s) })).ReadRaw()
+	if err != io.EOF || len(skips) != 1 || skips[0].Length != int64(len(truncated)) {
+		t.Fatalf("err = %v, skips = %+v", err, skips)
+	}
+}
+
+// readSizeRecorder 记录单次 Read 请求的最大字节数
+type readSizeRecorder struct {
+	r   io.Reader
+	max int
+}
+
+func (rr *readSizeRecorder) Read(p []byte) (int, error) {
+	rr.max = max(rr.max, len(p))
+	return rr.r.Read(p)
+}
+
+func TestFrameCorruptLength(t *testing.T) {
+	var stream bytes.Buffer
+	fw := NewFrameWriter(&stream)
+	for _, s := range []string{"a", "b"} {
+		if err := fw.Write(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := stream.Bytes()
+	data[2] ^= 0xFF // 第一帧的长度变为接近 4GB
+
+	rr := &readSizeRecorder{r: bytes.NewReader(data)}
+	var skips []SkippedRange
+	fr := NewFrameReader(rr, WithResync(func(s SkippedRange) { skips = append(skips, s) }))
+	var got string
+	if err := fr.Decode(&got); err != nil || got != "b" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	if len(skips) != 1 || !IsKind(skips[0].Err, DataTooLarge) {
+		t.Fatalf("skips = %+v", skips)
+	}
+	if rr.max > frameReadSize {
+		t.Fatalf("read buffer of %d bytes", rr.max)
+	}
+
+	// 长度在上限之内但超过实际数据时，只分配实际读到的数据
+	data[2] ^= 0xFF
+	data[3] ^= 0x01
+	rr = &readSizeRecorder{r: bytes.NewReader(data)}
+	if _, err := NewFrameReader(rr).ReadRaw(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if rr.max > frameReadSize {
+		t.Fatalf("read buffer of %d bytes", rr.max)
+	}
+
+	data[3] ^= 0x01
+	if _, err := NewFrameReader(bytes.NewReader(data), WithMaxFrameSize(1)).ReadRaw(); !IsKind(err, DataTooLarge) {
+		t.Fatalf("expected DataTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// DecodeSlice 使用默认实例把 list 解码为 []T，元素按照 Unmarshal 的规则转换：
+// 整数可以解码为任意宽度的整数或者浮点数，超出 T 的范围时返回错误
+//
+//	ids, err := poculum.DecodeSlice[int64](data)
+func DecodeSlice[T any](data []byte) ([]T, error) {
+	return DecodeSliceWith[T](Default(), data)
+}
+
+// DecodeSliceWith 使用 poc 把 list 解码为 []T。普通的 list 逐个元素解码，
+// 不构造中间的 []any，AfterDecode 钩子只对元素调用；Set、稀疏 list 等扩展类型与 Unmarshal 的处理相同
+func DecodeSliceWith[T any](poc *Poculum, data []byte) ([]T, error) {
+	if poc.maxInputSize > 0 && len(data) > poc.maxInputSize {
+		return nil, newError(DataTooLarge, fmt.Sprintf("Input too large: %d bytes (max %d)", len(data), poc.maxInputSize))
+	}
+
+	reader := bytes.NewReader(data)
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isList() {
+		var out []T
+		return out, poc.Unmarshal(data, &out)
+	}
+	if err := poc.checkItems(reader, h.length, "Array"); err != nil {
+		return nil, err
+	}
+
+	out := make([]T, h.length)
+	for i := range out {
+		item, err := poc.decodeValue(reader, 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := poc.assign(reflect.ValueOf(&out[i]).Elem(), poc.afterDecode(item), strconv.Itoa(i)); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// DecodeMap 使用默认实例把 map 解码为 map[string]V，值按照 Unmarshal 的规则转换
+//
+//	users, err := poculum.DecodeMap[User](data)
+func DecodeMap[V any](data []byte) (map[string]V, error) {
+	return DecodeMapWith[V](Default(), data)
+}
+
+// DecodeMapWith 使用 poc 把 map 解码为 map[string]V。逐个键值对解码，不构造中间的 map[string]any，
+// AfterDecode 钩子只对值调用；WithKeyMapper 对键生效
+func DecodeMapWith[V any](poc *Poculum, data []byte) (map[string]V, error) {
+	if len(data) == 0 || data[0] == typeNil {
+		return nil, nil
+	}
+	return decodeTypedMap(poc, data, func(reader *bytes.Reader, path string) (V, error) {
+		var v V
+		item, err := poc.decodeValue(reader, 1)
+		if err != nil {
+			return v, err
+		}
+		err = poc.assign(reflect.ValueOf(&v).Elem(), poc.afterDecode(item), path)
+		return v, err
+	})
+}
+
+// DecodeKeyedMap 使用默认实例把 map 解码为 map[K]V，K 可以是字符串或者整数类型，
+// 整数键的 map 扩展类型解码为对应的整数键，超出 K 的范围时返回错误
+//
+//	scores, err := poculum.DecodeKeyedMap[uint32, float64](data)
+func DecodeKeyedMap[K comparable, V any](data []byte) (map[K]V, error) {
+	return DecodeKeyedMapWith[K, V](Default(), data)
+}
+
+// DecodeKeyedMapWith 使用 poc 把 map 解码为 map[K]V
+func DecodeKeyedMapWith[K comparable, V any](poc *Poculum, data []byte) (map[K]V, error) {
+	var m map[K]V
+	return m, poc.Unmarshal(data, &m)
+}
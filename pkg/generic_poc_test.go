@@ -0,0 +1,55 @@
+package poculum
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeSlice(t *testing.T) {
+	data, _ := DumpPoculum([]any{uint8(1), int16(-300), uint32(70000), 2.5})
+	floats, err := DecodeSlice[float64](data)
+	if err != nil || !reflect.DeepEqual(floats, []float64{1, -300, 70000, 2.5}) {
+		t.Fatalf("got %v, %v", floats, err)
+	}
+	if _, err := DecodeSlice[int32](data); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch for float item, got %v", err)
+	}
+
+	data, _ = DumpPoculum([]any{uint8(1), int16(-300), uint32(70000)})
+	ints, err := DecodeSlice[int32](data)
+	if err != nil || !reflect.DeepEqual(ints, []int32{1, -300, 70000}) {
+		t.Fatalf("got %v, %v", ints, err)
+	}
+	if _, err := DecodeSlice[int8](data); !IsKind(err, ValueOutOfRange) {
+		t.Fatalf("expected ValueOutOfRange, got %v", err)
+	}
+
+	data, _ = DumpPoculum(Set{"b", "a"})
+	strs, err := DecodeSlice[string](data)
+	if err != nil || len(strs) != 2 {
+		t.Fatalf("got %v, %v", strs, err)
+	}
+}
+
+func TestDecodeMap(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	data, _ := DumpPoculum(map[string]point{"a": {1, 2}, "b": {3, 4}})
+	points, err := DecodeMap[point](data)
+	if err != nil || !reflect.DeepEqual(points, map[string]point{"a": {1, 2}, "b": {3, 4}}) {
+		t.Fatalf("got %v, %v", points, err)
+	}
+	if _, err := DecodeMap[int](data); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+
+	data, _ = DumpPoculum(map[uint16]string{1: "x", 500: "y"})
+	keyed, err := DecodeKeyedMap[uint16, string](data)
+	if err != nil || !reflect.DeepEqual(keyed, map[uint16]string{1: "x", 500: "y"}) {
+		t.Fatalf("got %v, %v", keyed, err)
+	}
+	if _, err := DecodeKeyedMap[int8, string](data); !IsKind(err, ValueOutOfRange) {
+		t.Fatalf("expected ValueOutOfRange, got %v", err)
+	}
+}
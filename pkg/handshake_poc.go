@@ -0,0 +1,116 @@
+package poculum
+
+import (
+	"fmt"
+	"slices"
+)
+
+// 连接握手
+/*
+	使用帧的长连接在发送数据之前交换一帧 Hello，双方得到共同支持的编码配置，不同版本的 poculum 可以互相通信：
+
+	{"hello": {"min_revision": 1, "max_revision": 1, "features": ["packed-arrays", "sparse-lists"]}}
+
+协商的格式版本是双方版本范围的交集中最大的一个，没有交集时返回 IncompatiblePeer 错误；
+特性是双方特性的交集，不认识的特性被忽略，以后可以增加新的特性。
+Profile.Options 返回的选项关闭对方不支持的扩展类型：
+
+	fr := poculum.NewFrameReader(conn)
+	profile, err := poculum.Handshake(poculum.NewFrameWriter(conn), fr, poculum.DefaultHello())
+	fw := poculum.NewPoculum(append(opts, profile.Options()...)...).NewFrameWriter(conn)
+
+之后继续使用同一个 FrameReader 读取，握手时读取的数据可能还在它的缓冲区中
+*/
+
+// FormatRevision 本库实现的编码格式版本
+const FormatRevision = 1
+
+// 握手时交换的特性
+const (
+	FeaturePackedArrays = "packed-arrays" // 位压缩的 []bool 与张量
+	FeatureSparseLists  = "sparse-lists"  // 稀疏 list
+)
+
+// Hello 握手时发送的本端信息
+type Hello struct {
+	MinRevision uint16   `poculum:"min_revision"`
+	MaxRevision uint16   `poculum:"max_revision"`
+	Features    []string `poculum:"features"`
+}
+
+// DefaultHello 返回本库支持的格式版本与全部特性
+func DefaultHello() Hello {
+	return Hello{
+		MinRevision: FormatRevision,
+		MaxRevision: FormatRevision,
+		Features:    []string{FeaturePackedArrays, FeatureSparseLists},
+	}
+}
+
+// Profile 握手协商得到的编码配置
+type Profile struct {
+	Revision uint16   // 双方都支持的最大格式版本
+	Features []string // 双方都支持的特性，按照本端 Hello 中的顺序排列
+	Peer     Hello    // 对方发送的 Hello
+}
+
+// Has 判断双方是否都支持 feature
+func (p Profile) Has(feature string) bool {
+	return slices.Contains(p.Features, feature)
+}
+
+// Options 返回关闭对方不支持的特性的选项，放在其它选项之后使用。
+// 没有 FeaturePackedArrays 时 []bool 编码为普通的 list，编码张量返回错误；
+// 没有 FeatureSparseLists 时 WithSparseLists 与 WithOptimize 都不使用稀疏编码
+func (p Profile) Options() []ConfigOption {
+	var opts []ConfigOption
+	if !p.Has(FeaturePackedArrays) {
+		opts = append(opts, func(poc *Poculum) { poc.noPacked = true })
+	}
+	if !p.Has(FeatureSparseLists) {
+		opts = append(opts, func(poc *Poculum) { poc.noSparse = true })
+	}
+	return opts
+}
+
+// Negotiate 根据双方的 Hello 计算编码配置
+func Negotiate(local, peer Hello) (Profile, error) {
+	low, high := max(local.MinRevision, peer.MinRevision), min(local.MaxRevision, peer.MaxRevision)
+	if low > high || high == 0 {
+		return Profile{}, newError(IncompatiblePeer, fmt.Sprintf("No common format revision: local %d-%d, peer %d-%d",
+			local.MinRevision, local.MaxRevision, peer.MinRevision, peer.MaxRevision))
+	}
+	p := Profile{Revision: high, Peer: peer}
+	for _, f := range local.Features {
+		if slices.Contains(peer.Features, f) && !slices.Contains(p.Features, f) {
+			p.Features = append(p.Features, f)
+		}
+	}
+	return p, nil
+}
+
+// helloFrame 握手帧的内容
+type helloFrame struct {
+	Hello *Hello `poculum:"hello"`
+}
+
+// Handshake 发送本端的 Hello 并读取对方的 Hello，返回协商的编码配置。
+// 发送与读取同时进行，双方可以同时调用 Handshake。对方的第一帧不是 Hello 时返回 IncompatiblePeer 错误
+func Handshake(fw *FrameWriter, fr *FrameReader, local Hello) (Profile, error) {
+	sent := make(chan error, 1)
+	go func() {
+		sent <- fw.Write(helloFrame{Hello: &local})
+	}()
+
+	var frame helloFrame
+	if err := fr.Decode(&frame); err != nil {
+		return Profile{}, err
+	}
+	if err := <-sent; err != nil {
+		return Profile{}, err
+	}
+	if frame.Hello == nil {
+		return Profile{}, newError(IncompatiblePeer, "Peer did not send a hello frame")
+	}
+	return Negotiate(local, *frame.Hello)
+}
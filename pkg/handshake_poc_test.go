@@ -0,0 +1,53 @@
+package poculum
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestHandshake(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	old := Hello{MinRevision: 1, MaxRevision: 1, Features: []string{FeatureSparseLists, "string-table"}}
+	type result struct {
+		profile Profile
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		p, err := Handshake(NewFrameWriter(b), NewFrameReader(b), old)
+		done <- result{p, err}
+	}()
+	local, err := Handshake(NewFrameWriter(a), NewFrameReader(a), DefaultHello())
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := <-done
+	if remote.err != nil {
+		t.Fatal(remote.err)
+	}
+	for _, p := range []Profile{local, remote.profile} {
+		if p.Revision != 1 || !reflect.DeepEqual(p.Features, []string{FeatureSparseLists}) {
+			t.Fatalf("profile: %+v", p)
+		}
+	}
+
+	// 对方不支持位压缩时 []bool 编码为普通的 list，张量无法编码
+	poc := NewPoculum(local.Options()...)
+	data, err := poc.Dump([]bool{true, false})
+	if err != nil || !bytes.Equal(data, []byte{0x52, typeTrue, typeFalse}) {
+		t.Fatalf("bools = %x, %v", data, err)
+	}
+	tensor, _ := NewTensorFloat32([]int{1}, []float32{1})
+	if _, err := poc.Dump(tensor); !IsKind(err, UnsupportedType) {
+		t.Fatalf("expected UnsupportedType, got %v", err)
+	}
+
+	if _, err := Negotiate(DefaultHello(), Hello{MinRevision: 2, MaxRevision: 3}); !IsKind(err, IncompatiblePeer) {
+		t.Fatalf("expected IncompatiblePeer, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 十六进制与问题复现
+/*
+	其它语言的实现报告的问题通常附带十六进制的数据，FromHex 接受常见的写法：
+
+	0123abcd                  Python 的 bytes.hex()
+	01 23 ab cd、01:23:ab:cd   带有空白或者分隔符
+	0x01, 0x23                C 数组
+	\x01\x23                  转义序列
+	b'\x01#\xab\xcd'          Python 的 bytes 字面量，可以包含可打印字符
+
+Repro 解析十六进制文本并解码，收集定位问题需要的信息，可以直接写入测试：
+
+	func TestIssue42(t *testing.T) {
+		r := poculum.Repro(`b'q2id\x01\x01'`) // {"id": 1}
+		if r.Err != nil {
+			t.Fatal(r)
+		}
+	}
+*/
+
+// ToHex 返回 data 的小写十六进制文本，与 Python 的 bytes.hex() 相同
+func ToHex(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+// FromHex 解析十六进制文本，忽略空白、逗号、冒号、连字符以及 0x、\x 前缀，也接受 Python 的 bytes 字面量
+func FromHex(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 3 && (s[0] == 'b' || s[0] == 'B') && (s[1] == '\'' || s[1] == '"') && s[len(s)-1] == s[1] {
+		return fromBytesLiteral(s[2 : len(s)-1])
+	}
+
+	s = strings.NewReplacer("0x", "", "0X", "", `\x`, "").Replace(s)
+	s = strings.Map(func(r rune) rune {
+		if r == ',' || r == ':' || r == '-' || r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, wrapError(InvalidText, "Invalid hex", err)
+	}
+	return data, nil
+}
+
+// fromBytesLiteral 解析 Python bytes 字面量引号中的内容
+func fromBytesLiteral(s string) ([]byte, error) {
+	data := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			data = append(data, c)
+			continue
+		}
+		if i+1 >= len(s) {
+			return nil, newError(InvalidText, "Invalid escape at end of bytes literal")
+		}
+		i++
+		switch s[i] {
+		case 'x':
+			if i+3 > len(s) {
+				return nil, newError(InvalidText, fmt.Sprintf("Invalid escape at offset %d", i-1))
+			}
+			n, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return nil, newError(InvalidText, fmt.Sprintf("Invalid escape at offset %d", i-1))
+			}
+			data = append(data, byte(n))
+			i += 2
+		case 'n':
+			data = append(data, '\n')
+		case 'r':
+			data = append(data, '\r')
+		case 't':
+			data = append(data, '\t')
+		case '0':
+			data = append(data, 0)
+		case '\\', '\'', '"':
+			data = append(data, s[i])
+		default:
+			return nil, newError(InvalidText, fmt.Sprintf("Unsupported escape \\%c at offset %d", s[i], i-1))
+		}
+	}
+	return data, nil
+}
+
+// Reproduction 由 Repro 生成的诊断信息
+type Reproduction struct {
+	Data     []byte    // 解析得到的数据，十六进制文本无法解析时为 nil
+	Value    any       // 解码得到的第一个值
+	Err      error     // 解析或者解码的错误，解码成功但是有剩余数据时同样返回错误
+	Segments []Segment // 数据的结构，见 Explain
+}
+
+// Repro 使用默认实例解析十六进制文本并解码
+func Repro(hexText string) *Reproduction {
+	return Default().Repro(hexText)
+}
+
+// Repro 解析十六进制文本并解码，Err 为 nil 表示数据恰好是一个合法的值
+func (poc *Poculum) Repro(hexText string) *Reproduction {
+	r := &Reproduction{}
+	if r.Data, r.Err = FromHex(hexText); r.Err != nil {
+		return r
+	}
+	r.Segments = poc.Explain(r.Data)
+
+	reader := bytes.NewReader(r.Data)
+	if r.Value, r.Err = poc.decodeValue(reader, 0); r.Err == nil && reader.Len() > 0 {
+		r.Err = newError(InvalidRaw, fmt.Sprintf("%d trailing bytes after the value at offset %d", reader.Len(), len(r.Data)-reader.Len()))
+	}
+	return r
+}
+
+// String 返回诊断报告：数据的十六进制转储、结构、解码结果或者错误
+func (r *Reproduction) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "input: %d bytes\n", len(r.Data))
+	if len(r.Data) > 0 {
+		b.WriteString(hex.Dump(r.Data))
+	}
+	if len(r.Segments) > 0 {
+		b.WriteString("structure:\n")
+		for _, s := range r.Segments {
+			typ := s.Type
+			if s.Kind != SegmentValue {
+				typ = s.Kind
+			}
+			fmt.Fprintf(&b, "%08x %6d  %-20s %-24s %s\n", s.Offset, s.Length, strings.Repeat("  ", s.Depth)+typ, displayPath(s.Path), s.Preview)
+		}
+	}
+	if r.Err != nil {
+		fmt.Fprintf(&b, "error: %v\n", r.Err)
+	} else {
+		fmt.Fprintf(&b, "value: %#v\n", r.Value)
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package poculum
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromHex(t *testing.T) {
+	want := []byte{0x71, 0x32, 'i', 'd', 0x01, 0x01}
+	for _, s := range []string{
+		"71326964 0101",
+		"71:32:69:64:01:01",
+		"0x71, 0x32, 0x69, 0x64, 0x01, 0x01",
+		`\x71\x32\x69\x64\x01\x01`,
+		`b'q2id\x01\x01'`,
+	} {
+		data, err := FromHex(s)
+		if err != nil || !bytes.Equal(data, want) {
+			t.Fatalf("FromHex(%q) = %x, %v", s, data, err)
+		}
+	}
+	if ToHex(want) != "713269640101" {
+		t.Fatalf("ToHex = %s", ToHex(want))
+	}
+	for _, s := range []string{"7", "zz", `b'\x7'`, `b'\q'`} {
+		if _, err := FromHex(s); !IsKind(err, InvalidText) {
+			t.Fatalf("FromHex(%q): expected InvalidText, got %v", s, err)
+		}
+	}
+
+	r := Repro("713269640101")
+	if r.Err != nil || !reflect.DeepEqual(r.Value, map[string]any{"id": uint8(1)}) || len(r.Segments) != 3 {
+		t.Fatalf("repro: %v", r)
+	}
+
+	// 截断的数据：错误与解析失败的位置都出现在报告中
+	r = Repro("7132696401")
+	if !IsKind(r.Err, InsufficientData) || r.Segments[len(r.Segments)-1].Kind != SegmentInvalid {
+		t.Fatalf("repro: %v", r)
+	}
+	if report := r.String(); !strings.Contains(report, "input: 5 bytes") || !strings.Contains(report, "error: InsufficientData") {
+		t.Fatalf("report:\n%s", report)
+	}
+
+	if r = Repro("010101"); !IsKind(r.Err, InvalidRaw) {
+		t.Fatalf("expected trailing data error, got %v", r.Err)
+	}
+}
@@ -0,0 +1,75 @@
+package poculum
+
+import "fmt"
+
+// 编解码钩子
+/*
+	使用 WithHooks 设置的钩子可以集中处理单位换算、字段脱敏等横切关注点：
+
+	poc := poculum.NewPoculum(poculum.WithHooks(poculum.Hooks{
+		BeforeEncode: func(v any) any {
+			if m, ok := v.(map[string]any); ok {
+				delete(m, "password")
+			}
+			return v
+		},
+	}))
+
+钩子对顶层值以及每个 list 元素、map 的值调用（不包括 map 的键与扩展类型内部的组成部分），
+返回值代替原来的值。BeforeEncode 先处理外层的值再处理其中的元素；
+AfterDecode 先处理元素再处理外层的值，所以外层的钩子看到的是已经处理过的元素。
+struct 在 BeforeEncode 中以 struct 本身出现，它的字段值随后逐个出现
+*/
+
+// Hooks 编解码时调用的钩子，为 nil 的钩子不调用
+type Hooks struct {
+	BeforeEncode func(v any) any // 编码每个值之前调用
+	AfterDecode  func(v any) any // 解码每个值之后调用
+}
+
+// WithHooks 设置编解码钩子
+func WithHooks(hooks Hooks) ConfigOption {
+	return func(poc *Poculum) {
+		poc.hooks = hooks
+	}
+}
+
+// beforeEncode 调用 BeforeEncode 钩子
+func (poc *Poculum) beforeEncode(v any) any {
+	if poc.hooks.BeforeEncode == nil {
+		return v
+	}
+	return poc.hooks.BeforeEncode(v)
+}
+
+// afterDecode 调用 AfterDecode 钩子
+func (poc *Poculum) afterDecode(v any) any {
+	if poc.hooks.AfterDecode == nil {
+		return v
+	}
+	return poc.hooks.AfterDecode(v)
+}
+
+// KeyMapper 转换解码得到的 map 的键，例如统一为小写或者去掉前缀
+type KeyMapper func(key string) string
+
+// WithKeyMapper 解码时对每个 map 的键调用 fn，使用返回值作为键，不需要在解码后再遍历整个值。
+// 只作用于 map 的键，不包括整数键的 map 与 struct 的字段名；
+// 转换后出现相同的键时返回错误。WithFields 的路径按照转换后的键匹配
+func WithKeyMapper(fn KeyMapper) ConfigOption {
+	return func(poc *Poculum) {
+		poc.keyMapper = fn
+	}
+}
+
+// mapKey 调用 KeyMapper，并检查转换后的键是否与 obj 中已有的键重复
+func (poc *Poculum) mapKey(obj map[string]any, key string) (string, error) {
+	if poc.keyMapper == nil {
+		return key, nil
+	}
+	mapped := poc.keyMapper(key)
+	if _, dup := obj[mapped]; dup {
+		return "", newError(DuplicateItem, fmt.Sprintf("Duplicate key %q after mapping %q", mapped, key))
+	}
+	return mapped, nil
+}
@@ -0,0 +1,169 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// 整数键的 map
+/*
+	键为整数类型的 map（例如 map[int64]any、map[uint32]string）编码为带有整数键 map 扩展标识的 list：
+
+	[键1, 值1, 键2, 值2, ...]
+
+键按照从小到大的顺序写入，使用能容纳它的最小宽度编码。解码得到 map[int64]any，
+如果存在超出 int64 范围的键则得到 map[uint64]any。Unmarshal 可以写入任意整数键的 map，
+键超出目标类型范围时返回 ValueOutOfRange 错误
+*/
+
+// isIntKind 判断是否是整数类型
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// compactKey 把整数键转换为能容纳它的最小宽度的整数
+func compactKey(key reflect.Value) any {
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := key.Int()
+		switch {
+		case n >= 0 && n <= math.MaxUint32:
+			return compactUint32(uint64(n))
+		case n >= math.MinInt8 && n < 0:
+			return int8(n)
+		case n >= math.MinInt16 && n < 0:
+			return int16(n)
+		case n >= math.MinInt32 && n < 0:
+			return int32(n)
+		default:
+			return n
+		}
+	default:
+		n := key.Uint()
+		if n <= math.MaxUint32 {
+			return compactUint32(n)
+		}
+		return n
+	}
+}
+
+// compactUint32 与 compactUint 相同，但是参数为 uint64，不经过 32 位平台上可能溢出的 int。
+// n 不能超过 math.MaxUint32
+func compactUint32(n uint64) any {
+	switch {
+	case n <= math.MaxUint8:
+		return uint8(n)
+	case n <= math.MaxUint16:
+		return uint16(n)
+	default:
+		return uint32(n)
+	}
+}
+
+// encodeIntMap 编码整数键的 map
+func (poc *Poculum) encodeIntMap(rv reflect.Value, buf *bytes.Buffer, depth int) error {
+	length := rv.Len()
+	if length > poc.maxContainerItems {
+		return newError(DataTooLarge, fmt.Sprintf("Object too large: %d items (max %d)", length, poc.maxContainerItems))
+	}
+
+	keys := rv.MapKeys()
+	if rv.Type().Key().Kind() >= reflect.Uint {
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Uint() < keys[j].Uint() })
+	} else {
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Int() < keys[j].Int() })
+	}
+
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extIntMap)
+	writeListHeader(buf, length*2)
+	for _, key := range keys {
+		if err := poc.encodeValue(compactKey(key), buf, depth+2); err != nil {
+			return err
+		}
+		if err := poc.encodeValue(rv.MapIndex(key).Interface(), buf, depth+2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeIntMap 解码整数键的 map
+func (poc *Poculum) decodeIntMap(reader *bytes.Reader, depth int) (any, error) {
+	items, err := expectList(reader, "IntMap")
+	if err != nil {
+		return nil, err
+	}
+	if items%2 != 0 {
+		return nil, newError(InvalidIntMap, fmt.Sprintf("IntMap must contain an even number of items, got %d", items))
+	}
+	if err := poc.checkItems(reader, items, "IntMap"); err != nil {
+		return nil, err
+	}
+
+	signed := make(map[int64]any, min(items/2, maxSizeHint))
+	var unsigned map[uint64]any
+	for i := 0; i < items; i += 2 {
+		keyValue, err := poc.decodeValue(reader, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := poc.decodeValue(reader, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		if key, ok := toInt64(keyValue); ok && unsigned == nil {
+			signed[key] = value
+			continue
+		}
+		key, ok := toUint64(keyValue)
+		if !ok {
+			return nil, newError(InvalidIntMap, fmt.Sprintf("IntMap key must be an integer, got %T", keyValue))
+		}
+		if unsigned == nil {
+			// 出现超出 int64 的键，此前的键都是非负数
+			unsigned = make(map[uint64]any, min(items/2, maxSizeHint))
+			for k, v := range signed {
+				if k < 0 {
+					return nil, newError(InvalidIntMap, "IntMap mixes negative keys with keys above int64")
+				}
+				unsigned[uint64(k)] = v
+			}
+		}
+		unsigned[key] = value
+	}
+
+	if unsigned != nil {
+		return unsigned, nil
+	}
+	return signed, nil
+}
+
+// assignIntMap 把解码得到的整数键 map 写入整数键的 map 中
+func (poc *Poculum) assignIntMap(dst reflect.Value, src reflect.Value, path string) error {
+	m := reflect.MakeMapWithSize(dst.Type(), src.Len())
+	iter := src.MapRange()
+	for iter.Next() {
+		keyPath := joinPath(path, fmt.Sprint(iter.Key().Interface()))
+		key := reflect.New(dst.Type().Key()).Elem()
+		if err := poc.assign(key, iter.Key().Interface(), keyPath); err != nil {
+			return err
+		}
+		elem := reflect.New(dst.Type().Elem()).Elem()
+		if err := poc.assign(elem, iter.Value().Interface(), keyPath); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, elem)
+	}
+	dst.Set(m)
+	return nil
+}
@@ -0,0 +1,84 @@
+package poculum
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestIntKeyedMap(t *testing.T) {
+	in := map[int64]any{-5: "neg", 1: "one", 1 << 40: "big"}
+	data, err := DumpPoculum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := LoadPoculum(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := value.(map[int64]any)
+	if !ok || len(got) != 3 || got[-5] != "neg" || got[1<<40] != "big" {
+		t.Fatalf("got %#v", value)
+	}
+
+	var ids map[uint16]string
+	if err := Unmarshal(mustDumpIntMap(t, map[uint32]string{7: "a", 9: "b"}), &ids); err != nil {
+		t.Fatal(err)
+	}
+	if ids[7] != "a" || ids[9] != "b" {
+		t.Fatalf("got %v", ids)
+	}
+	if err := Unmarshal(mustDumpIntMap(t, map[uint32]string{70000: "a"}), &ids); err == nil {
+		t.Fatal("expected overflow error for key 70000")
+	}
+
+	huge, err := DumpPoculum(map[uint64]any{math.MaxUint64: true, 3: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err = LoadPoculum(huge); err != nil {
+		t.Fatal(err)
+	}
+	if u, ok := value.(map[uint64]any); !ok || u[math.MaxUint64] != true || u[3] != false {
+		t.Fatalf("got %#v", value)
+	}
+}
+
+func mustDumpIntMap(t *testing.T, m map[uint32]string) []byte {
+	t.Helper()
+	data, err := DumpPoculum(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestIntKeyedMapForgedLength(t *testing.T) {
+	data := []byte{typeExt, extIntMap, typeList32, 0x0F, 0xFF, 0xFF, 0xFE}
+	if _, err := NewPoculumSecure().Load(data); !IsKind(err, DataTooLarge) {
+		t.Fatalf("expected DataTooLarge, got %v", err)
+	}
+	if _, err := NewPoculum().Load(data); !IsKind(err, InsufficientData) {
+		t.Fatalf("expected InsufficientData, got %v", err)
+	}
+}
+
+func TestCompactKey(t *testing.T) {
+	for _, c := range []struct {
+		key  any
+		want any
+	}{
+		{int64(200), uint8(200)},
+		{int64(math.MaxInt32 + 1), uint32(math.MaxInt32 + 1)},
+		{int64(math.MaxUint32), uint32(math.MaxUint32)},
+		{int64(math.MaxUint32 + 1), int64(math.MaxUint32 + 1)},
+		{int64(-1), int8(-1)},
+		{uint64(math.MaxUint32), uint32(math.MaxUint32)},
+		{uint(70000), uint32(70000)},
+		{uint64(math.MaxUint32 + 1), uint64(math.MaxUint32 + 1)},
+	} {
+		if got := compactKey(reflect.ValueOf(c.key)); got != c.want {
+			t.Errorf("compactKey(%T %v) = %T %v, want %T %v", c.key, c.key, got, got, c.want, c.want)
+		}
+	}
+}
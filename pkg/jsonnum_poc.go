@@ -0,0 +1,30 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// encodeJSONNumber 编码 json.Number
+//
+// 字面量是整数时按照整数编码，超出 int64 的非负整数按照 uint64 编码，
+// 其它情况按照 float64 编码，这样使用 UseNumber 解码的 JSON 数据可以不损失精度地转换为 poculum
+func (poc *Poculum) encodeJSONNumber(n json.Number, buf *bytes.Buffer, depth int) error {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		if i >= math.MinInt32 && i <= math.MaxUint32 {
+			return poc.encodeValue(int(i), buf, depth)
+		}
+		return poc.encodeValue(i, buf, depth)
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return poc.encodeValue(u, buf, depth)
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return newError(UnsupportedType, fmt.Sprintf("Invalid json.Number: %q", n))
+	}
+	return poc.encodeValue(f, buf, depth)
+}
@@ -0,0 +1,35 @@
+package poculum
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestJSONNumber(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`{"id": 9007199254740993, "big": 18446744073709551615, "ratio": 0.25, "neg": -7}`))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := DumpPoculum(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := LoadPoculum(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := value.(map[string]any)
+	if got["id"] != int64(9007199254740993) || got["big"] != uint64(math.MaxUint64) ||
+		got["ratio"] != 0.25 || got["neg"] != int32(-7) {
+		t.Fatalf("got %#v", got)
+	}
+
+	if _, err := DumpPoculum(json.Number("abc")); err == nil {
+		t.Fatal("expected error for invalid number")
+	}
+}
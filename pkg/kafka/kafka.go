@@ -0,0 +1,174 @@
+// Package kafka 提供 Kafka 客户端使用的 poculum 序列化适配器，不依赖任何 Kafka 客户端库
+/*
+	Serializer 与 Deserializer 的方法签名与 confluent-kafka-go 的 serde.Serializer、serde.Deserializer
+中的 Serialize、Deserialize、DeserializeInto、Close 相同，可以直接替换使用 Avro/Protobuf 序列化器的代码：
+
+	ser := kafka.NewSerializer(kafka.WithRegistry(schema.NewSerializer(registry, nil)))
+	value, err := ser.Serialize(topic, order)
+	producer.Produce(&ckafka.Message{TopicPartition: ..., Value: value}, nil)
+
+franz-go 使用函数而不是接口，Funcs 返回可以直接放入 sr.Serde 或者手动调用的编解码函数：
+
+	encode, decode := kafka.Funcs(ser, de, "orders")
+	record := &kgo.Record{Topic: "orders", Value: must(encode(order))}
+
+消息的格式由选项决定：
+	默认                poculum 编码的值
+	WithRegistry        0x00 | schema 编号 | poculum 编码的值（见 schema.Serializer），subject 为 "<topic>-value"
+	WithEnvelope        poculum.Envelope，Topic 为 topic；同时使用 WithRegistry 时 schema 编号写入 Envelope.SchemaID，
+	                    body 中不再带有前缀
+*/
+package kafka
+
+import (
+	"context"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+	"github.com/shinyes/poculum-go/pkg/schema"
+)
+
+// config Serializer 与 Deserializer 共用的配置
+type config struct {
+	poc      *poculum.Poculum
+	registry *schema.Serializer
+	envelope bool
+	subject  func(topic string) string
+}
+
+// Option 配置 Serializer 与 Deserializer
+type Option func(*config)
+
+// WithPoculum 使用 poc 编解码，默认使用 poculum.Default()
+func WithPoculum(poc *poculum.Poculum) Option {
+	return func(c *config) {
+		c.poc = poc
+	}
+}
+
+// WithRegistry 使用注册中心中的 schema 校验消息，并写入 schema 编号
+func WithRegistry(s *schema.Serializer) Option {
+	return func(c *config) {
+		c.registry = s
+	}
+}
+
+// WithEnvelope 把消息包装在 poculum.Envelope 中
+func WithEnvelope() Option {
+	return func(c *config) {
+		c.envelope = true
+	}
+}
+
+// WithSubjectName 设置 topic 对应的 subject，默认为 "<topic>-value"
+func WithSubjectName(fn func(topic string) string) Option {
+	return func(c *config) {
+		c.subject = fn
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{
+		poc:     poculum.Default(),
+		subject: func(topic string) string { return topic + "-value" },
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Serializer 把值序列化为 Kafka 消息
+type Serializer struct {
+	config
+}
+
+// NewSerializer 创建 Serializer
+func NewSerializer(opts ...Option) *Serializer {
+	return &Serializer{newConfig(opts)}
+}
+
+// Serialize 序列化发送到 topic 的消息
+func (s *Serializer) Serialize(topic string, msg any) ([]byte, error) {
+	var (
+		body     []byte
+		schemaID uint32
+		err      error
+	)
+	if s.registry != nil {
+		prefixed, err := s.registry.Serialize(context.Background(), s.subject(topic), msg)
+		if err != nil {
+			return nil, err
+		}
+		if !s.envelope {
+			return prefixed, nil
+		}
+		schemaID, body, err = schema.SchemaID(prefixed)
+		if err != nil {
+			return nil, err
+		}
+	} else if body, err = s.poc.Dump(msg); err != nil {
+		return nil, err
+	}
+	if !s.envelope {
+		return body, nil
+	}
+
+	env := &poculum.Envelope{Topic: topic, ContentType: poculum.ContentTypePoculum, SchemaID: schemaID, Body: body}
+	return s.poc.MarshalEnvelope(env)
+}
+
+// Close 释放资源，目前没有需要释放的资源
+func (s *Serializer) Close() error {
+	return nil
+}
+
+// Deserializer 把 Kafka 消息反序列化为值
+type Deserializer struct {
+	config
+}
+
+// NewDeserializer 创建 Deserializer，选项需要与生产者的 Serializer 一致
+func NewDeserializer(opts ...Option) *Deserializer {
+	return &Deserializer{newConfig(opts)}
+}
+
+// Deserialize 反序列化来自 topic 的消息，得到 map[string]any 等通用的值
+func (d *Deserializer) Deserialize(topic string, payload []byte) (any, error) {
+	var v any
+	if err := d.DeserializeInto(topic, payload, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// DeserializeInto 反序列化来自 topic 的消息，解码到 msg 指向的值中
+func (d *Deserializer) DeserializeInto(topic string, payload []byte, msg any) error {
+	if d.envelope {
+		env, err := d.poc.UnmarshalEnvelope(payload)
+		if err != nil {
+			return err
+		}
+		if d.registry == nil {
+			return d.poc.Unmarshal(env.Body, msg)
+		}
+		// 还原为带有 schema 编号前缀的格式，由 schema.Serializer 校验
+		payload = schema.Prefix(env.SchemaID, env.Body)
+	}
+	if d.registry != nil {
+		_, err := d.registry.Deserialize(context.Background(), payload, msg)
+		return err
+	}
+	return d.poc.Unmarshal(payload, msg)
+}
+
+// Close 释放资源，目前没有需要释放的资源
+func (d *Deserializer) Close() error {
+	return nil
+}
+
+// Funcs 返回 topic 的编解码函数，签名与 franz-go 的 sr.Serde 等使用的函数相同
+func Funcs(s *Serializer, d *Deserializer, topic string) (encode func(any) ([]byte, error), decode func([]byte, any) error) {
+	encode = func(v any) ([]byte, error) { return s.Serialize(topic, v) }
+	decode = func(b []byte, v any) error { return d.DeserializeInto(topic, b, v) }
+	return encode, decode
+}
@@ -0,0 +1,55 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+	"github.com/shinyes/poculum-go/pkg/schema"
+)
+
+type order struct {
+	ID    uint32  `poculum:"id"`
+	Total float64 `poculum:"total"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	s, err := schema.ParseString("struct Order {\n id: uint32\n total: float64\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := schema.NewMemoryRegistry()
+	if _, err := registry.Register(context.Background(), "orders-value", "Order", s); err != nil {
+		t.Fatal(err)
+	}
+
+	modes := map[string][]Option{
+		"plain":             nil,
+		"registry":          {WithRegistry(schema.NewSerializer(registry, nil))},
+		"envelope":          {WithEnvelope()},
+		"envelope+registry": {WithEnvelope(), WithRegistry(schema.NewSerializer(registry, nil))},
+	}
+	for name, opts := range modes {
+		ser, de := NewSerializer(opts...), NewDeserializer(opts...)
+		encode, decode := Funcs(ser, de, "orders")
+		payload, err := encode(order{ID: 1, Total: 9.5})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var got order
+		if err := decode(payload, &got); err != nil || got != (order{ID: 1, Total: 9.5}) {
+			t.Fatalf("%s: got %+v, %v", name, got, err)
+		}
+		if name == "envelope+registry" {
+			env, err := poculum.UnmarshalEnvelope(payload)
+			if err != nil || env.Topic != "orders" || env.SchemaID != 1 {
+				t.Fatalf("%s: envelope %+v, %v", name, env, err)
+			}
+		}
+	}
+
+	de := NewDeserializer(WithRegistry(schema.NewSerializer(registry, nil)))
+	if _, err := de.Deserialize("orders", poculum.MustDump(order{})); err == nil {
+		t.Fatal("expected error for payload without schema prefix")
+	}
+}
@@ -0,0 +1,88 @@
+package poculum
+
+import "bytes"
+
+// Kind 编码值的类别，由 TypeOf 返回
+type Kind uint8
+
+const (
+	Invalid   Kind = iota // 数据不合法
+	Nil                   // nil
+	Bool                  // true 或者 false
+	Int                   // 任意宽度的有符号或者无符号整数
+	Float                 // float32 或者 float64
+	String                // 字符串
+	Bytes                 // 字节数据
+	List                  // list
+	Map                   // map
+	Extension             // 扩展类型，例如 Set、时间戳、张量，可以使用 ExtTagOf 读取扩展标识
+)
+
+var kindNames = [...]string{"invalid", "nil", "bool", "int", "float", "string", "bytes", "list", "map", "ext"}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "invalid"
+}
+
+// TypeOf 返回编码数据中第一个值的类别，只读取类型字节，不检查值的其余部分是否合法，
+// 可以在解码之前根据负载的类型选择处理方式
+func TypeOf(data []byte) (Kind, error) {
+	if len(data) == 0 {
+		return Invalid, newError(InsufficientData, "No type byte")
+	}
+	switch typeName(data[0]) {
+	case "nil":
+		return Nil, nil
+	case "bool":
+		return Bool, nil
+	case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64":
+		return Int, nil
+	case "float32", "float64":
+		return Float, nil
+	case "string":
+		return String, nil
+	case "bytes":
+		return Bytes, nil
+	case "list":
+		return List, nil
+	case "map":
+		return Map, nil
+	case "ext":
+		return Extension, nil
+	}
+	_, err := readHeader(bytes.NewReader(data))
+	return Invalid, err
+}
+
+// ExtTagOf 返回扩展类型的扩展标识，第一个值不是扩展类型时返回 TypeMismatch 错误
+func ExtTagOf(data []byte) (byte, error) {
+	kind, err := TypeOf(data)
+	if err != nil {
+		return 0, err
+	}
+	if kind != Extension {
+		return 0, newError(TypeMismatch, "Value is not an extension type, got "+kind.String())
+	}
+	if len(data) < 2 {
+		return 0, newError(InsufficientData, "ext tag")
+	}
+	return data[1], nil
+}
+
+// Len 返回编码数据中第一个值的长度：list 与 map 为元素个数，字符串与字节数据为字节数。
+// 只读取头部，可以在解码之前决定路由与分批。其它类型返回 TypeMismatch 错误
+func Len(data []byte) (int, error) {
+	h, err := readHeader(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	switch kind, _ := TypeOf(data); kind {
+	case List, Map, String, Bytes:
+		return h.length, nil
+	default:
+		return 0, newError(TypeMismatch, "Len requires a list, map, string or bytes, got "+kind.String())
+	}
+}
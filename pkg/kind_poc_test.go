@@ -0,0 +1,62 @@
+package poculum
+
+import (
+	"testing"
+)
+
+func TestTypeOf(t *testing.T) {
+	for _, c := range []struct {
+		value any
+		want  Kind
+	}{
+		{nil, Nil},
+		{true, Bool},
+		{uint8(1), Int},
+		{int64(-1), Int},
+		{1.5, Float},
+		{"s", String},
+		{[]byte{1}, Bytes},
+		{[]any{1}, List},
+		{map[string]any{}, Map},
+		{Set{"a"}, Extension},
+	} {
+		if kind, err := TypeOf(MustDump(c.value)); err != nil || kind != c.want {
+			t.Fatalf("TypeOf(%v) = %s, %v", c.value, kind, err)
+		}
+	}
+	if tag, err := ExtTagOf(MustDump(Set{"a"})); err != nil || tag != extSet {
+		t.Fatalf("ExtTagOf = %d, %v", tag, err)
+	}
+	if _, err := ExtTagOf(MustDump(1)); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+	if _, err := TypeOf([]byte{0xEE}); !IsKind(err, UnknownTypeID) {
+		t.Fatalf("expected UnknownTypeID, got %v", err)
+	}
+	if _, err := TypeOf(nil); !IsKind(err, InsufficientData) {
+		t.Fatalf("expected InsufficientData, got %v", err)
+	}
+}
+
+func TestLen(t *testing.T) {
+	for _, c := range []struct {
+		value any
+		want  int
+	}{
+		{make([]any, 20), 20},
+		{make([]any, 70000), 70000},
+		{map[string]any{"a": 1, "b": 2}, 2},
+		{"héllo", 6},
+		{make([]byte, 300), 300},
+	} {
+		if n, err := Len(MustDump(c.value)); err != nil || n != c.want {
+			t.Fatalf("Len(%T) = %d, %v", c.value, n, err)
+		}
+	}
+	if _, err := Len(MustDump(1)); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+	if _, err := Len([]byte{typeList32, 0, 0}); !IsKind(err, InsufficientData) {
+		t.Fatalf("expected InsufficientData, got %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+// Package kv 是基于 poculum 记录日志的嵌入式键值存储，适合需要持久化但不需要完整数据库的工具
+/*
+	数据保存在一个只追加写入的日志文件中，每条记录为：
+
+	[4 字节记录 CRC32][4 字节长度][4 字节头部 CRC32][poculum 编码的记录]
+
+记录为 list：写入为 [键, 值]，删除为 [键]。多字节字段使用大端序，CRC32（IEEE）分别针对编码的记录
+与头部的前 8 个字节计算，损坏的长度字段不会被当作有效的长度。
+Open 时顺序扫描日志在内存中建立 键 -> 位置 的索引，剩余的字节放不下完整的头部与记录时（例如写入时进程崩溃）
+截断末尾，恰好结束在文件末尾的最后一条记录校验失败时也截断这一条。
+其它损坏时 Open 返回 ErrCorrupt，不修改文件。
+同一个键的旧记录在 Compact 之前仍然占用空间
+
+	db, err := kv.Open("cache.pkv")
+	defer db.Close()
+	err = db.Put("user:1", User{Name: "ann"})
+	var u User
+	err = db.Get("user:1", &u)
+*/
+package kv
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// recordHeaderSize 记录头部的字节数
+const recordHeaderSize = 12
+
+// ErrNotFound 键不存在
+var ErrNotFound = errors.New("kv: key not found")
+
+// ErrCorrupt 日志中间的记录损坏，不是写入时崩溃留下的不完整的末尾
+var ErrCorrupt = errors.New("kv: corrupted log")
+
+// location 记录中值的位置
+type location struct {
+	offset int64 // 值的原始字节在文件中的偏移
+	length int
+}
+
+// DB 键值存储，可以并发使用
+type DB struct {
+	mu    sync.RWMutex
+	file  *os.File
+	path  string
+	size  int64
+	index map[string]location
+	poc   *poculum.Poculum
+}
+
+// Open 打开或者创建 path 处的存储
+func Open(path string) (*DB, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	db := &DB{
+		file:  file,
+		path:  path,
+		index: make(map[string]location),
+		poc:   poculum.NewPoculum(),
+	}
+	if err := db.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// load 扫描日志建立索引，并截断末尾不完整的记录
+func (db *DB) load() error {
+	info, err := db.file.Stat()
+	if err != nil {
+		return err
+	}
+	fileSize := info.Size()
+
+	header := make([]byte, recordHeaderSize)
+	for db.size < fileSize {
+		// 头部或者数据超出文件末尾：最后一条记录没有写完
+		if db.size+recordHeaderSize > fileSize {
+			break
+		}
+		if _, err := db.file.ReadAt(header, db.size); err != nil {
+			return fmt.Errorf("kv: read record at offset %d: %w", db.size, err)
+		}
+		if crc32.ChecksumIEEE(header[:8]) != binary.BigEndian.Uint32(header[8:]) {
+			return fmt.Errorf("%w: header checksum mismatch at offset %d", ErrCorrupt, db.size)
+		}
+		sum := binary.BigEndian.Uint32(header)
+		length := int64(binary.BigEndian.Uint32(header[4:]))
+		end := db.size + recordHeaderSize + length
+		if end > fileSize {
+			// 长度经过校验，记录没有写完
+			break
+		}
+		record := make([]byte, length)
+		if _, err := db.file.ReadAt(record, db.size+recordHeaderSize); err != nil {
+			return fmt.Errorf("kv: read record at offset %d: %w", db.size, err)
+		}
+		if crc32.ChecksumIEEE(record) != sum {
+			if end == fileSize {
+				// 最后一条记录的长度已经写入但数据没有写完
+				break
+			}
+			return fmt.Errorf("%w: checksum mismatch in record at offset %d", ErrCorrupt, db.size)
+		}
+		if err := db.apply(record, db.size+recordHeaderSize); err != nil {
+			return fmt.Errorf("%w: record at offset %d: %v", ErrCorrupt, db.size, err)
+		}
+		db.size = end
+	}
+
+	if db.size < fileSize {
+		if err := db.file.Truncate(db.size); err != nil {
+			return fmt.Errorf("kv: truncate incomplete tail: %w", err)
+		}
+	}
+	return nil
+}
+
+// apply 把一条记录应用到索引上，offset 为记录在文件中的偏移
+func (db *DB) apply(record []byte, offset int64) error {
+	keyRaw, err := poculum.GetRaw(record, "0")
+	if err != nil {
+		return err
+	}
+	keyValue, err := db.poc.Load(keyRaw)
+	if err != nil {
+		return err
+	}
+	key, ok := keyValue.(string)
+	if !ok {
+		return fmt.Errorf("kv: record key must be a string, got %T", keyValue)
+	}
+
+	value, err := poculum.GetRaw(record, "1")
+	if err != nil {
+		// 只有键的记录代表删除
+		delete(db.index, key)
+		return nil
+	}
+	// 值是记录中的最后一个元素
+	start := len(record) - len(value)
+	db.index[key] = location{offset: offset + int64(start), length: len(value)}
+	return nil
+}
+
+// write 追加一条记录并更新索引，调用时需要持有写锁
+func (db *DB) write(record []byte) error {
+	if db.file == nil {
+		return os.ErrClosed
+	}
+	buf := make([]byte, recordHeaderSize, recordHeaderSize+len(record))
+	binary.BigEndian.PutUint32(buf, crc32.ChecksumIEEE(record))
+	binary.BigEndian.PutUint32(buf[4:], uint32(len(record)))
+	binary.BigEndian.PutUint32(buf[8:], crc32.ChecksumIEEE(buf[:8]))
+	buf = append(buf, record...)
+	if _, err := db.file.WriteAt(buf, db.size); err != nil {
+		return err
+	}
+
+	offset := db.size + recordHeaderSize
+	db.size += int64(len(buf))
+	return db.apply(record, offset)
+}
+
+// Put 写入键值
+func (db *DB) Put(key string, v any) error {
+	value, err := db.poc.Dump(v)
+	if err != nil {
+		return err
+	}
+	record, err := db.poc.Dump([]any{key, poculum.RawMessage(value)})
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.write(record)
+}
+
+// Delete 删除键，键不存在时不做任何事
+func (db *DB) Delete(key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.index[key]; !ok {
+		return nil
+	}
+	record, err := db.poc.Dump([]any{key})
+	if err != nil {
+		return err
+	}
+	return db.write(record)
+}
+
+// GetRaw 返回键对应的值的编码数据
+func (db *DB) GetRaw(key string) (poculum.RawMessage, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.file == nil {
+		return nil, os.ErrClosed
+	}
+	loc, ok := db.index[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	value := make([]byte, loc.length)
+	if _, err := db.file.ReadAt(value, loc.offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Get 把键对应的值解码到 dst 中，键不存在时返回 ErrNotFound
+func (db *DB) Get(key string, dst any) error {
+	value, err := db.GetRaw(key)
+	if err != nil {
+		return err
+	}
+	return db.poc.Unmarshal(value, dst)
+}
+
+// Has 判断键是否存在
+func (db *DB) Has(key string) bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	_, ok := db.index[key]
+	return ok
+}
+
+// Keys 返回排序后的所有键
+func (db *DB) Keys() []string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	keys := make([]string, 0, len(db.index))
+	for key := range db.index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Compact 重写日志，只保留每个键的最新值，回收被覆盖与删除的记录占用的空间
+func (db *DB) Compact() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.file == nil {
+		return os.ErrClosed
+	}
+
+	tmpPath := db.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	compacted := &DB{file: tmp, path: db.path, index: make(map[string]location), poc: db.poc}
+	for key, loc := range db.index {
+		value := make([]byte, loc.length)
+		if _, err := db.file.ReadAt(value, loc.offset); err != nil && err != io.EOF {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+		record, err := db.poc.Dump([]any{key, poculum.RawMessage(value)})
+		if err == nil {
+			err = compacted.write(record)
+		}
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, db.path); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	db.file.Close()
+	db.file, db.size, db.index = tmp, compacted.size, compacted.index
+	// 刷新目录，保证重命名在崩溃后仍然有效
+	return syncDir(filepath.Dir(db.path))
+}
+
+// syncDir 把目录项的修改刷新到磁盘
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// Sync 把已写入的数据刷新到磁盘
+func (db *DB) Sync() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.file == nil {
+		return os.ErrClosed
+	}
+	return db.file.Sync()
+}
+
+// Close 关闭存储
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.file == nil {
+		return nil
+	}
+	err := db.file.Close()
+	db.file = nil
+	return err
+}
@@ -0,0 +1,162 @@
+package kv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type user struct {
+	Name string
+	Age  uint8
+}
+
+func TestPutGetReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.pkv")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put("user:1", user{Name: "ann", Age: 30}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put("user:2", user{Name: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put("user:1", user{Name: "ann", Age: 31}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete("user:2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟写入时崩溃留下的不完整记录
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte{1, 2, 3, 4, 0, 0, 0, 9, 0x91})
+	f.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var u user
+	if err := db.Get("user:1", &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Age != 31 {
+		t.Fatalf("got %+v", u)
+	}
+	if err := db.Get("user:2", &u); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	// 截断后新的记录可以正常追加
+	if err := db.Put("user:3", user{Name: "cy"}); err != nil {
+		t.Fatal(err)
+	}
+	if keys := db.Keys(); len(keys) != 2 || keys[1] != "user:3" {
+		t.Fatalf("keys = %v", keys)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.pkv")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 100; i++ {
+		if err := db.Put("counter", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before, _ := os.Stat(path)
+	if err := db.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size()/10 {
+		t.Fatalf("compact: %d -> %d bytes", before.Size(), after.Size())
+	}
+
+	var n int
+	if err := db.Get("counter", &n); err != nil || n != 99 {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if err := db.Put("other", "x"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCorruptMiddleRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.pkv")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if err := db.Put(key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	// 翻转第一条记录中的一位，其后仍有完整的记录
+	data, _ := os.ReadFile(path)
+	data[recordHeaderSize] ^= 0x01
+	os.WriteFile(path, data, 0o644)
+
+	if _, err := Open(path); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+	if info, _ := os.Stat(path); info.Size() != int64(len(data)) {
+		t.Fatalf("file truncated to %d bytes, want %d", info.Size(), len(data))
+	}
+
+	// 最后一条记录校验失败时视为写入时崩溃，只截断这一条
+	data[recordHeaderSize] ^= 0x01
+	data[len(data)-1] ^= 0x01
+	os.WriteFile(path, data, 0o644)
+	db, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if keys := db.Keys(); len(keys) != 2 {
+		t.Fatalf("keys = %v", keys)
+	}
+}
+
+func TestCorruptLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.pkv")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if err := db.Put(key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	// 长度字段变大后超出文件末尾，不能当作没有写完的记录截断
+	data, _ := os.ReadFile(path)
+	data[4] ^= 0x40
+	os.WriteFile(path, data, 0o644)
+
+	if _, err := Open(path); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+	if got, _ := os.ReadFile(path); len(got) != len(data) {
+		t.Fatalf("file truncated to %d bytes, want %d", len(got), len(data))
+	}
+}
@@ -0,0 +1,150 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Budget 编码数据的预算，为 0 的字段不检查
+type Budget struct {
+	MaxSize       int // 数据的总字节数
+	MaxDepth      int // 嵌套深度，顶层值的深度为 0
+	MaxStringSize int // 单个字符串的字节数，包括 map 的键
+	MaxBytesSize  int // 单个字节数据的字节数
+	MaxListItems  int // 单个 list 的元素个数
+	MaxMapEntries int // 单个 map 的键值对个数
+	MaxElements   int // 所有 list 的元素与 map 的键值对的总数
+}
+
+// 违反预算的规则
+const (
+	RuleSize     = "size"
+	RuleDepth    = "depth"
+	RuleString   = "string"
+	RuleBytes    = "bytes"
+	RuleList     = "list"
+	RuleMap      = "map"
+	RuleElements = "elements"
+	RuleInvalid  = "invalid" // 数据不是合法的编码，Err 为解码的错误
+)
+
+// Violation 一处违反预算的位置
+type Violation struct {
+	Path   string // 使用与 Get 相同的语法，顶层值为 ""
+	Rule   string // 违反的规则，例如 RuleDepth
+	Actual int    // 实际的值
+	Limit  int    // 预算
+	Err    error  // Rule 为 RuleInvalid 时的错误
+}
+
+// String 返回用于日志与 CI 输出的描述
+func (v Violation) String() string {
+	if v.Rule == RuleInvalid {
+		return fmt.Sprintf("%s: invalid data: %v", displayPath(v.Path), v.Err)
+	}
+	return fmt.Sprintf("%s: %s %d exceeds budget %d", displayPath(v.Path), v.Rule, v.Actual, v.Limit)
+}
+
+// Lint 使用默认实例检查 data 是否符合预算
+func Lint(data []byte, budget Budget) []Violation {
+	return Default().Lint(data, budget)
+}
+
+// Lint 检查 data 中的第一个值是否符合预算，返回所有违反预算的位置，符合时返回 nil。
+// 只读取类型与长度信息，不构造其中的值，可以在 CI 中检查录制的数据，也可以在解码不可信的数据之前检查。
+// 超过嵌套深度的值只报告一次，不再检查其中的内容；数据不合法时最后一项的规则为 RuleInvalid
+func (poc *Poculum) Lint(data []byte, budget Budget) []Violation {
+	l := linter{poc: poc, budget: budget, reader: bytes.NewReader(data)}
+	if budget.MaxSize > 0 && len(data) > budget.MaxSize {
+		l.report("", RuleSize, len(data), budget.MaxSize)
+	}
+	if err := l.value("", 0); err != nil {
+		l.out = append(l.out, Violation{Path: l.path, Rule: RuleInvalid, Err: err})
+	}
+	if budget.MaxElements > 0 && l.elements > budget.MaxElements {
+		l.report("", RuleElements, l.elements, budget.MaxElements)
+	}
+	return l.out
+}
+
+// linter 保存检查的状态
+type linter struct {
+	poc      *Poculum
+	budget   Budget
+	reader   *bytes.Reader
+	out      []Violation
+	elements int
+	path     string // 正在检查的路径，用于报告不合法的数据
+}
+
+func (l *linter) report(path, rule string, actual, limit int) {
+	l.out = append(l.out, Violation{Path: path, Rule: rule, Actual: actual, Limit: limit})
+}
+
+// check 在 limit 大于 0 并且 actual 超过 limit 时报告
+func (l *linter) check(path, rule string, actual, limit int) {
+	if limit > 0 && actual > limit {
+		l.report(path, rule, actual, limit)
+	}
+}
+
+// value 检查下一个值
+func (l *linter) value(path string, depth int) error {
+	l.path = path
+	if l.budget.MaxDepth > 0 && depth > l.budget.MaxDepth {
+		l.report(path, RuleDepth, depth, l.budget.MaxDepth)
+		return l.poc.skipValue(l.reader, depth)
+	}
+	if depth > l.poc.maxRecursionDepth {
+		return newError(MaxRecursionDepth, "Maximum recursion depth exceeded while parsing nested structure")
+	}
+
+	h, err := readHeader(l.reader)
+	if err != nil {
+		return err
+	}
+	switch {
+	case h.typeByte == typeExt:
+		return l.value(path, depth+1)
+
+	case h.isList():
+		l.check(path, RuleList, h.length, l.budget.MaxListItems)
+		l.elements += h.length
+		for i := 0; i < h.length; i++ {
+			if err := l.value(joinPath(path, strconv.Itoa(i)), depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case h.isMap():
+		l.check(path, RuleMap, h.length, l.budget.MaxMapEntries)
+		l.elements += h.length
+		for i := 0; i < h.length; i++ {
+			key, err := l.poc.readKey(l.reader)
+			if err != nil {
+				return err
+			}
+			itemPath := joinPath(path, key)
+			l.check(itemPath, RuleString, len(key), l.budget.MaxStringSize)
+			if err := l.value(itemPath, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch typeName(h.typeByte) {
+	case "string":
+		l.check(path, RuleString, h.length, l.budget.MaxStringSize)
+	case "bytes":
+		l.check(path, RuleBytes, h.length, l.budget.MaxBytesSize)
+	}
+	if h.length > l.reader.Len() {
+		return newError(InsufficientData, fmt.Sprintf("%s data", typeName(h.typeByte)))
+	}
+	_, err = l.reader.Seek(int64(h.length), io.SeekCurrent)
+	return err
+}
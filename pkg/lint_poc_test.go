@@ -0,0 +1,45 @@
+package poculum
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	data := MustDump(map[string]any{
+		"name": strings.Repeat("x", 20),
+		"tags": []any{"a", "b", "c", "d"},
+		"deep": []any{[]any{[]any{1}}},
+	})
+	if got := Lint(data, Budget{MaxSize: len(data), MaxDepth: 4}); got != nil {
+		t.Fatalf("expected no violations, got %v", got)
+	}
+
+	got := Lint(data, Budget{MaxSize: 10, MaxDepth: 3, MaxStringSize: 16, MaxListItems: 3, MaxElements: 5})
+	want := map[string]string{}
+	for _, v := range got {
+		want[v.Path+" "+v.Rule] = v.String()
+	}
+	for _, key := range []string{" size", "name string", "tags list", "deep.0.0.0 depth", " elements"} {
+		if _, ok := want[key]; !ok {
+			t.Errorf("missing violation %q in %v", key, got)
+		}
+	}
+	if len(got) != 5 {
+		t.Errorf("got %d violations: %v", len(got), got)
+	}
+
+	got = Lint(data[:len(data)-1], Budget{})
+	if len(got) != 1 || got[0].Rule != RuleInvalid || !IsKind(got[0].Err, InsufficientData) {
+		t.Fatalf("truncated data: %v", got)
+	}
+}
+
+func TestLintExtChain(t *testing.T) {
+	data := append(bytes.Repeat([]byte{typeExt, extSet}, 1<<16), typeNil)
+	out := NewPoculumSecure().Lint(data, Budget{})
+	if len(out) != 1 || out[0].Rule != RuleInvalid || !IsKind(out[0].Err, MaxRecursionDepth) {
+		t.Fatalf("got %v", out)
+	}
+}
@@ -0,0 +1,117 @@
+// Package mqttcodec 编解码 MQTT 消息的 poculum 负载，不依赖 MQTT 客户端库
+/*
+	物联网设备通过蜂窝网络发送数据时，poculum 比 JSON 节省流量。负载可以带有 2 字节的版本前缀，
+设备固件升级期间新旧格式的消息可以共存：
+
+	版本前缀（可选，大端序） | poculum 编码的值
+
+	codec := mqttcodec.New(mqttcodec.WithVersion(2))
+	payload, err := codec.Encode(reading)
+	client.Publish("sensors/42", 1, false, payload)
+
+	handle := mqttcodec.Handler(codec, func(topic string, version uint16, r Reading) error { ... })
+	client.Subscribe("sensors/+", 1, func(_ mqtt.Client, m mqtt.Message) { handle(m.Topic(), m.Payload()) })
+*/
+package mqttcodec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// ErrShortPayload 负载短于版本前缀
+var ErrShortPayload = errors.New("mqttcodec: payload shorter than version prefix")
+
+// Codec MQTT 负载的编解码器
+type Codec struct {
+	poc       *poculum.Poculum
+	versioned bool
+	version   uint16
+	accept    map[uint16]bool // 为 nil 时接受任意版本
+}
+
+// Option 配置 Codec
+type Option func(*Codec)
+
+// WithVersion 编码时加上版本前缀，解码时读取版本前缀
+func WithVersion(version uint16) Option {
+	return func(c *Codec) {
+		c.versioned = true
+		c.version = version
+	}
+}
+
+// WithAcceptVersions 解码时只接受 versions 中的版本，需要与 WithVersion 一起使用
+func WithAcceptVersions(versions ...uint16) Option {
+	return func(c *Codec) {
+		c.accept = make(map[uint16]bool, len(versions))
+		for _, v := range versions {
+			c.accept[v] = true
+		}
+	}
+}
+
+// WithPoculum 使用 poc 编解码，默认使用 poculum.NewPoculumSecure()，因为负载来自设备
+func WithPoculum(poc *poculum.Poculum) Option {
+	return func(c *Codec) {
+		c.poc = poc
+	}
+}
+
+// New 创建编解码器
+func New(opts ...Option) *Codec {
+	c := &Codec{poc: poculum.NewPoculumSecure()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Encode 编码 v，使用 WithVersion 时加上版本前缀
+func (c *Codec) Encode(v any) ([]byte, error) {
+	data, err := c.poc.Dump(v)
+	if err != nil || !c.versioned {
+		return data, err
+	}
+	payload := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(data)), c.version)
+	return append(payload, data...), nil
+}
+
+// Decode 把负载解码到 dst 中，返回负载的版本，没有使用 WithVersion 时版本总是 0
+func (c *Codec) Decode(payload []byte, dst any) (uint16, error) {
+	version, body, err := c.split(payload)
+	if err != nil {
+		return 0, err
+	}
+	return version, c.poc.Unmarshal(body, dst)
+}
+
+// split 拆分版本前缀与 poculum 数据，并检查版本是否被接受
+func (c *Codec) split(payload []byte) (uint16, []byte, error) {
+	if !c.versioned {
+		return 0, payload, nil
+	}
+	if len(payload) < 2 {
+		return 0, nil, ErrShortPayload
+	}
+	version := binary.BigEndian.Uint16(payload)
+	if c.accept != nil && !c.accept[version] {
+		return version, nil, fmt.Errorf("mqttcodec: unsupported payload version %d", version)
+	}
+	return version, payload[2:], nil
+}
+
+// Handler 返回处理 (topic, payload) 的函数，负载解码为 T 之后调用 fn，可以在 MQTT 客户端的回调中调用
+func Handler[T any](c *Codec, fn func(topic string, version uint16, v T) error) func(topic string, payload []byte) error {
+	return func(topic string, payload []byte) error {
+		var v T
+		version, err := c.Decode(payload, &v)
+		if err != nil {
+			return fmt.Errorf("mqttcodec: topic %s: %w", topic, err)
+		}
+		return fn(topic, version, v)
+	}
+}
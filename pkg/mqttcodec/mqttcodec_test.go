@@ -0,0 +1,45 @@
+package mqttcodec
+
+import (
+	"errors"
+	"testing"
+)
+
+type reading struct {
+	Temp float32 `poculum:"t"`
+}
+
+func TestVersionedPayload(t *testing.T) {
+	codec := New(WithVersion(2), WithAcceptVersions(1, 2))
+	payload, err := codec.Encode(reading{Temp: 21.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload[0] != 0 || payload[1] != 2 {
+		t.Fatalf("missing version prefix: % x", payload)
+	}
+
+	var got reading
+	handle := Handler(codec, func(topic string, version uint16, r reading) error {
+		if topic != "sensors/1" || version != 2 {
+			t.Errorf("topic %s, version %d", topic, version)
+		}
+		got = r
+		return nil
+	})
+	if err := handle("sensors/1", payload); err != nil || got.Temp != 21.5 {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+
+	if _, err := New(WithVersion(3), WithAcceptVersions(3)).Decode(payload, &got); err == nil {
+		t.Fatal("expected unsupported version error")
+	}
+	if _, err := codec.Decode([]byte{1}, &got); !errors.Is(err, ErrShortPayload) {
+		t.Fatalf("expected ErrShortPayload, got %v", err)
+	}
+
+	plain, _ := New().Encode(reading{Temp: 1})
+	if version, err := New().Decode(plain, &got); err != nil || version != 0 || got.Temp != 1 {
+		t.Fatalf("plain: %v, %d, %+v", err, version, got)
+	}
+}
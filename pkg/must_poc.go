@@ -0,0 +1,78 @@
+package poculum
+
+// 出错时 panic 的辅助函数
+/*
+	用于测试、脚本与初始化代码，这些场景中错误处理只是噪音：
+
+	data := poculum.MustDump(cfg)
+	cfg := poculum.LoadAs[Config](data).Must()
+	port := poculum.LoadAs[int](data).Or(8080)
+
+不要在处理外部输入的代码中使用 Must 系列函数
+*/
+
+// Must 在 err 不为 nil 时 panic，否则返回 v，例如 poculum.Must(poc.Dump(v))
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustDump 使用默认实例编码，出错时 panic
+func MustDump(value any) []byte {
+	return Must(DumpPoculum(value))
+}
+
+// MustLoad 使用默认实例解码，出错时 panic
+func MustLoad(data []byte) any {
+	return Must(LoadPoculum(data))
+}
+
+// MustUnmarshal 使用默认实例解码到 dst 中，出错时 panic
+func MustUnmarshal(data []byte, dst any) {
+	if err := Unmarshal(data, dst); err != nil {
+		panic(err)
+	}
+}
+
+// Result 一个值或者一个错误
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// ResultOf 把 (值, 错误) 组合为 Result
+func ResultOf[T any](v T, err error) Result[T] {
+	return Result[T]{value: v, err: err}
+}
+
+// LoadAs 使用默认实例把 data 解码为 T
+func LoadAs[T any](data []byte) Result[T] {
+	var v T
+	err := Unmarshal(data, &v)
+	return Result[T]{value: v, err: err}
+}
+
+// Get 返回值与错误
+func (r Result[T]) Get() (T, error) {
+	return r.value, r.err
+}
+
+// Err 返回错误
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// Must 返回值，存在错误时 panic
+func (r Result[T]) Must() T {
+	return Must(r.value, r.err)
+}
+
+// Or 返回值，存在错误时返回 fallback
+func (r Result[T]) Or(fallback T) T {
+	if r.err != nil {
+		return fallback
+	}
+	return r.value
+}
@@ -0,0 +1,84 @@
+// Package natsenc 提供 NATS 使用的 poculum 编码器，不依赖 nats.go
+/*
+	Encoder 实现了 nats.Encoder 接口（Encode、Decode 两个方法），注册一次之后，
+EncodedConn 的发布、订阅与请求都使用 poculum 编码的 struct：
+
+	nats.RegisterEncoder(natsenc.Name, natsenc.New(nil))
+	ec, err := nats.NewEncodedConn(nc, natsenc.Name)
+	ec.Publish("orders", &order)
+	ec.Subscribe("orders", func(o *Order) { ... })
+
+JetStream 的 API 直接收发字节与消息头，使用 Message 与 DecodeMessage：
+
+	data, header, err := natsenc.Message(order)
+	ack, err := js.PublishMsg(ctx, &nats.Msg{Subject: "orders", Data: data, Header: header})
+
+	err = natsenc.DecodeMessage(msg.Data(), msg.Headers(), &order)
+*/
+package natsenc
+
+import (
+	"fmt"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// Name 注册编码器时使用的名称
+const Name = "poculum"
+
+// HeaderContentType 标记消息内容格式的消息头
+const HeaderContentType = "Content-Type"
+
+// Encoder 使用 poculum 编解码 NATS 消息
+type Encoder struct {
+	poc *poculum.Poculum
+}
+
+// New 创建编码器，poc 为 nil 时使用 poculum.Default()
+func New(poc *poculum.Poculum) *Encoder {
+	return &Encoder{poc: poc}
+}
+
+func (e *Encoder) instance() *poculum.Poculum {
+	if e.poc == nil {
+		return poculum.Default()
+	}
+	return e.poc
+}
+
+// Encode 编码发布到 subject 的值
+func (e *Encoder) Encode(subject string, v any) ([]byte, error) {
+	return e.instance().Dump(v)
+}
+
+// Decode 把来自 subject 的数据解码到 vPtr 中。vPtr 可以指向 []byte 或者 string，
+// 与 nats.go 自带的编码器一样直接得到原始数据
+func (e *Encoder) Decode(subject string, data []byte, vPtr any) error {
+	switch p := vPtr.(type) {
+	case *[]byte:
+		*p = append([]byte(nil), data...)
+		return nil
+	case *string:
+		*p = string(data)
+		return nil
+	}
+	return e.instance().Unmarshal(data, vPtr)
+}
+
+// Message 使用默认实例编码 v，返回可以填入 nats.Msg 的数据与消息头（nats.Header 的底层类型）
+func Message(v any) ([]byte, map[string][]string, error) {
+	data, err := poculum.DumpPoculum(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, map[string][]string{HeaderContentType: {poculum.ContentTypePoculum}}, nil
+}
+
+// DecodeMessage 使用默认实例把消息数据解码到 dst 中。
+// 消息头中带有其他 Content-Type 时返回错误，没有 Content-Type 时按照 poculum 解码
+func DecodeMessage(data []byte, header map[string][]string, dst any) error {
+	if types := header[HeaderContentType]; len(types) > 0 && types[0] != poculum.ContentTypePoculum {
+		return fmt.Errorf("natsenc: unexpected content type %q", types[0])
+	}
+	return poculum.Unmarshal(data, dst)
+}
@@ -0,0 +1,33 @@
+package natsenc
+
+import "testing"
+
+func TestEncoder(t *testing.T) {
+	type order struct {
+		ID int `poculum:"id"`
+	}
+	enc := New(nil)
+	data, err := enc.Encode("orders", &order{ID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o order
+	if err := enc.Decode("orders", data, &o); err != nil || o.ID != 3 {
+		t.Fatalf("got %+v, %v", o, err)
+	}
+	var raw []byte
+	if err := enc.Decode("orders", data, &raw); err != nil || string(raw) != string(data) {
+		t.Fatalf("raw decode: %v", err)
+	}
+
+	data, header, err := Message(order{ID: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DecodeMessage(data, header, &o); err != nil || o.ID != 4 {
+		t.Fatalf("got %+v, %v", o, err)
+	}
+	if err := DecodeMessage(data, map[string][]string{HeaderContentType: {"application/json"}}, &o); err == nil {
+		t.Fatal("expected content type error")
+	}
+}
@@ -0,0 +1,116 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+)
+
+// 网络地址扩展类型
+/*
+	netip.Addr 与 net.IP 编码为带有地址扩展标识的字节数据，IPv4 为 4 个字节，IPv6 为 16 个字节；
+netip.Prefix 编码为带有前缀扩展标识的字节数据，在地址之后追加一个字节的前缀长度。
+解码得到 netip.Addr 与 netip.Prefix，Unmarshal 时也可以写入 net.IP 类型的字段。
+带有 zone 的 IPv6 地址不支持编码，无效的（零值）地址编码为 nil
+*/
+
+var netIPType = reflect.TypeOf(net.IP(nil))
+
+// encodeAddr 编码 IP 地址
+func (poc *Poculum) encodeAddr(addr netip.Addr, buf *bytes.Buffer) error {
+	if !addr.IsValid() {
+		return buf.WriteByte(typeNil)
+	}
+	if addr.Zone() != "" {
+		return newError(UnsupportedType, fmt.Sprintf("IP address with zone is not supported: %s", addr))
+	}
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extAddr)
+	return poc.encodeBytes(addr.AsSlice(), buf)
+}
+
+// encodeNetIP 编码 net.IP，长度不合法的 net.IP 返回错误
+func (poc *Poculum) encodeNetIP(ip net.IP, buf *bytes.Buffer) error {
+	if ip == nil {
+		return buf.WriteByte(typeNil)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return newError(UnsupportedType, fmt.Sprintf("Invalid net.IP length: %d", len(ip)))
+	}
+	return poc.encodeAddr(addr, buf)
+}
+
+// encodePrefix 编码 IP 前缀
+func (poc *Poculum) encodePrefix(prefix netip.Prefix, buf *bytes.Buffer) error {
+	if !prefix.IsValid() {
+		return buf.WriteByte(typeNil)
+	}
+	if prefix.Addr().Zone() != "" {
+		return newError(UnsupportedType, fmt.Sprintf("IP prefix with zone is not supported: %s", prefix))
+	}
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extPrefix)
+	return poc.encodeBytes(append(prefix.Addr().AsSlice(), byte(prefix.Bits())), buf)
+}
+
+// readAddrBytes 读取地址扩展类型中的字节数据
+func (poc *Poculum) readAddrBytes(reader *bytes.Reader, depth int, what string) ([]byte, error) {
+	value, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := value.([]byte)
+	if !ok {
+		return nil, newError(TypeMismatch, fmt.Sprintf("%s must contain bytes, got %T", what, value))
+	}
+	return data, nil
+}
+
+// decodeAddr 解码 IP 地址
+func (poc *Poculum) decodeAddr(reader *bytes.Reader, depth int) (netip.Addr, error) {
+	data, err := poc.readAddrBytes(reader, depth, "IP address")
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	addr, ok := netip.AddrFromSlice(data)
+	if !ok {
+		return netip.Addr{}, newError(InvalidAddr, fmt.Sprintf("IP address must be 4 or 16 bytes, got %d", len(data)))
+	}
+	return addr, nil
+}
+
+// decodePrefix 解码 IP 前缀
+func (poc *Poculum) decodePrefix(reader *bytes.Reader, depth int) (netip.Prefix, error) {
+	data, err := poc.readAddrBytes(reader, depth, "IP prefix")
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if len(data) == 0 {
+		return netip.Prefix{}, newError(InvalidAddr, "IP prefix is empty")
+	}
+	addr, ok := netip.AddrFromSlice(data[:len(data)-1])
+	if !ok {
+		return netip.Prefix{}, newError(InvalidAddr, fmt.Sprintf("IP prefix must be 5 or 17 bytes, got %d", len(data)))
+	}
+	bits := int(data[len(data)-1])
+	if bits > addr.BitLen() {
+		return netip.Prefix{}, newError(InvalidAddr, fmt.Sprintf("IP prefix length %d exceeds %d bits", bits, addr.BitLen()))
+	}
+	return netip.PrefixFrom(addr, bits), nil
+}
+
+// assignNetIP 把解码得到的 netip.Addr 写入 net.IP 类型的目标
+func assignNetIP(dst reflect.Value, src any) bool {
+	addr, ok := src.(netip.Addr)
+	if !ok || dst.Type() != netIPType {
+		return false
+	}
+	dst.Set(reflect.ValueOf(net.IP(addr.AsSlice())))
+	return true
+}
@@ -0,0 +1,40 @@
+package poculum
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestNetAddr(t *testing.T) {
+	type Flow struct {
+		Src    netip.Addr
+		Dst    net.IP
+		Subnet netip.Prefix
+	}
+	in := Flow{
+		Src:    netip.MustParseAddr("10.0.0.1"),
+		Dst:    net.ParseIP("2001:db8::1"),
+		Subnet: netip.MustParsePrefix("192.168.0.0/16"),
+	}
+	data, err := DumpPoculum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out Flow
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Src != in.Src || !out.Dst.Equal(in.Dst) || out.Subnet != in.Subnet {
+		t.Fatalf("got %+v, want %+v", out, in)
+	}
+
+	raw, err := GetRaw(data, "Src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 2+2+4 {
+		t.Fatalf("IPv4 address encoded in %d bytes", len(raw))
+	}
+}
@@ -0,0 +1,97 @@
+package poculum
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// nil 与空容器
+/*
+	默认情况下 nil slice 与 nil map 编码为空的 list 与 map，解码时数据中的 nil 赋给 slice、map 得到 nil，
+空的 list 与 map 得到空的 slice 与 map。需要区分“没有 list”与“空 list”的消费方可以修改这两个行为：
+
+	WithNilEncoding(NilAsNil)   nil slice 与 nil map 编码为 nil，空的 slice 与 map 仍然编码为空容器
+	WithEmptyOnNil()            解码时数据中的 nil 赋给 slice、map 得到空的 slice 与 map
+	WithStrictNil()             解码时数据中的 nil 赋给不能为 nil 的目标（整数、字符串、struct 等）时返回错误
+
+数组与 struct 不是 nil，不受影响。默认情况下 nil 赋给不能为 nil 的目标得到零值
+*/
+
+// NilPolicy 编码 nil slice 与 nil map 的方式
+type NilPolicy uint8
+
+const (
+	NilAsEmpty NilPolicy = iota // 编码为空的 list 与 map（默认）
+	NilAsNil                    // 编码为 nil
+)
+
+// WithNilEncoding 设置编码 nil slice 与 nil map 的方式
+func WithNilEncoding(policy NilPolicy) ConfigOption {
+	return func(poc *Poculum) {
+		poc.nilPolicy = policy
+	}
+}
+
+// WithEmptyOnNil 解码时把数据中的 nil 赋给 slice 与 map 得到空的 slice 与 map，而不是 nil
+func WithEmptyOnNil() ConfigOption {
+	return func(poc *Poculum) {
+		poc.emptyOnNil = true
+	}
+}
+
+// WithStrictNil 解码时数据中的 nil 只能赋给指针、slice、map 与 interface，
+// 赋给其它类型时返回 TypeMismatch 错误，而不是得到零值。Option 等自己处理 nil 的类型不受影响
+func WithStrictNil() ConfigOption {
+	return func(poc *Poculum) {
+		poc.strictNil = true
+	}
+}
+
+// isNilContainer 判断 value 是否是 nil slice 或者 nil map
+func isNilContainer(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case []any:
+		return v == nil
+	case map[string]any:
+		return v == nil
+	case []byte:
+		return v == nil
+	}
+	rv := reflect.ValueOf(value)
+	return (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.IsNil()
+}
+
+// encodeNilContainer 在 NilAsNil 策略下把 nil slice 与 nil map 编码为 nil，返回是否已经编码
+func (poc *Poculum) encodeNilContainer(value any, buf *bytes.Buffer) bool {
+	if poc.nilPolicy != NilAsNil || !isNilContainer(value) {
+		return false
+	}
+	buf.WriteByte(typeNil)
+	return true
+}
+
+// assignNil 把数据中的 nil 赋给 dst
+func (poc *Poculum) assignNil(dst reflect.Value, path string) error {
+	switch {
+	case poc.strictNil && !isNullable(dst.Kind()):
+		return mismatchError(path, nil, dst)
+	case poc.emptyOnNil && dst.Kind() == reflect.Slice:
+		dst.Set(reflect.MakeSlice(dst.Type(), 0, 0))
+	case poc.emptyOnNil && dst.Kind() == reflect.Map:
+		dst.Set(reflect.MakeMap(dst.Type()))
+	default:
+		dst.Set(reflect.Zero(dst.Type()))
+	}
+	return nil
+}
+
+// isNullable 判断 kind 的值是否可以为 nil
+func isNullable(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package poculum
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// 数值 list 的统一类型
+/*
+	不同的生产者编码同一个数值 list 时可能使用不同宽度的整数，例如本库使用能容纳值的最小宽度，
+其它实现总是使用 int64，Load 得到的 []any 中的元素类型因此并不固定。
+WithNumericLists 把解码得到的数值 list 的元素统一转换为 T：
+
+	poc := poculum.NewPoculum(poculum.WithNumericLists[int64]())
+	v, err := poc.Load(data) // [uint8(1), uint32(70000), int64(-1)] 得到 [int64(1), int64(70000), int64(-1)]
+
+只有元素都是数字或者 nil（至少有一个数字）的 list 被转换，nil 保持不变，结果仍然是 []any。
+转换有损失时返回 ValueOutOfRange 错误：超出 T 的范围、带有小数部分的浮点数转换为整数、
+整数转换为浮点数时无法精确表示、float64 转换为 float32 时丢失精度。
+只影响解码到 any 的值，Unmarshal 到 []int64 等具体类型时本来就会转换
+*/
+
+// Number 可以作为 WithNumericLists 目标的数值类型
+type Number interface {
+	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | float32 | float64
+}
+
+// WithNumericLists 解码时把数值 list 的元素统一转换为 T，转换有损失时返回错误
+func WithNumericLists[T Number]() ConfigOption {
+	return func(poc *Poculum) {
+		poc.numericList = reflect.TypeFor[T]()
+	}
+}
+
+// normalizeNumbers 把数值 list 的元素转换为 poc.numericList，arr 不是数值 list 时原样返回
+func (poc *Poculum) normalizeNumbers(arr []any) ([]any, error) {
+	numbers := 0
+	for _, item := range arr {
+		switch item.(type) {
+		case nil:
+		case int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64:
+			numbers++
+		default:
+			return arr, nil
+		}
+	}
+	if numbers == 0 {
+		return arr, nil
+	}
+
+	for i, item := range arr {
+		if item == nil {
+			continue
+		}
+		converted, ok := convertNumber(item, poc.numericList)
+		if !ok {
+			return nil, newError(ValueOutOfRange, fmt.Sprintf("List item %d: %T %v cannot be converted to %s without loss", i, item, item, poc.numericList))
+		}
+		arr[i] = converted
+	}
+	return arr, nil
+}
+
+// convertNumber 把解码得到的数字无损地转换为 t 类型，有损失时返回 false
+func convertNumber(v any, t reflect.Type) (any, bool) {
+	if reflect.TypeOf(v) == t {
+		return v, true
+	}
+	f, isFloat := v.(float64)
+	if f32, ok := v.(float32); ok {
+		f, isFloat = float64(f32), true
+	}
+
+	rv := reflect.New(t).Elem()
+	switch {
+	case rv.CanInt():
+		n, ok := toInt64(v)
+		if isFloat {
+			ok = f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64
+			n = int64(f)
+		}
+		if !ok || rv.OverflowInt(n) {
+			return nil, false
+		}
+		rv.SetInt(n)
+	case rv.CanUint():
+		n, ok := toUint64(v)
+		if isFloat {
+			ok = f == math.Trunc(f) && f >= 0 && f < math.MaxUint64
+			n = uint64(f)
+		}
+		if !ok || rv.OverflowUint(n) {
+			return nil, false
+		}
+		rv.SetUint(n)
+	default:
+		if !isFloat {
+			// 整数转换为浮点数后必须能还原
+			if n, ok := toInt64(v); ok {
+				f = float64(n)
+				isFloat = f < math.MaxInt64 && int64(f) == n
+			} else if n, ok := toUint64(v); ok {
+				f = float64(n)
+				isFloat = f < math.MaxUint64 && uint64(f) == n
+			}
+		}
+		if !isFloat || t.Kind() == reflect.Float32 && !math.IsNaN(f) && float64(float32(f)) != f {
+			return nil, false
+		}
+		rv.SetFloat(f)
+	}
+	return rv.Interface(), true
+}
@@ -0,0 +1,46 @@
+package poculum
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNumericLists(t *testing.T) {
+	data := MustDump(map[string]any{
+		"ids":   []any{uint8(1), uint32(70000), int64(-1), nil},
+		"names": []any{"a", uint8(1)},
+	})
+	v, err := NewPoculum(WithNumericLists[int64]()).Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := v.(map[string]any)
+	if want := []any{int64(1), int64(70000), int64(-1), nil}; !reflect.DeepEqual(m["ids"], want) {
+		t.Fatalf("ids = %#v", m["ids"])
+	}
+	if names := m["names"].([]any); names[1] != uint8(1) {
+		t.Fatalf("non-numeric list changed: %#v", names)
+	}
+
+	v, err = NewPoculum(WithNumericLists[float64]()).Load(MustDump([]any{uint8(1), float32(0.5)}))
+	if err != nil || !reflect.DeepEqual(v, []any{1.0, 0.5}) {
+		t.Fatalf("got %#v, %v", v, err)
+	}
+
+	lossy := []struct {
+		name string
+		poc  *Poculum
+		data []any
+	}{
+		{"negative to uint", NewPoculum(WithNumericLists[uint32]()), []any{uint8(1), int8(-1)}},
+		{"overflow", NewPoculum(WithNumericLists[int8]()), []any{uint16(300)}},
+		{"fraction", NewPoculum(WithNumericLists[int64]()), []any{1.5}},
+		{"float precision", NewPoculum(WithNumericLists[float64]()), []any{uint64(1<<53 + 1)}},
+		{"float32 precision", NewPoculum(WithNumericLists[float32]()), []any{0.1}},
+	}
+	for _, c := range lossy {
+		if _, err := c.poc.Load(MustDump(c.data)); !IsKind(err, ValueOutOfRange) {
+			t.Fatalf("%s: expected ValueOutOfRange, got %v", c.name, err)
+		}
+	}
+}
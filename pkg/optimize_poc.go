@@ -0,0 +1,230 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// 体积优化的编码
+/*
+	WithOptimize 在普通编码之后再遍历一遍编码的结果，对每个值选择最小的表示，适合写入一次、读取多次的数据：
+
+	整数       使用能容纳它的最小宽度，非负数使用无符号类型
+	浮点数     可以无损转换为 float32 的 float64 使用 float32
+	list       比较普通编码与稀疏编码的实际字节数，使用较小的一种，与 WithSparseLists 的比例无关
+
+扩展类型（Set、时间戳、张量等）原样保留。编码的结果与普通编码可以互相解码，
+只是 Load 得到的整数与浮点数类型可能更窄，Unmarshal 到 struct 时没有区别。
+编码格式没有字符串引用，重复的字符串保持不变，可以使用 Stats 的 RepeatedStrings 估计它们占用的空间
+*/
+
+// WithOptimize 编码时选择最小的表示，编码需要多遍历一次数据
+func WithOptimize() ConfigOption {
+	return func(poc *Poculum) {
+		poc.optimize = true
+	}
+}
+
+// optimizeEncoded 重写已经编码的数据，使用最小的表示
+func (poc *Poculum) optimizeEncoded(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	if err := poc.optimizeValue(data, bytes.NewReader(data), &buf, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// optimizeValue 重写 reader 中的下一个值并写入 buf，data 是 reader 读取的完整数据
+func (poc *Poculum) optimizeValue(data []byte, reader *bytes.Reader, buf *bytes.Buffer, depth int) error {
+	start := len(data) - reader.Len()
+	if reader.Len() > 0 && data[start] == typeExt {
+		raw, err := poc.readRaw(reader, depth)
+		if err != nil {
+			return err
+		}
+		buf.Write(raw)
+		return nil
+	}
+
+	h, err := readHeader(reader)
+	if err != nil {
+		return err
+	}
+	if h.isMap() {
+		writeMapHeader(buf, h.length)
+		for i := 0; i < h.length; i++ {
+			keyStart := len(data) - reader.Len()
+			if _, err := poc.readKey(reader); err != nil {
+				return err
+			}
+			buf.Write(data[keyStart : len(data)-reader.Len()])
+			if err := poc.optimizeValue(data, reader, buf, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if h.isList() {
+		return poc.optimizeList(data, reader, buf, h.length, depth)
+	}
+
+	if h.length > reader.Len() {
+		return newError(InsufficientData, fmt.Sprintf("%s data", typeName(h.typeByte)))
+	}
+	body := data[len(data)-reader.Len():][:h.length]
+	reader.Seek(int64(h.length), io.SeekCurrent)
+
+	switch h.typeByte {
+	case typeUInt8, typeUInt16, typeUInt32, typeUInt64:
+		writeCompactUint(buf, readUintBytes(body))
+	case typeInt8, typeInt16, typeInt32, typeInt64:
+		// 左移到最高位再算术右移，得到符号扩展的值
+		shift := 64 - 8*len(body)
+		writeCompactInt(buf, int64(readUintBytes(body)<<shift)>>shift)
+	case typeFloat64:
+		f := math.Float64frombits(binary.BigEndian.Uint64(body))
+		if float64(float32(f)) == f {
+			buf.WriteByte(typeFloat32)
+			binary.Write(buf, binary.BigEndian, float32(f))
+		} else {
+			buf.Write(data[start : len(data)-reader.Len()])
+		}
+	default:
+		buf.Write(data[start : len(data)-reader.Len()])
+	}
+	return nil
+}
+
+// optimizeList 重写 list 的元素，稀疏编码更小时使用稀疏编码
+func (poc *Poculum) optimizeList(data []byte, reader *bytes.Reader, buf *bytes.Buffer, length int, depth int) error {
+	if length > reader.Len() {
+		return newError(InsufficientData, fmt.Sprintf("Array of %d items", length))
+	}
+	var body bytes.Buffer
+	ends := make([]int, length)
+	for i := range ends {
+		if err := poc.optimizeValue(data, reader, &body, depth+1); err != nil {
+			return err
+		}
+		ends[i] = body.Len()
+	}
+	items := make([][]byte, length)
+	prev := 0
+	for i, end := range ends {
+		items[i] = body.Bytes()[prev:end]
+		prev = end
+	}
+
+	// 找出最常见的零值作为填充值，比较两种编码的字节数
+	counts := make(map[string]int)
+	fill, fills := "", 0
+	for _, item := range items {
+		if !isZeroRaw(item) {
+			continue
+		}
+		counts[string(item)]++
+		if counts[string(item)] > fills {
+			fill, fills = string(item), counts[string(item)]
+		}
+	}
+	if fills > 0 && !poc.noSparse {
+		sparse := 2 + listHeaderSize(2+2*(length-fills)) + compactUintSize(length) + len(fill)
+		for i, item := range items {
+			if string(item) != fill {
+				sparse += compactUintSize(i) + len(item)
+			}
+		}
+		if sparse < listHeaderSize(length)+body.Len() {
+			buf.WriteByte(typeExt)
+			buf.WriteByte(extSparse)
+			writeListHeader(buf, 2+2*(length-fills))
+			writeCompactUint(buf, uint64(length))
+			buf.WriteString(fill)
+			for i, item := range items {
+				if string(item) != fill {
+					writeCompactUint(buf, uint64(i))
+					buf.Write(item)
+				}
+			}
+			return nil
+		}
+	}
+
+	writeListHeader(buf, length)
+	buf.Write(body.Bytes())
+	return nil
+}
+
+// readUintBytes 按照大端序读取 1~8 个字节的无符号整数
+func readUintBytes(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
+
+// writeCompactUint 使用能容纳 n 的最小宽度的无符号整数写入 n
+func writeCompactUint(buf *bytes.Buffer, n uint64) {
+	switch {
+	case n <= math.MaxUint8:
+		buf.WriteByte(typeUInt8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(typeUInt16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(typeUInt32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(typeUInt64)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+// compactUintSize 返回 writeCompactUint 写入 n 的字节数
+func compactUintSize(n int) int {
+	switch {
+	case n <= math.MaxUint8:
+		return 2
+	case n <= math.MaxUint16:
+		return 3
+	case uint64(n) <= math.MaxUint32:
+		return 5
+	default:
+		return 9
+	}
+}
+
+// writeCompactInt 使用能容纳 n 的最小宽度的整数写入 n，非负数使用无符号类型
+func writeCompactInt(buf *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0:
+		writeCompactUint(buf, uint64(n))
+	case n >= math.MinInt8:
+		buf.WriteByte(typeInt8)
+		buf.WriteByte(byte(n))
+	case n >= math.MinInt16:
+		buf.WriteByte(typeInt16)
+		binary.Write(buf, binary.BigEndian, int16(n))
+	case n >= math.MinInt32:
+		buf.WriteByte(typeInt32)
+		binary.Write(buf, binary.BigEndian, int32(n))
+	default:
+		buf.WriteByte(typeInt64)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+// isZeroRaw 判断优化后的编码是否是可以作为稀疏填充值的零值：nil、false、整数 0 或者浮点数 +0
+func isZeroRaw(item []byte) bool {
+	switch string(item) {
+	case string([]byte{typeNil}), string([]byte{typeFalse}), string([]byte{typeUInt8, 0}), string([]byte{typeFloat32, 0, 0, 0, 0}):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package poculum
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	type reading struct {
+		ID     int64     `poculum:"id"`
+		Temp   float64   `poculum:"temp"`
+		Delta  int       `poculum:"delta"`
+		Values []float64 `poculum:"values"`
+		Flags  []any     `poculum:"flags"`
+	}
+	in := reading{ID: 7, Temp: 21.5, Delta: -300, Values: make([]float64, 32), Flags: []any{nil, false, 70000, -1}}
+	in.Values[3], in.Values[30] = 1.25, 0.1
+
+	plain := MustDump(in)
+	poc := NewPoculum(WithOptimize())
+	data, err := poc.Dump(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) >= len(plain)/2 {
+		t.Errorf("optimized %d bytes, plain %d bytes", len(data), len(plain))
+	}
+
+	var out reading
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	// []any 中的整数解码为更窄的类型，在下面检查
+	out.Flags = in.Flags
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("got %+v, want %+v", out, in)
+	}
+
+	got, err := poc.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := got.(map[string]any)
+	if m["id"] != uint8(7) || m["delta"] != int16(-300) || m["temp"] != float32(21.5) {
+		t.Errorf("got %#v", m)
+	}
+	if !reflect.DeepEqual(m["flags"], []any{nil, false, uint32(70000), int8(-1)}) {
+		t.Errorf("flags = %#v", m["flags"])
+	}
+}
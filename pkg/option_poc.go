@@ -0,0 +1,107 @@
+package poculum
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// optionState Option 的三种状态
+type optionState uint8
+
+const (
+	optionAbsent optionState = iota // 不存在，struct 字段编码时省略该键
+	optionNull                      // 显式的 nil
+	optionValue                     // 存在值，值可以是零值
+)
+
+// Option 区分 "不存在"、"nil" 与 "零值" 三种情况，用于 PATCH 风格的 API
+//
+// 作为 struct 字段时，零值的 Option 表示不存在，编码时不写入该键，解码时数据中没有该键也保持不存在；
+// Null 编码为 nil，数据中的 nil 解码为 Null；Some 编码为其中的值。
+// 不在 struct 字段中时（例如 list 的元素），不存在与 nil 都编码为 nil
+type Option[T any] struct {
+	value T
+	state optionState
+}
+
+// Some 创建一个存在值的 Option
+func Some[T any](value T) Option[T] {
+	return Option[T]{value: value, state: optionValue}
+}
+
+// Null 创建一个显式为 nil 的 Option
+func Null[T any]() Option[T] {
+	return Option[T]{state: optionNull}
+}
+
+// Present 判断是否存在，显式的 nil 也算作存在
+func (o Option[T]) Present() bool {
+	return o.state != optionAbsent
+}
+
+// IsNull 判断是否是显式的 nil
+func (o Option[T]) IsNull() bool {
+	return o.state == optionNull
+}
+
+// Get 返回其中的值，不存在或者为 nil 时第二个返回值为 false
+func (o Option[T]) Get() (T, bool) {
+	return o.value, o.state == optionValue
+}
+
+// ValueOr 返回其中的值，不存在或者为 nil 时返回 fallback
+func (o Option[T]) ValueOr(fallback T) T {
+	if o.state == optionValue {
+		return o.value
+	}
+	return fallback
+}
+
+func (o Option[T]) optionValue() (any, optionState) {
+	return o.value, o.state
+}
+
+func (o *Option[T]) setOption(state optionState) reflect.Value {
+	var zero T
+	o.value = zero
+	o.state = state
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+// optional 由 Option 实现，用于编码
+type optional interface {
+	optionValue() (any, optionState)
+}
+
+// optionalTarget 由 *Option 实现，用于解码；setOption 设置状态并返回存放值的位置
+type optionalTarget interface {
+	setOption(state optionState) reflect.Value
+}
+
+// isAbsentOption 判断 value 是否是不存在的 Option
+func isAbsentOption(value any) bool {
+	o, ok := value.(optional)
+	if !ok {
+		return false
+	}
+	_, state := o.optionValue()
+	return state == optionAbsent
+}
+
+// encodeOption 编码 Option 中的值，不存在与 nil 都编码为 nil
+func (poc *Poculum) encodeOption(o optional, buf *bytes.Buffer, depth int) error {
+	value, state := o.optionValue()
+	if state != optionValue {
+		return buf.WriteByte(typeNil)
+	}
+	return poc.encodeValue(value, buf, depth)
+}
+
+// assignOption 把解码得到的值赋给 Option
+func (poc *Poculum) assignOption(target optionalTarget, src any, path string) error {
+	if src == nil {
+		target.setOption(optionNull)
+		return nil
+	}
+	return poc.assign(target.setOption(optionValue), src, path)
+}
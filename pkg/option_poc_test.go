@@ -0,0 +1,37 @@
+package poculum
+
+import (
+	"testing"
+)
+
+func TestOption(t *testing.T) {
+	type patch struct {
+		Name  Option[string] `poculum:"name"`
+		Age   Option[int]    `poculum:"age"`
+		Email Option[string] `poculum:"email"`
+	}
+
+	data, err := DumpPoculum(patch{Name: Some(""), Age: Null[int]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _ := LoadPoculum(data)
+	obj := value.(map[string]any)
+	if _, ok := obj["email"]; ok || len(obj) != 2 || obj["age"] != nil || obj["name"] != "" {
+		t.Fatalf("unexpected encoding: %#v", obj)
+	}
+
+	var out patch
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if name, ok := out.Name.Get(); !ok || name != "" {
+		t.Errorf("name: %+v", out.Name)
+	}
+	if !out.Age.Present() || !out.Age.IsNull() {
+		t.Errorf("age: %+v", out.Age)
+	}
+	if out.Email.Present() {
+		t.Errorf("email: %+v", out.Email)
+	}
+}
@@ -0,0 +1,110 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// applyOp 把一个操作应用到完整的值 data 上，返回新的值
+//
+// 只有路径上的容器会被重新拼接，其它部分的字节原样复制；
+// 增加或者删除 map 的键时只重写该 map 的头部
+func (poc *Poculum) applyOp(data []byte, path []string, op PatchOp, depth int) ([]byte, error) {
+	if depth > poc.maxRecursionDepth {
+		return nil, newError(MaxRecursionDepth, "Maximum recursion depth exceeded while applying patch")
+	}
+	if len(path) == 0 {
+		if op.Delete {
+			return nil, newError(InvalidPath, "Cannot delete the top-level value")
+		}
+		return append([]byte(nil), op.Value...), nil
+	}
+
+	h, headerLen, entries, err := poc.rawEntries(data, depth)
+	if err != nil {
+		return nil, err
+	}
+	seg := path[0]
+	at := func() string { return strings.Join(op.Path[:len(op.Path)-len(path)+1], ".") }
+
+	var target *rawEntry
+	if h.isMap() {
+		for i := range entries {
+			if entries[i].key == seg {
+				target = &entries[i]
+				break
+			}
+		}
+	} else {
+		index, err := strconv.Atoi(seg)
+		if err != nil || index < 0 {
+			return nil, newError(InvalidPath, fmt.Sprintf("List index must be a non-negative integer: %q", at()))
+		}
+		if index >= len(entries) {
+			return nil, newError(PathNotFound, fmt.Sprintf("Index %q out of range (length %d)", at(), len(entries)))
+		}
+		if op.Delete && len(path) == 1 {
+			return nil, newError(InvalidPath, fmt.Sprintf("Cannot delete list element %q", at()))
+		}
+		target = &entries[index]
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case target == nil && (op.Delete || len(path) > 1):
+		return nil, newError(PathNotFound, fmt.Sprintf("Key %q not found", at()))
+	case target == nil:
+		// 在 map 的末尾追加新的键
+		writeMapHeader(&buf, h.length+1)
+		buf.Write(data[headerLen:])
+		if err := poc.encodeString(seg, &buf); err != nil {
+			return nil, err
+		}
+		buf.Write(op.Value)
+	case op.Delete && len(path) == 1:
+		writeMapHeader(&buf, h.length-1)
+		buf.Write(data[headerLen:target.start])
+		buf.Write(data[target.end:])
+	default:
+		value, err := poc.applyOp(data[target.valueStart:target.end], path[1:], op, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data[:target.valueStart])
+		buf.Write(value)
+		buf.Write(data[target.end:])
+	}
+	return buf.Bytes(), nil
+}
+
+// ApplyPatch 把 Diff 得到的 Patch 应用到 base 上，返回新的数据，base 不会被修改
+func ApplyPatch(base []byte, p Patch) ([]byte, error) {
+	return Default().ApplyPatch(base, p)
+}
+
+// ApplyPatch 把 Patch 应用到 base 上，返回新的数据
+func (poc *Poculum) ApplyPatch(base []byte, p Patch) ([]byte, error) {
+	if err := poc.validRaw(base); err != nil {
+		return nil, err
+	}
+
+	data := base
+	for i, op := range p {
+		if !op.Delete {
+			if err := poc.validRaw(op.Value); err != nil {
+				return nil, newError(InvalidPatch, fmt.Sprintf("Patch operation %d: %v", i, err))
+			}
+		}
+		next, err := poc.applyOp(data, op.Path, op, 0)
+		if err != nil {
+			return nil, err
+		}
+		data = next
+	}
+	if len(p) == 0 {
+		data = append([]byte(nil), base...)
+	}
+	return data, nil
+}
@@ -0,0 +1,281 @@
+package poculum
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Raw 是 RawMessage 的别名，表示数据中某个值的原始字节
+type Raw = RawMessage
+
+// 路径语法说明
+/*
+	路径由 "." 分隔的段组成，例如 "user.tags.0"
+
+对于 map，段是键名；对于 list，段是十进制下标。空路径代表顶层值本身。
+*/
+
+// splitPath 把路径拆分为段
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// readKey 读取 map 的键
+func (poc *Poculum) readKey(reader *bytes.Reader) (string, error) {
+	h, err := readHeader(reader)
+	if err != nil {
+		return "", err
+	}
+	isString := (h.typeByte >= typeFixStringBase && h.typeByte <= typeFixStringBase+15) ||
+		h.typeByte == typeString16 || h.typeByte == typeString32
+	if !isString {
+		return "", newError(UnsupportedType, "Object key must be string")
+	}
+	return poc.decodeString(reader, h.length)
+}
+
+// seekPath 把 reader 移动到 segments 所指向的值的开头
+func (poc *Poculum) seekPath(reader *bytes.Reader, segments []string) error {
+	for depth, seg := range segments {
+		h, err := readHeader(reader)
+		for err == nil && h.typeByte == typeExt {
+			// 扩展类型按照其中的值进行查找
+			h, err = readHeader(reader)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case h.isMap():
+			found := false
+			for i := 0; i < h.length; i++ {
+				key, err := poc.readKey(reader)
+				if err != nil {
+					return err
+				}
+				if key == seg {
+					found = true
+					break
+				}
+				if err := poc.skipValue(reader, depth+1); err != nil {
+					return err
+				}
+			}
+			if !found {
+				return newError(PathNotFound, fmt.Sprintf("Key %q not found", strings.Join(segments[:depth+1], ".")))
+			}
+		case h.isList():
+			index, err := strconv.Atoi(seg)
+			if err != nil || index < 0 {
+				return newError(InvalidPath, fmt.Sprintf("List index must be a non-negative integer: %q", seg))
+			}
+			if index >= h.length {
+				return newError(PathNotFound, fmt.Sprintf("Index %d out of range (length %d)", index, h.length))
+			}
+			for i := 0; i < index; i++ {
+				if err := poc.skipValue(reader, depth+1); err != nil {
+					return err
+				}
+			}
+		default:
+			return newError(InvalidPath, fmt.Sprintf("Cannot index into type 0x%02x with %q", h.typeByte, seg))
+		}
+	}
+
+	return nil
+}
+
+// getRaw 返回路径所指向的值在 data 中的字节范围
+func (poc *Poculum) getRaw(data []byte, path string) (Raw, error) {
+	reader := bytes.NewReader(data)
+	if err := poc.seekPath(reader, splitPath(path)); err != nil {
+		return nil, err
+	}
+
+	start := len(data) - reader.Len()
+	if err := poc.skipValue(reader, 0); err != nil {
+		return nil, err
+	}
+	end := len(data) - reader.Len()
+
+	return Raw(data[start:end:end]), nil
+}
+
+// GetRaw 返回 data 中 path 所指向的嵌套值的原始字节，不解码其它任何值
+//
+// 返回的 Raw 与 data 共享底层数组，可以直接转发或者拼接到其它文档中
+func GetRaw(data []byte, path string) (Raw, error) {
+	return Default().getRaw(data, path)
+}
+
+// Has 判断 data 顶层的 map 中是否存在 key，找到 key 之前的值只跳过不解码，找到后立即返回。
+// key 按照原样比较，不使用路径语法；顶层值不是 map 时返回 TypeMismatch 错误
+func Has(data []byte, key string) (bool, error) {
+	return Default().has(data, key)
+}
+
+func (poc *Poculum) has(data []byte, key string) (bool, error) {
+	reader := bytes.NewReader(data)
+	h, err := readHeader(reader)
+	if err != nil {
+		return false, err
+	}
+	if !h.isMap() {
+		return false, newError(TypeMismatch, fmt.Sprintf("Has requires a map, got %s", typeName(h.typeByte)))
+	}
+	for i := 0; i < h.length; i++ {
+		k, err := poc.readKey(reader)
+		if err != nil {
+			return false, err
+		}
+		if k == key {
+			return true, nil
+		}
+		if err := poc.skipValue(reader, 1); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// GetMany 在一次遍历中取出多个路径所指向的值并解码，结果的键为路径。
+// 不存在的路径不出现在结果中；一个路径是另一个路径的前缀时两者都会返回。
+// 所有路径都找到后立即停止遍历，其余的值只跳过不解码
+func GetMany(data []byte, paths ...string) (map[string]any, error) {
+	return Default().getMany(data, paths)
+}
+
+// pathNode 路径树的节点
+type pathNode struct {
+	children map[string]*pathNode
+	path     string // 以该节点结尾的路径
+	terminal bool
+}
+
+// manyGetter 保存 GetMany 的状态
+type manyGetter struct {
+	poc       *Poculum
+	data      []byte
+	reader    *bytes.Reader
+	result    map[string]any
+	remaining int // 还没有找到的路径数
+}
+
+func (poc *Poculum) getMany(data []byte, paths []string) (map[string]any, error) {
+	root := &pathNode{}
+	g := &manyGetter{poc: poc, data: data, reader: bytes.NewReader(data), result: make(map[string]any, len(paths))}
+	for _, path := range paths {
+		node := root
+		for _, seg := range splitPath(path) {
+			if node.children == nil {
+				node.children = map[string]*pathNode{}
+			}
+			if node.children[seg] == nil {
+				node.children[seg] = &pathNode{}
+			}
+			node = node.children[seg]
+		}
+		if !node.terminal {
+			node.terminal, node.path = true, path
+			g.remaining++
+		}
+	}
+	if g.remaining == 0 {
+		return g.result, nil
+	}
+	if err := g.visit(root, 0); err != nil && err != errAllFound {
+		return nil, err
+	}
+	return g.result, nil
+}
+
+// errAllFound 所有路径都已经找到，用于提前结束遍历
+var errAllFound = errors.New("all paths found")
+
+func (g *manyGetter) offset() int {
+	return len(g.data) - g.reader.Len()
+}
+
+// visit 处理从当前位置开始的值，node 为该值对应的路径树节点
+func (g *manyGetter) visit(node *pathNode, depth int) error {
+	start := g.offset()
+	if len(node.children) == 0 {
+		if err := g.poc.skipValue(g.reader, depth); err != nil {
+			return err
+		}
+		return g.found(node, start, depth)
+	}
+	if depth > g.poc.maxRecursionDepth {
+		return newError(MaxRecursionDepth, "Maximum recursion depth exceeded while parsing nested structure")
+	}
+
+	h, err := readHeader(g.reader)
+	for err == nil && h.typeByte == typeExt {
+		// 与 GetRaw 相同，扩展类型按照其中的值查找
+		h, err = readHeader(g.reader)
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case h.isMap():
+		for i := 0; i < h.length; i++ {
+			key, err := g.poc.readKey(g.reader)
+			if err != nil {
+				return err
+			}
+			if err := g.child(node.children[key], depth); err != nil {
+				return err
+			}
+		}
+	case h.isList():
+		for i := 0; i < h.length; i++ {
+			if err := g.child(node.children[strconv.Itoa(i)], depth); err != nil {
+				return err
+			}
+		}
+	default:
+		// 标量没有子路径，回到开头跳过整个值
+		g.reader.Seek(int64(start), io.SeekStart)
+		if err := g.poc.skipValue(g.reader, depth); err != nil {
+			return err
+		}
+	}
+	return g.found(node, start, depth)
+}
+
+// child 处理容器中的一个元素，不在路径树中的元素被跳过
+func (g *manyGetter) child(node *pathNode, depth int) error {
+	if node == nil {
+		return g.poc.skipValue(g.reader, depth+1)
+	}
+	return g.visit(node, depth+1)
+}
+
+// found 解码以 node 结尾的路径所指向的值 data[start:当前位置]
+func (g *manyGetter) found(node *pathNode, start, depth int) error {
+	if !node.terminal {
+		return nil
+	}
+	if _, ok := g.result[node.path]; ok {
+		// map 中重复的键，与 GetRaw 相同使用第一次出现的值
+		return nil
+	}
+	value, err := g.poc.decodeValue(bytes.NewReader(g.data[start:g.offset()]), depth)
+	if err != nil {
+		return err
+	}
+	g.result[node.path] = g.poc.afterDecode(value)
+	if g.remaining--; g.remaining == 0 {
+		return errAllFound
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package poculum
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGetRaw(t *testing.T) {
+	data, err := DumpPoculum(map[string]any{
+		"user": map[string]any{
+			"name": "poc",
+			"tags": []any{"a", "b", []any{uint8(1)}},
+		},
+		"other": "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := GetRaw(data, "user.tags.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := DumpPoculum([]any{uint8(1)})
+	if !bytes.Equal(raw, want) {
+		t.Fatalf("got %x, want %x", raw, want)
+	}
+
+	if _, err := GetRaw(data, "user.missing"); err == nil {
+		t.Fatal("expected error for missing key")
+	}
+	if _, err := GetRaw(data, "user.tags.9"); err == nil {
+		t.Fatal("expected error for out of range index")
+	}
+	if _, err := GetRaw(data, "other.0"); err == nil {
+		t.Fatal("expected error when indexing into a string")
+	}
+}
+
+func TestHas(t *testing.T) {
+	data := MustDump(map[string]any{
+		"payload":  bytes.Repeat([]byte{1}, 1000),
+		"flags":    map[string]any{"beta": true},
+		"a.b":      1,
+		"disabled": nil,
+	})
+	for key, want := range map[string]bool{"flags": true, "a.b": true, "disabled": true, "beta": false, "missing": false} {
+		if got, err := Has(data, key); err != nil || got != want {
+			t.Fatalf("Has(%q) = %v, %v", key, got, err)
+		}
+	}
+	if _, err := Has(MustDump([]any{"flags"}), "flags"); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+	// 找到键之前遇到截断的数据时返回错误
+	if _, err := Has(MustDump(map[string]any{"payload": "x"})[:3], "flags"); err == nil {
+		t.Fatal("expected error for truncated data")
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	data := MustDump(map[string]any{
+		"id":   uint8(7),
+		"user": map[string]any{"name": "ann", "tags": []any{"a", "b"}},
+		"blob": bytes.Repeat([]byte{1}, 100),
+	})
+	got, err := GetMany(data, "id", "user.name", "user.tags.1", "user", "missing", "user.tags.9", "id.x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"id":          uint8(7),
+		"user.name":   "ann",
+		"user.tags.1": "b",
+		"user":        map[string]any{"name": "ann", "tags": []any{"a", "b"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v", got)
+	}
+
+	// 找到所有路径后不再读取剩余的数据
+	ordered, _ := NewPoculum(WithCanonical()).Dump(map[string]any{"a": 1, "z": "tail"})
+	if got, err := GetMany(ordered[:len(ordered)-2], "a"); err != nil || got["a"] != uint32(1) {
+		t.Fatalf("early stop: %v, %v", got, err)
+	}
+	if got, err := GetMany(MustDump([]any{1, 2}), ""); err != nil || !reflect.DeepEqual(got[""], []any{uint32(1), uint32(2)}) {
+		t.Fatalf("root: %v, %v", got, err)
+	}
+	if _, err := GetMany([]byte{0x71, 0x32, 'i'}, "id"); err == nil {
+		t.Fatal("expected error for truncated data")
+	}
+
+	// 重复的键不会被计为另一个路径
+	dup := []byte{
+		typeFixMapBase + 3,
+		typeFixStringBase + 1, 'a', typeUInt8, 1,
+		typeFixStringBase + 1, 'a', typeUInt8, 2,
+		typeFixStringBase + 1, 'b', typeUInt8, 3,
+	}
+	got, err = GetMany(dup, "a", "b")
+	if err != nil || !reflect.DeepEqual(got, map[string]any{"a": uint8(1), "b": uint8(3)}) {
+		t.Fatalf("duplicate keys: %v, %v", got, err)
+	}
+	if raw, err := GetRaw(dup, "a"); err != nil || MustLoad(raw) != got["a"] {
+		t.Fatalf("GetRaw = %x, %v, GetMany = %v", raw, err, got["a"])
+	}
+}
@@ -0,0 +1,60 @@
+package poculum
+
+import (
+	"context"
+	"io"
+)
+
+// EncodeFrom 使用默认实例把 ch 中的值写为帧
+func EncodeFrom(ctx context.Context, ch <-chan any, w io.Writer) error {
+	return Default().EncodeFrom(ctx, ch, w)
+}
+
+// EncodeFrom 从 ch 中逐个接收值并写为帧（格式见 FrameWriter），直到 ch 被关闭或者 ctx 被取消。
+// 写入完成后才接收下一个值，写入慢时生产者在发送处阻塞，形成背压。
+// 出错或者取消时立即返回，此后不再从 ch 接收，生产者应当同时监听 ctx 以免阻塞
+func (poc *Poculum) EncodeFrom(ctx context.Context, ch <-chan any, w io.Writer) error {
+	fw := poc.NewFrameWriter(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case v, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := fw.Write(v); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// DecodeTo 使用默认实例把帧解码后发送到 ch
+func DecodeTo(ctx context.Context, r io.Reader, ch chan<- any, opts ...FrameReaderOption) error {
+	return Default().DecodeTo(ctx, r, ch, opts...)
+}
+
+// DecodeTo 从 r 中逐帧读取并解码，把值发送到 ch，直到数据结束（返回 nil）、出错或者 ctx 被取消。
+// 返回前关闭 ch，消费者可以直接 range ch。opts 传递给 FrameReader，例如 WithResync。
+// 取消只在两帧之间以及发送时生效，不会打断正在进行的读取
+func (poc *Poculum) DecodeTo(ctx context.Context, r io.Reader, ch chan<- any, opts ...FrameReaderOption) error {
+	defer close(ch)
+	fr := poc.NewFrameReader(r, opts...)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var v any
+		if err := fr.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ch <- v:
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package poculum
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestEncodeFrom(t *testing.T) {
+	ch := make(chan any)
+	go func() {
+		defer close(ch)
+		for i := 0; i < 3; i++ {
+			ch <- uint8(i)
+		}
+	}()
+	var stream bytes.Buffer
+	if err := EncodeFrom(context.Background(), ch, &stream); err != nil {
+		t.Fatal(err)
+	}
+	fr := NewFrameReader(&stream)
+	for i := 0; i < 3; i++ {
+		var v uint8
+		if err := fr.Decode(&v); err != nil || v != uint8(i) {
+			t.Fatalf("frame %d: %v, %v", i, v, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := EncodeFrom(ctx, make(chan any), &stream); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDecodeTo(t *testing.T) {
+	var stream bytes.Buffer
+	fw := NewFrameWriter(&stream)
+	for i := 0; i < 3; i++ {
+		fw.Write(uint8(i))
+	}
+
+	ch := make(chan any)
+	errc := make(chan error, 1)
+	go func() { errc <- DecodeTo(context.Background(), &stream, ch) }()
+	var got []any
+	for v := range ch {
+		got = append(got, v)
+	}
+	if err := <-errc; err != nil || len(got) != 3 || got[2] != uint8(2) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+
+	var one bytes.Buffer
+	NewFrameWriter(&one).Write("x")
+	ctx, cancel := context.WithCancel(context.Background())
+	ch = make(chan any)
+	go func() { errc <- DecodeTo(ctx, &one, ch) }()
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -1,8 +1,9 @@
 package poculum
 
 import (
-	"fmt"
 	"math"
+	"reflect"
+	"time"
 )
 
 // 以下定义类型标识符常量，长度都是一个字节
@@ -50,6 +51,8 @@ const (
 	typeFalse = 0xA1
 	// typeUnkown = 0xA2 // 暂不使用
 	typeNil = 0xA3
+
+	typeExt = 0xB0 // 扩展类型，之后是一个字节的扩展标识以及一个值
 )
 
 // 安全限制常量
@@ -64,30 +67,102 @@ type Poculum struct {
 	maxRecursionDepth int
 	maxStringSize     int
 	maxContainerItems int
-}
+	maxInputSize      int  // 解码的数据的最大字节数，为 0 时不限制
+	trusted           bool // 可信配置，不检查 UTF-8 与集合中的重复元素
+	canonical         bool // 规范编码，map 的键按照字节序排列
+	compactFloats     bool // 可以无损转换为 float32 的 float64 按照 float32 编码
+	optimize          bool // 编码后再选择最小的表示
+	fixedInts         bool // int 与 uint 总是编码为 int64 与 uint64
+	driverValues      bool // 所有实现 driver.Valuer 与 sql.Scanner 的类型按照数据库的值处理
 
-// PoculumError 错误类型
-type PoculumError struct {
-	Type    string
-	Message string
-}
+	nilPolicy  NilPolicy // 编码 nil slice 与 nil map 的方式
+	emptyOnNil bool      // 解码时 nil 赋给 slice 与 map 得到空容器
+	strictNil  bool      // 解码时 nil 赋给不能为 nil 的目标时返回错误
 
-func (e *PoculumError) Error() string {
-	return fmt.Sprintf("%s: %s", e.Type, e.Message)
-}
+	saturateInts bool // 解码时超出整数字段范围的值取边界值
+	intBools     bool // 解码到 bool 时接受整数 0 与 1
+
+	enums    map[reflect.Type]*enumInfo                 // 通过 RegisterEnum 注册的枚举类型
+	encoders map[reflect.Type]func(any, *Encoder) error // 通过 RegisterEncoder 注册的编码函数
+	decoders map[reflect.Type]func(any) (any, error)    // 通过 RegisterDecoder 注册的解码函数
+
+	sparseRatio     float64 // 零值比例达到该值的 list 使用稀疏编码，为 0 时不使用
+	maxSparseLength int     // 解码时稀疏 list 还原后的最大长度，为 0 时使用默认值
+
+	timePolicy    TimePolicy    // 时间戳的时区处理策略
+	timePrecision time.Duration // 时间戳编码时四舍五入的精度，为 0 时保留纳秒
+
+	hooks     Hooks     // 编解码钩子
+	keyMapper KeyMapper // 解码时转换 map 的键
 
-// 错误构造函数
-func newError(errType, message string) *PoculumError {
-	return &PoculumError{Type: errType, Message: message}
+	fields   projection // 解码时保留的键，为 nil 时解码全部内容
+	foldKeys bool       // 解码到 struct 时键名不区分大小写
+
+	redactor    func(any) any // 脱敏函数，为 nil 时不脱敏
+	redactPaths projection    // 需要脱敏的路径
+
+	reporter func(DecodeReport) // 解码成功后报告数据的形状，为 nil 时不报告
+
+	numericList reflect.Type // 解码时数值 list 的元素统一转换的类型，为 nil 时不转换
+
+	noSparse bool // 对方不支持稀疏 list，见 handshake_poc.go
+	noPacked bool // 对方不支持位压缩的 []bool 与张量
 }
 
+// ConfigOption 配置 Poculum 的选项，在 NewPoculum 中使用
+type ConfigOption func(*Poculum)
+
 // NewPoculum 创建新的 Poculum 实例
-func NewPoculum() *Poculum {
-	return &Poculum{
+func NewPoculum(opts ...ConfigOption) *Poculum {
+	poc := &Poculum{
 		maxRecursionDepth: maxRecursionDepth,
 		maxStringSize:     maxStringSize,
 		maxContainerItems: maxContainerItems,
 	}
+	for _, opt := range opts {
+		opt(poc)
+	}
+	return poc
+}
+
+// 安全配置的限制
+const (
+	secureRecursionDepth = 64       // 最大嵌套深度
+	secureStringSize     = 16 << 20 // 字符串最大 16MB
+	secureContainerItems = 1 << 20  // list、map 最多 1M 个元素
+	secureInputSize      = 64 << 20 // 一次解码的数据最大 64MB
+)
+
+// NewPoculumSecure 创建适合解码不可信数据的实例，例如面向互联网的服务收到的请求，
+// 默认限制为：嵌套深度 64、字符串 16MB、list 与 map 1M 个元素、数据总大小 64MB。
+// opts 在安全限制之后应用，可以按需调整
+func NewPoculumSecure(opts ...ConfigOption) *Poculum {
+	poc := &Poculum{
+		maxRecursionDepth: secureRecursionDepth,
+		maxStringSize:     secureStringSize,
+		maxContainerItems: secureContainerItems,
+		maxInputSize:      secureInputSize,
+	}
+	for _, opt := range opts {
+		opt(poc)
+	}
+	return poc
+}
+
+// NewPoculumTrusted 创建用于内部高频路径的实例，要求编解码的两端都是本库并且数据可信：
+// 不校验字符串的 UTF-8 编码，不检查集合中的重复元素，字符串与 list、map 的大小不做限制。
+// 嵌套深度仍然使用默认限制。不要用于解码来自外部的数据
+func NewPoculumTrusted(opts ...ConfigOption) *Poculum {
+	poc := &Poculum{
+		maxRecursionDepth: maxRecursionDepth,
+		maxStringSize:     math.MaxInt,
+		maxContainerItems: math.MaxInt,
+		trusted:           true,
+	}
+	for _, opt := range opts {
+		opt(poc)
+	}
+	return poc
 }
 
 // WithLimits 创建具有自定义限制的 Poculum 实例
@@ -98,3 +173,92 @@ func WithLimits(maxRecursion, maxStringSize, maxContainerItems int) *Poculum {
 		maxContainerItems: maxContainerItems,
 	}
 }
+
+// WithMaxRecursionDepth 设置最大嵌套深度
+func WithMaxRecursionDepth(n int) ConfigOption {
+	return func(poc *Poculum) {
+		poc.maxRecursionDepth = n
+	}
+}
+
+// WithMaxStringSize 设置字符串的最大字节数
+func WithMaxStringSize(n int) ConfigOption {
+	return func(poc *Poculum) {
+		poc.maxStringSize = n
+	}
+}
+
+// WithMaxContainerItems 设置 list 与 map 的最大元素个数
+func WithMaxContainerItems(n int) ConfigOption {
+	return func(poc *Poculum) {
+		poc.maxContainerItems = n
+	}
+}
+
+// WithMaxInputSize 设置一次解码的数据的最大字节数，为 0 时不限制
+func WithMaxInputSize(n int) ConfigOption {
+	return func(poc *Poculum) {
+		poc.maxInputSize = n
+	}
+}
+
+// WithCanonical 使用规范编码：map 按照键排序（集合总是按照元素编码后的字节排序），
+// 相同的值总是得到相同的字节，可以用于计算哈希与去重
+func WithCanonical() ConfigOption {
+	return func(poc *Poculum) {
+		poc.canonical = true
+	}
+}
+
+// WithFixedIntWidth 把 Go 的 int 与 uint 总是编码为 int64 与 uint64，而不是根据值的大小选择 32 位或者 64 位，
+// 同一个值在任何平台与取值范围内都得到相同的字节，适合与 WithCanonical 一起用于计算哈希。
+// int8、uint16 等定宽类型不受影响。WithOptimize 仍然会缩小整数的宽度
+func WithFixedIntWidth() ConfigOption {
+	return func(poc *Poculum) {
+		poc.fixedInts = true
+	}
+}
+
+// WithCompactFloats 把可以无损转换为 float32 的 float64 按照 float32 编码，每个值节省 4 个字节，
+// 适合大部分是低精度浮点数的传感器数据。NaN 的载荷可能改变，所以总是按照 float64 编码。
+// Load 对这些值返回 float32，Unmarshal 到 float64 时得到与原来完全相同的值
+func WithCompactFloats() ConfigOption {
+	return func(poc *Poculum) {
+		poc.compactFloats = true
+	}
+}
+
+// With 复制当前实例并应用 opts，返回派生的实例，当前实例不受影响。
+// 已经注册的枚举、编码函数与解码函数会被复制，之后在派生实例上的注册不会影响当前实例
+func (poc *Poculum) With(opts ...ConfigOption) *Poculum {
+	derived := *poc
+	derived.enums = cloneMap(poc.enums)
+	derived.encoders = cloneMap(poc.encoders)
+	derived.decoders = cloneMap(poc.decoders)
+	for _, opt := range opts {
+		opt(&derived)
+	}
+	return &derived
+}
+
+// cloneMap 复制 map，nil 仍然返回 nil
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[K]V, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// Dump 把值序列化为字节数组
+func (poc *Poculum) Dump(value any) ([]byte, error) {
+	return poc.dump(value)
+}
+
+// Load 从字节数组反序列化值
+func (poc *Poculum) Load(data []byte) (any, error) {
+	return poc.load(data)
+}
@@ -0,0 +1,490 @@
+// Package poculumarrow 在 poculum 的表格数据与 Apache Arrow 的列式内存布局之间转换，不依赖 Arrow 的库
+/*
+	Column 中的缓冲区与 Arrow 列式格式的规范一致（有效位图按照最低位优先，定长值与偏移使用小端序），
+可以不复制地交给 Arrow 的 Go 实现：
+
+	batch, err := poculumarrow.FromRows(data)
+	for _, col := range batch.Columns {
+		bufs := make([]*memory.Buffer, 0, 3)
+		for _, b := range col.Buffers() {
+			bufs = append(bufs, memory.NewBufferBytes(b))
+		}
+		arr := array.MakeFromData(array.NewData(arrowType(col.Type), col.Len, bufs, nil, col.NullCount, 0))
+		...
+	}
+
+支持两种 poculum 的数据形状：
+
+	行     元素为 map 的 list，列为所有 map 的键的并集，按照第一次出现的顺序排列，缺少的键视为 null
+	列     键为列名、值为 list 或者一维张量的 map，列按照列名排序，张量（打包的数值数组）不复制数据
+
+列的类型根据值推断：布尔值为 Bool，字符串为 String，字节数据为 Binary，整数为 Int64（超出 int64 的无符号整数为 Uint64），
+含有浮点数时为 Float64（全部为 float32 时为 Float32），张量使用对应的元素类型。nil 为 null，其它类型或者混合的类型返回错误
+*/
+package poculumarrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// Type 列的类型，名称与 Arrow 的类型对应
+type Type uint8
+
+const (
+	Null Type = iota // 全部为 null 的列
+	Bool
+	Int8
+	Int16
+	Int32
+	Int64
+	Uint8
+	Uint16
+	Uint32
+	Uint64
+	Float32
+	Float64
+	String // 使用 int32 偏移的 UTF-8 字符串
+	Binary // 使用 int32 偏移的字节数据
+)
+
+var typeNames = [...]string{"null", "bool", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64", "float32", "float64", "utf8", "binary"}
+
+func (t Type) String() string {
+	if int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return fmt.Sprintf("Type(%d)", uint8(t))
+}
+
+// width 返回定长类型的字节数，Bool、String、Binary 与 Null 返回 0
+func (t Type) width() int {
+	switch t {
+	case Int8, Uint8:
+		return 1
+	case Int16, Uint16:
+		return 2
+	case Int32, Uint32, Float32:
+		return 4
+	case Int64, Uint64, Float64:
+		return 8
+	}
+	return 0
+}
+
+// dtypes 张量元素类型与列类型的对应关系
+var dtypes = map[poculum.DType]Type{
+	poculum.DTypeInt8: Int8, poculum.DTypeInt16: Int16, poculum.DTypeInt32: Int32, poculum.DTypeInt64: Int64,
+	poculum.DTypeUInt8: Uint8, poculum.DTypeUInt16: Uint16, poculum.DTypeUInt32: Uint32, poculum.DTypeUInt64: Uint64,
+	poculum.DTypeFloat32: Float32, poculum.DTypeFloat64: Float64,
+}
+
+// Column 一列数据，缓冲区使用 Arrow 的内存布局
+type Column struct {
+	Name      string
+	Type      Type
+	Len       int
+	NullCount int
+	Validity  []byte // 有效位图，第 i 位为 1 表示第 i 个值不是 null，没有 null 时为 nil
+	Offsets   []byte // String 与 Binary 的 Len+1 个小端序 int32 偏移，其它类型为 nil
+	Values    []byte // 定长类型为小端序的值，Bool 为位图，String 与 Binary 为拼接的数据
+}
+
+// Buffers 按照 Arrow 的顺序返回缓冲区：有效位图、偏移（只有 String 与 Binary）、值。Null 类型没有缓冲区
+func (c *Column) Buffers() [][]byte {
+	switch c.Type {
+	case Null:
+		return nil
+	case String, Binary:
+		return [][]byte{c.Validity, c.Offsets, c.Values}
+	}
+	return [][]byte{c.Validity, c.Values}
+}
+
+// IsNull 判断第 i 个值是否是 null
+func (c *Column) IsNull(i int) bool {
+	return c.Type == Null || c.Validity != nil && c.Validity[i/8]&(1<<(i%8)) == 0
+}
+
+// Value 返回第 i 个值，null 返回 nil，整数与浮点数返回对应宽度的 Go 类型
+func (c *Column) Value(i int) any {
+	if c.IsNull(i) {
+		return nil
+	}
+	if w := c.Type.width(); w > 0 {
+		b := c.Values[i*w : (i+1)*w]
+		switch c.Type {
+		case Int8:
+			return int8(b[0])
+		case Int16:
+			return int16(binary.LittleEndian.Uint16(b))
+		case Int32:
+			return int32(binary.LittleEndian.Uint32(b))
+		case Int64:
+			return int64(binary.LittleEndian.Uint64(b))
+		case Uint8:
+			return b[0]
+		case Uint16:
+			return binary.LittleEndian.Uint16(b)
+		case Uint32:
+			return binary.LittleEndian.Uint32(b)
+		case Uint64:
+			return binary.LittleEndian.Uint64(b)
+		case Float32:
+			return math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case Float64:
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+	}
+	switch c.Type {
+	case Bool:
+		return c.Values[i/8]&(1<<(i%8)) != 0
+	case String, Binary:
+		start := binary.LittleEndian.Uint32(c.Offsets[i*4:])
+		end := binary.LittleEndian.Uint32(c.Offsets[(i+1)*4:])
+		if c.Type == String {
+			return string(c.Values[start:end])
+		}
+		return c.Values[start:end:end]
+	}
+	return nil
+}
+
+// Batch 一组行数相同的列，对应 Arrow 的 record batch
+type Batch struct {
+	Rows    int
+	Columns []Column
+}
+
+// Column 返回名称为 name 的列，不存在时返回 nil
+func (b *Batch) Column(name string) *Column {
+	for i := range b.Columns {
+		if b.Columns[i].Name == name {
+			return &b.Columns[i]
+		}
+	}
+	return nil
+}
+
+// FromRows 把元素为 map 的 list 转换为列
+func FromRows(data []byte) (*Batch, error) {
+	var rows []map[string]any
+	if err := poculum.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	index := map[string]int{}
+	for _, row := range rows {
+		// 同一行中新出现的键按照键排序，使结果不受 map 遍历顺序的影响
+		var added []string
+		for key := range row {
+			if _, ok := index[key]; !ok {
+				added = append(added, key)
+			}
+		}
+		sort.Strings(added)
+		for _, key := range added {
+			index[key] = len(names)
+			names = append(names, key)
+		}
+	}
+
+	batch := &Batch{Rows: len(rows), Columns: make([]Column, len(names))}
+	values := make([]any, len(rows))
+	for i, name := range names {
+		for r, row := range rows {
+			values[r] = row[name]
+		}
+		col, err := buildColumn(name, values)
+		if err != nil {
+			return nil, err
+		}
+		batch.Columns[i] = col
+	}
+	return batch, nil
+}
+
+// FromColumns 把键为列名、值为 list 或者一维张量的 map 转换为列，所有列的长度必须相同
+func FromColumns(data []byte) (*Batch, error) {
+	var columns map[string]any
+	if err := poculum.Unmarshal(data, &columns); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	batch := &Batch{Columns: make([]Column, len(names))}
+	for i, name := range names {
+		var col Column
+		var err error
+		switch v := columns[name].(type) {
+		case *poculum.Tensor:
+			col, err = tensorColumn(name, v)
+		case []any:
+			col, err = buildColumn(name, v)
+		default:
+			err = fmt.Errorf("column %q: expected list or tensor, got %T", name, v)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && col.Len != batch.Rows {
+			return nil, fmt.Errorf("column %q has %d rows, expected %d", name, col.Len, batch.Rows)
+		}
+		batch.Rows = col.Len
+		batch.Columns[i] = col
+	}
+	return batch, nil
+}
+
+// tensorColumn 把一维张量转换为列，数据不复制
+func tensorColumn(name string, t *poculum.Tensor) (Column, error) {
+	typ, ok := dtypes[t.DType]
+	if !ok || len(t.Shape) != 1 {
+		return Column{}, fmt.Errorf("column %q: expected a one-dimensional numeric tensor", name)
+	}
+	return Column{Name: name, Type: typ, Len: t.Shape[0], Values: t.Data}, nil
+}
+
+// inferType 推断列的类型
+func inferType(name string, values []any) (Type, error) {
+	typ := Null
+	merge := func(t Type) error {
+		switch {
+		case typ == Null || typ == t:
+			typ = t
+		case isNumber(typ) && isNumber(t):
+			typ = widen(typ, t)
+		default:
+			return fmt.Errorf("column %q mixes %s and %s values", name, typ, t)
+		}
+		return nil
+	}
+
+	negative := false
+	for _, v := range values {
+		var t Type
+		switch v := v.(type) {
+		case nil:
+			continue
+		case bool:
+			t = Bool
+		case string:
+			t = String
+		case []byte:
+			t = Binary
+		case float32:
+			t = Float32
+		case float64:
+			t = Float64
+		case uint64:
+			t = Int64
+			if v > math.MaxInt64 {
+				t = Uint64
+			}
+		case int8, int16, int32, int64:
+			t = Int64
+			n, _ := toInt64(v)
+			negative = negative || n < 0
+		case uint8, uint16, uint32:
+			t = Int64
+		default:
+			return Null, fmt.Errorf("column %q: unsupported value type %T", name, v)
+		}
+		if err := merge(t); err != nil {
+			return Null, err
+		}
+	}
+	if typ == Uint64 && negative {
+		return Null, fmt.Errorf("column %q mixes negative integers and integers above int64", name)
+	}
+	return typ, nil
+}
+
+func isNumber(t Type) bool {
+	return t == Int64 || t == Uint64 || t == Float32 || t == Float64
+}
+
+// widen 返回能同时表示两种数值类型的类型
+func widen(a, b Type) Type {
+	switch {
+	case a == Float64 || b == Float64 || a == Float32 || b == Float32:
+		if a == Float32 && b == Float32 {
+			return Float32
+		}
+		return Float64
+	case a == Uint64 || b == Uint64:
+		return Uint64
+	}
+	return Int64
+}
+
+// toInt64 把有符号整数转换为 int64
+func toInt64(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+	return 0, false
+}
+
+// buildColumn 根据推断的类型把值写入 Arrow 的缓冲区
+func buildColumn(name string, values []any) (Column, error) {
+	typ, err := inferType(name, values)
+	if err != nil {
+		return Column{}, err
+	}
+	col := Column{Name: name, Type: typ, Len: len(values)}
+	if typ == Null {
+		col.NullCount = len(values)
+		return col, nil
+	}
+
+	validity := make([]byte, (len(values)+7)/8)
+	var offsets, out []byte
+	switch {
+	case typ == Bool:
+		out = make([]byte, (len(values)+7)/8)
+	case typ == String || typ == Binary:
+		offsets = binary.LittleEndian.AppendUint32(make([]byte, 0, 4*(len(values)+1)), 0)
+	default:
+		out = make([]byte, 0, typ.width()*len(values))
+	}
+
+	for i, v := range values {
+		if v == nil {
+			col.NullCount++
+		} else {
+			validity[i/8] |= 1 << (i % 8)
+		}
+		switch typ {
+		case Bool:
+			if v == true {
+				out[i/8] |= 1 << (i % 8)
+			}
+		case String, Binary:
+			switch v := v.(type) {
+			case string:
+				out = append(out, v...)
+			case []byte:
+				out = append(out, v...)
+			}
+			if len(out) > math.MaxInt32 {
+				return Column{}, fmt.Errorf("column %q exceeds 2GB of data", name)
+			}
+			offsets = binary.LittleEndian.AppendUint32(offsets, uint32(len(out)))
+		case Float32:
+			f, _ := v.(float32)
+			out = binary.LittleEndian.AppendUint32(out, math.Float32bits(f))
+		case Float64:
+			out = binary.LittleEndian.AppendUint64(out, math.Float64bits(toFloat64(v)))
+		case Int64, Uint64:
+			out = binary.LittleEndian.AppendUint64(out, toUint64Bits(v))
+		}
+	}
+
+	col.Values, col.Offsets = out, offsets
+	if col.NullCount > 0 {
+		col.Validity = validity
+	}
+	return col, nil
+}
+
+// toFloat64 把数值转换为 float64，nil 得到 0
+func toFloat64(v any) float64 {
+	switch v := v.(type) {
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	n, _ := toInt64(v)
+	return float64(n)
+}
+
+// toUint64Bits 返回整数的 64 位补码表示，nil 得到 0
+func toUint64Bits(v any) uint64 {
+	switch v := v.(type) {
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case uint64:
+		return v
+	}
+	n, _ := toInt64(v)
+	return uint64(n)
+}
+
+// ToRows 把列编码为元素为 map 的 list，null 编码为 nil
+func (b *Batch) ToRows(poc *poculum.Poculum) ([]byte, error) {
+	rows := make([]any, b.Rows)
+	for r := range rows {
+		row := make(map[string]any, len(b.Columns))
+		for i := range b.Columns {
+			row[b.Columns[i].Name] = b.Columns[i].Value(r)
+		}
+		rows[r] = row
+	}
+	return instance(poc).Dump(rows)
+}
+
+// ToColumns 把列编码为键为列名的 map，没有 null 的数值列编码为一维张量，其它列编码为 list
+func (b *Batch) ToColumns(poc *poculum.Poculum) ([]byte, error) {
+	columns := make(map[string]any, len(b.Columns))
+	for i := range b.Columns {
+		col := &b.Columns[i]
+		if dtype, ok := tensorType(col.Type); ok && col.NullCount == 0 {
+			columns[col.Name] = &poculum.Tensor{DType: dtype, Shape: []int{col.Len}, Data: col.Values[:col.Len*col.Type.width()]}
+			continue
+		}
+		list := make([]any, col.Len)
+		for r := range list {
+			list[r] = col.Value(r)
+		}
+		columns[col.Name] = list
+	}
+	return instance(poc).Dump(columns)
+}
+
+// tensorType 返回列类型对应的张量元素类型
+func tensorType(t Type) (poculum.DType, bool) {
+	for dtype, typ := range dtypes {
+		if typ == t {
+			return dtype, true
+		}
+	}
+	return 0, false
+}
+
+// instance poc 为 nil 时返回默认实例
+func instance(poc *poculum.Poculum) *poculum.Poculum {
+	if poc == nil {
+		return poculum.Default()
+	}
+	return poc
+}
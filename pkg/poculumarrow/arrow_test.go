@@ -0,0 +1,103 @@
+package poculumarrow
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+func TestFromRows(t *testing.T) {
+	data := poculum.MustDump([]any{
+		map[string]any{"id": 1, "name": "a", "ok": true, "temp": 20.5},
+		map[string]any{"id": -2, "ok": false, "temp": float32(1)},
+		map[string]any{"id": 3, "name": "ccc", "raw": []byte{1, 2}},
+	})
+	batch, err := FromRows(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.Rows != 3 || len(batch.Columns) != 5 {
+		t.Fatalf("rows=%d columns=%d", batch.Rows, len(batch.Columns))
+	}
+
+	id := batch.Column("id")
+	if id.Type != Int64 || id.NullCount != 0 || id.Validity != nil || int64(binary.LittleEndian.Uint64(id.Values[8:])) != -2 {
+		t.Fatalf("id column: %+v", id)
+	}
+	name := batch.Column("name")
+	if name.Type != String || name.NullCount != 1 || name.Validity[0] != 0b101 || string(name.Values) != "accc" {
+		t.Fatalf("name column: %+v", name)
+	}
+	if len(name.Buffers()) != 3 || name.Value(1) != nil || name.Value(2) != "ccc" {
+		t.Fatalf("name values: %v %v", name.Value(1), name.Value(2))
+	}
+	if ok := batch.Column("ok"); ok.Type != Bool || ok.Values[0] != 0b001 || ok.Value(1) != false {
+		t.Fatalf("ok column: %+v", ok)
+	}
+	if temp := batch.Column("temp"); temp.Type != Float64 || temp.Value(1) != 1.0 || temp.Value(2) != nil {
+		t.Fatalf("temp column: %+v", temp)
+	}
+	if raw := batch.Column("raw"); raw.Type != Binary || !reflect.DeepEqual(raw.Value(2), []byte{1, 2}) {
+		t.Fatalf("raw column: %+v", raw)
+	}
+
+	if _, err := FromRows(poculum.MustDump([]any{map[string]any{"x": 1}, map[string]any{"x": "1"}})); err == nil {
+		t.Fatal("expected error for mixed column")
+	}
+}
+
+func TestColumnsRoundTrip(t *testing.T) {
+	values, err := poculum.NewTensorFloat32([]int{3}, []float32{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := poculum.MustDump(map[string]any{
+		"values": values,
+		"label":  []any{"x", nil, "z"},
+	})
+	batch, err := FromColumns(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.Rows != 3 || batch.Columns[0].Name != "label" || batch.Columns[1].Type != Float32 {
+		t.Fatalf("batch: %+v", batch)
+	}
+	if batch.Column("values").Value(2) != float32(3) {
+		t.Fatalf("values[2] = %v", batch.Column("values").Value(2))
+	}
+
+	encoded, err := batch.ToColumns(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var columns map[string]any
+	if err := poculum.Unmarshal(encoded, &columns); err != nil {
+		t.Fatal(err)
+	}
+	tensor, ok := columns["values"].(*poculum.Tensor)
+	if !ok {
+		t.Fatalf("values = %#v", columns["values"])
+	}
+	if floats, err := tensor.Float32s(); err != nil || !reflect.DeepEqual(floats, []float32{1, 2, 3}) {
+		t.Fatalf("values = %v, %v", floats, err)
+	}
+
+	rows, err := batch.ToRows(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := FromRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label := again.Column("label"); label.NullCount != 1 || label.Value(2) != "z" {
+		t.Fatalf("label column: %+v", label)
+	}
+
+	bad := poculum.MustDump(map[string]any{"a": []any{1}, "b": []any{1, 2}})
+	if _, err := FromColumns(bad); err == nil {
+		t.Fatal("expected error for columns of different lengths")
+	}
+}
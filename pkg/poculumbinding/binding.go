@@ -0,0 +1,72 @@
+// Package poculumbinding 把 poculum 编码的 HTTP 请求体解码到 struct 中，不依赖 Web 框架
+/*
+	Binding 实现了 Gin 的 binding.Binding 与 binding.BindingBody 接口：
+
+	if err := c.ShouldBindWith(&req, poculumbinding.Binding); err != nil { ... }
+
+Echo 中使用 Bind：
+
+	if err := poculumbinding.Bind(c.Request(), &req); err != nil { ... }
+
+请求体使用 poculum.NewPoculumSecure 的限制解码，struct 标签中的 required、min、max 等规则同样生效。
+Gin 的 validate 标签不会被检查，需要时在绑定后调用 binding.Validator.ValidateStruct
+*/
+package poculumbinding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// MaxBodySize 请求体的最大字节数
+var MaxBodySize int64 = 64 << 20
+
+// ErrContentType 请求的 Content-Type 不是 poculum
+var ErrContentType = errors.New("poculumbinding: content type is not " + poculum.ContentTypePoculum)
+
+// secure 解码请求体使用的实例
+var secure = poculum.NewPoculumSecure()
+
+// Binding 绑定 poculum 请求体
+var Binding = poculumBinding{}
+
+type poculumBinding struct{}
+
+// Name 返回绑定的名称
+func (poculumBinding) Name() string {
+	return "poculum"
+}
+
+// Bind 读取请求体并解码到 obj 中
+func (poculumBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("poculumbinding: invalid request")
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, MaxBodySize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(body)) > MaxBodySize {
+		return fmt.Errorf("poculumbinding: request body exceeds %d bytes", MaxBodySize)
+	}
+	return secure.Unmarshal(body, obj)
+}
+
+// BindBody 把已经读取的请求体解码到 obj 中
+func (poculumBinding) BindBody(body []byte, obj any) error {
+	return secure.Unmarshal(body, obj)
+}
+
+// Bind 检查 Content-Type 后读取请求体并解码到 obj 中，Content-Type 不是 poculum 时返回 ErrContentType
+func Bind(req *http.Request, obj any) error {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != poculum.ContentTypePoculum {
+		return ErrContentType
+	}
+	return Binding.Bind(req, obj)
+}
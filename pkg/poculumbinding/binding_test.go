@@ -0,0 +1,43 @@
+package poculumbinding
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+	"github.com/shinyes/poculum-go/pkg/poculumrender"
+)
+
+func TestRenderAndBind(t *testing.T) {
+	type item struct {
+		Name string `poculum:"name,required"`
+	}
+
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(201)
+	if err := poculumrender.New(item{Name: "pen"}).Render(rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Header().Get("Content-Type") != poculum.ContentTypePoculum {
+		t.Fatalf("content type %q", rec.Header().Get("Content-Type"))
+	}
+
+	req := httptest.NewRequest("POST", "/items", bytes.NewReader(rec.Body.Bytes()))
+	req.Header.Set("Content-Type", poculum.ContentTypePoculum+"; charset=binary")
+	var got item
+	if err := Bind(req, &got); err != nil || got.Name != "pen" {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+
+	if err := Binding.BindBody(poculum.MustDump(map[string]any{}), &got); !poculum.IsKind(err, poculum.ValidationError) {
+		t.Fatalf("expected required field error, got %v", err)
+	}
+
+	req = httptest.NewRequest("POST", "/items", bytes.NewReader(rec.Body.Bytes()))
+	req.Header.Set("Content-Type", "application/json")
+	if err := Bind(req, &got); !errors.Is(err, ErrContentType) {
+		t.Fatalf("expected ErrContentType, got %v", err)
+	}
+}
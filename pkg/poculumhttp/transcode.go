@@ -0,0 +1,198 @@
+// Package poculumhttp 在 HTTP 层转换 JSON 与 poculum，不依赖 Web 框架
+/*
+	公开的 API 迁移到 poculum 期间，Transcode 中间件让内部的 handler 只处理 poculum，
+仍然使用 JSON 的客户端不需要修改：
+
+	Content-Type 为 application/json 的请求体转换为 poculum，Content-Type 改为 application/poculum
+	客户端需要 JSON 时，poculum 的响应体转换为 JSON，Content-Type 改为 application/json
+
+客户端需要 JSON 是指 Accept 中有 application/json 而没有 application/poculum，
+或者没有 Accept（以及 Accept 为 *\/*）而请求体是 JSON。
+
+	mux := http.NewServeMux()
+	mux.Handle("/orders", ordersHandler)
+	http.ListenAndServe(":8080", poculumhttp.Transcode(mux))
+
+需要转换的响应会先完整地缓存在内存中，流式的响应不应该经过这个中间件
+*/
+package poculumhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// ContentTypeJSON JSON 的 Content-Type
+const ContentTypeJSON = "application/json"
+
+// Option 配置 Transcode
+type Option func(*transcoder)
+
+// WithPoculum 使用 poc 转换，默认使用 poculum.NewPoculumSecure()，因为请求体来自客户端
+func WithPoculum(poc *poculum.Poculum) Option {
+	return func(t *transcoder) {
+		t.poc = poc
+	}
+}
+
+// WithMaxBodySize 设置 JSON 请求体的最大字节数，默认 64MB，超过时返回 413
+func WithMaxBodySize(n int64) Option {
+	return func(t *transcoder) {
+		t.maxBodySize = n
+	}
+}
+
+type transcoder struct {
+	next        http.Handler
+	poc         *poculum.Poculum
+	maxBodySize int64
+}
+
+// Transcode 返回在 next 前后转换 JSON 与 poculum 的 handler
+func Transcode(next http.Handler, opts ...Option) http.Handler {
+	t := &transcoder{next: next, poc: poculum.NewPoculumSecure(), maxBodySize: 64 << 20}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *transcoder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	jsonBody := mediaType(r.Header.Get("Content-Type")) == ContentTypeJSON
+	if jsonBody && r.Body != nil && r.Body != http.NoBody {
+		data, status, err := t.requestToPoculum(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		r.ContentLength = int64(len(data))
+		r.Header.Set("Content-Type", poculum.ContentTypePoculum)
+		r.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	}
+
+	if !wantsJSON(r.Header.Get("Accept"), jsonBody) {
+		t.next.ServeHTTP(w, r)
+		return
+	}
+	rec := &recorder{header: http.Header{}}
+	t.next.ServeHTTP(rec, r)
+	t.writeResponse(w, rec)
+}
+
+// requestToPoculum 把 JSON 请求体转换为 poculum，出错时返回对应的状态码
+func (t *transcoder) requestToPoculum(body io.Reader) ([]byte, int, error) {
+	data, err := io.ReadAll(io.LimitReader(body, t.maxBodySize+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if int64(len(data)) > t.maxBodySize {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", t.maxBodySize)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err)
+	}
+	if dec.More() {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid JSON body: trailing data")
+	}
+	out, err := t.poc.Dump(v)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return out, 0, nil
+}
+
+// writeResponse 把缓存的响应写入 w，poculum 的响应体转换为 JSON
+func (t *transcoder) writeResponse(w http.ResponseWriter, rec *recorder) {
+	header := w.Header()
+	for k, v := range rec.header {
+		header[k] = v
+	}
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	body := rec.body.Bytes()
+
+	if mediaType(rec.header.Get("Content-Type")) == poculum.ContentTypePoculum && len(body) > 0 {
+		v, err := t.poc.Load(body)
+		if err == nil {
+			body, err = json.Marshal(v)
+		}
+		if err != nil {
+			for k := range header {
+				delete(header, k)
+			}
+			http.Error(w, "cannot transcode response: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		header.Set("Content-Type", ContentTypeJSON)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	header.Add("Vary", "Accept")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// wantsJSON 判断客户端是否需要 JSON 的响应
+func wantsJSON(accept string, jsonBody bool) bool {
+	accept = strings.TrimSpace(accept)
+	if accept == "" || accept == "*/*" {
+		return jsonBody
+	}
+	jsonOK, pocOK := false, false
+	for _, part := range strings.Split(accept, ",") {
+		switch mediaType(part) {
+		case ContentTypeJSON:
+			jsonOK = true
+		case poculum.ContentTypePoculum:
+			pocOK = true
+		}
+	}
+	return jsonOK && !pocOK
+}
+
+// mediaType 返回不带参数的 media type，无法解析时返回空字符串
+func mediaType(s string) string {
+	mt, _, err := mime.ParseMediaType(s)
+	if err != nil {
+		return ""
+	}
+	return mt
+}
+
+// recorder 缓存 handler 写入的响应
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(p)
+}
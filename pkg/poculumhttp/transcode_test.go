@@ -0,0 +1,61 @@
+package poculumhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+func TestTranscode(t *testing.T) {
+	type order struct {
+		ID    uint64   `poculum:"id"`
+		Items []string `poculum:"items"`
+	}
+	handler := Transcode(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != poculum.ContentTypePoculum {
+			t.Errorf("handler got content type %q", r.Header.Get("Content-Type"))
+		}
+		var o order
+		if err := poculum.NewPoculumSecure().NewDecoder(r.Body).Decode(&o); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		o.Items = append(o.Items, "receipt")
+		data, _ := poculum.DumpPoculum(o)
+		w.Header().Set("Content-Type", poculum.ContentTypePoculum)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(data)
+	}))
+
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"id": 18446744073709551615, "items": ["pen"]}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated || rec.Header().Get("Content-Type") != ContentTypeJSON {
+		t.Fatalf("got %d %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	if got := rec.Body.String(); got != `{"id":18446744073709551615,"items":["pen","receipt"]}` {
+		t.Fatalf("got body %s", got)
+	}
+
+	// 需要 poculum 的客户端得到原样的响应
+	req = httptest.NewRequest("POST", "/orders", strings.NewReader(`{"id": 1}`))
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	req.Header.Set("Accept", poculum.ContentTypePoculum)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Type") != poculum.ContentTypePoculum {
+		t.Fatalf("got content type %q", rec.Header().Get("Content-Type"))
+	}
+
+	req = httptest.NewRequest("POST", "/orders", strings.NewReader(`{"id": `))
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid JSON got %d", rec.Code)
+	}
+}
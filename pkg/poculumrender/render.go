@@ -0,0 +1,65 @@
+// Package poculumrender 把值编码为 poculum 写入 HTTP 响应，不依赖 Web 框架
+/*
+	Poculum 实现了 Gin 的 render.Render 接口：
+
+	c.Render(http.StatusOK, poculumrender.New(obj))
+
+Echo 没有对应的接口，使用 Echo 函数，它只需要 echo.Context 的 Blob 方法：
+
+	return poculumrender.Echo(c, http.StatusOK, obj)
+*/
+package poculumrender
+
+import (
+	"net/http"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// Poculum 把 Data 编码为 poculum 的响应
+type Poculum struct {
+	Data any
+	Poc  *poculum.Poculum // 为 nil 时使用 poculum.Default()
+}
+
+// New 创建使用默认实例编码 obj 的响应
+func New(obj any) Poculum {
+	return Poculum{Data: obj}
+}
+
+// Render 编码 Data 并写入 w，编码失败时不写入任何内容
+func (r Poculum) Render(w http.ResponseWriter) error {
+	poc := r.Poc
+	if poc == nil {
+		poc = poculum.Default()
+	}
+	data, err := poc.Dump(r.Data)
+	if err != nil {
+		return err
+	}
+	r.WriteContentType(w)
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteContentType 设置 Content-Type 响应头
+func (r Poculum) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", poculum.ContentTypePoculum)
+	}
+}
+
+// Blobber 可以写入完整响应体的上下文，例如 echo.Context
+type Blobber interface {
+	Blob(code int, contentType string, b []byte) error
+}
+
+// Echo 使用默认实例编码 obj，以状态码 code 写入响应
+func Echo(c Blobber, code int, obj any) error {
+	data, err := poculum.DumpPoculum(obj)
+	if err != nil {
+		return err
+	}
+	return c.Blob(code, poculum.ContentTypePoculum, data)
+}
@@ -0,0 +1,83 @@
+package poculumrender
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+func TestRender(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := New(map[string]any{"id": uint8(7)}).Render(w); err != nil {
+		t.Fatal(err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != poculum.ContentTypePoculum {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var got map[string]any
+	if err := poculum.Unmarshal(w.Body.Bytes(), &got); err != nil || got["id"] != uint8(7) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+
+	// 使用指定的实例，编码失败时不写入任何内容
+	w = httptest.NewRecorder()
+	r := Poculum{Data: make(chan int), Poc: poculum.NewPoculumSecure()}
+	if err := r.Render(w); err == nil {
+		t.Fatal("expected error for unsupported type")
+	}
+	if w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Fatalf("failed Render wrote %q with headers %v", w.Body.Bytes(), w.Header())
+	}
+}
+
+func TestWriteContentType(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(nil).WriteContentType(w)
+	if ct := w.Header().Get("Content-Type"); ct != poculum.ContentTypePoculum {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	// 已经设置的 Content-Type 不被覆盖
+	w = httptest.NewRecorder()
+	w.Header().Set("Content-Type", "application/x-custom")
+	New(nil).WriteContentType(w)
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-custom" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+}
+
+// blobRecorder 记录 Blob 的参数，模仿 echo.Context
+type blobRecorder struct {
+	code        int
+	contentType string
+	body        []byte
+}
+
+func (b *blobRecorder) Blob(code int, contentType string, body []byte) error {
+	b.code, b.contentType, b.body = code, contentType, body
+	return nil
+}
+
+func TestEcho(t *testing.T) {
+	var c blobRecorder
+	if err := Echo(&c, http.StatusCreated, []any{"a", uint8(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if c.code != http.StatusCreated || c.contentType != poculum.ContentTypePoculum {
+		t.Fatalf("Blob(%d, %q)", c.code, c.contentType)
+	}
+	var got []any
+	if err := poculum.Unmarshal(c.body, &got); err != nil || len(got) != 2 || got[0] != "a" {
+		t.Fatalf("got %v, %v", got, err)
+	}
+
+	c = blobRecorder{}
+	if err := Echo(&c, http.StatusOK, make(chan int)); err == nil || c.body != nil {
+		t.Fatalf("expected error without Blob call, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package poculumrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// ErrClosed 客户端已经关闭，或者连接已经断开
+var ErrClosed = errors.New("poculumrpc: client closed")
+
+// Client RPC 客户端，可以被多个 goroutine 同时使用
+type Client struct {
+	poc  *poculum.Poculum
+	conn io.ReadWriteCloser
+
+	wmu sync.Mutex
+	fw  *poculum.FrameWriter
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan poculum.Raw
+	err     error // 连接断开的原因，不为 nil 时不再发送请求
+}
+
+// NewClient 创建通过 conn 发送请求的客户端，使用 poculum.NewPoculumSecure 的限制解码响应，opts 可以调整
+func NewClient(conn io.ReadWriteCloser, opts ...poculum.ConfigOption) *Client {
+	poc := poculum.NewPoculumSecure(opts...)
+	c := &Client{
+		poc:     poc,
+		conn:    conn,
+		fw:      poc.NewFrameWriter(conn),
+		pending: make(map[uint64]chan poculum.Raw),
+	}
+	go c.readLoop()
+	return c
+}
+
+// Call 调用 method 并等待响应，params 编码为 map（例如 struct 或者 map[string]any），可以为 nil。
+// 响应中的 result 解码到 result 中，result 为 nil 时忽略；服务端返回错误时返回 *Error。
+// ctx 被取消时不再等待响应，服务端仍然可能执行该请求
+func (c *Client) Call(ctx context.Context, method string, params, result any) error {
+	ch := make(chan poculum.Raw, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	data, err := encodeRequest(c.poc, id, method, params)
+	if err == nil {
+		c.wmu.Lock()
+		err = c.fw.WriteRaw(data)
+		c.wmu.Unlock()
+	}
+	if err != nil {
+		c.forget(id)
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		c.forget(id)
+		return ctx.Err()
+	case msg, ok := <-ch:
+		if !ok {
+			return c.closedErr()
+		}
+		return c.decodeResponse(msg, result)
+	}
+}
+
+// decodeResponse 解码响应中的 error 或者 result
+func (c *Client) decodeResponse(msg poculum.Raw, result any) error {
+	raw, ok, err := field(msg, "error")
+	if err != nil {
+		return err
+	}
+	if ok {
+		var rpcErr Error
+		if err := c.poc.Unmarshal(raw, &rpcErr); err != nil {
+			return fmt.Errorf("poculumrpc: invalid error object: %w", err)
+		}
+		return &rpcErr
+	}
+	if result == nil {
+		return nil
+	}
+	raw, ok, err = field(msg, "result")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("poculumrpc: response has neither result nor error")
+	}
+	return c.poc.Unmarshal(raw, result)
+}
+
+// forget 删除未完成的请求
+func (c *Client) forget(id uint64) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// closedErr 返回连接断开的原因
+func (c *Client) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// readLoop 读取响应并交给对应的请求，连接断开后让所有未完成的请求返回错误
+func (c *Client) readLoop() {
+	fr := c.poc.NewFrameReader(c.conn)
+	var err error
+	for {
+		var msg poculum.Raw
+		if msg, err = fr.ReadRaw(); err != nil {
+			break
+		}
+		id, idErr := messageID(c.poc, msg)
+		if idErr != nil {
+			// 无法对应到请求的响应被忽略
+			continue
+		}
+		c.mu.Lock()
+		ch := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+		if ch != nil {
+			ch <- msg
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		if err == io.EOF {
+			c.err = ErrClosed
+		} else {
+			c.err = fmt.Errorf("%w: %v", ErrClosed, err)
+		}
+	}
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
+
+// Close 关闭连接，未完成的请求返回 ErrClosed
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = ErrClosed
+	}
+	c.mu.Unlock()
+	return c.conn.Close()
+}
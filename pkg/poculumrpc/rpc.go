@@ -0,0 +1,92 @@
+// Package poculumrpc 是基于 poculum 帧的请求/响应 RPC，可以与其他语言的实现互通，不需要 gRPC
+/*
+	连接上的每个消息都是一帧（见 poculum.FrameWriter），帧中的值为 map：
+
+	请求  {"id": 整数, "method": 字符串, "params": map 或者 nil}
+	响应  {"id": 整数, "result": 任意值}
+	      {"id": 整数, "error": {"code": 整数, "message": 字符串}}
+
+id 由客户端分配，响应按照 id 与请求对应，同一个连接上可以同时有多个未完成的请求，
+服务端并发处理同一个连接上的请求，响应的顺序与请求的顺序无关。错误码沿用 JSON-RPC 2.0 的约定
+
+	srv := poculumrpc.NewServer()
+	srv.Handle("add", func(ctx context.Context, params poculum.Raw) (any, error) {
+		var p struct{ A, B int }
+		if err := poculum.Unmarshal(params, &p); err != nil {
+			return nil, poculumrpc.Errorf(poculumrpc.CodeInvalidParams, "%v", err)
+		}
+		return p.A + p.B, nil
+	})
+	go srv.Serve(ctx, listener)
+
+	client := poculumrpc.NewClient(conn)
+	var sum int
+	err := client.Call(ctx, "add", map[string]any{"A": 1, "B": 2}, &sum)
+*/
+package poculumrpc
+
+import (
+	"errors"
+	"fmt"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// 错误码
+const (
+	CodeParseError     = -32700 // 消息无法解码
+	CodeInvalidRequest = -32600 // 消息不是合法的请求
+	CodeMethodNotFound = -32601 // 方法不存在
+	CodeInvalidParams  = -32602 // 参数不合法
+	CodeInternalError  = -32603 // 处理函数返回了不是 *Error 的错误
+)
+
+// Error RPC 错误对象，处理函数返回 *Error 时原样发送给客户端
+type Error struct {
+	Code    int    `poculum:"code"`
+	Message string `poculum:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Errorf 创建 RPC 错误
+func Errorf(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// encodeRequest 编码请求消息
+func encodeRequest(poc *poculum.Poculum, id uint64, method string, params any) ([]byte, error) {
+	return poc.Dump(map[string]any{"id": id, "method": method, "params": params})
+}
+
+// encodeResponse 编码响应消息，rpcErr 不为 nil 时忽略 result
+func encodeResponse(poc *poculum.Poculum, id uint64, result poculum.Raw, rpcErr *Error) ([]byte, error) {
+	if rpcErr != nil {
+		return poc.Dump(map[string]any{"id": id, "error": rpcErr})
+	}
+	return poc.Dump(map[string]any{"id": id, "result": result})
+}
+
+// field 返回消息中键对应的原始字节，键不存在时返回 false
+func field(msg poculum.Raw, key string) (poculum.Raw, bool, error) {
+	raw, err := poculum.GetRaw(msg, key)
+	if poculum.IsKind(err, poculum.PathNotFound) {
+		return nil, false, nil
+	}
+	return raw, err == nil, err
+}
+
+// messageID 读取消息中的 id
+func messageID(poc *poculum.Poculum, msg poculum.Raw) (uint64, error) {
+	raw, ok, err := field(msg, "id")
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errors.New("missing id")
+	}
+	var id uint64
+	return id, poc.Unmarshal(raw, &id)
+}
@@ -0,0 +1,115 @@
+package poculumrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+func TestCall(t *testing.T) {
+	srv := NewServer()
+	srv.Handle("add", func(ctx context.Context, params poculum.Raw) (any, error) {
+		var p struct{ A, B int }
+		if err := poculum.Unmarshal(params, &p); err != nil {
+			return nil, Errorf(CodeInvalidParams, "%v", err)
+		}
+		return p.A + p.B, nil
+	})
+	srv.Handle("fail", func(ctx context.Context, params poculum.Raw) (any, error) {
+		return nil, errors.New("boom")
+	})
+
+	serverConn, clientConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- srv.ServeConn(ctx, serverConn) }()
+
+	client := NewClient(clientConn)
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			var sum int
+			err := client.Call(ctx, "add", map[string]any{"A": i, "B": 1}, &sum)
+			if err == nil && sum != i+1 {
+				err = errors.New("wrong sum")
+			}
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var rpcErr *Error
+	if err := client.Call(ctx, "fail", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInternalError {
+		t.Fatalf("expected internal error, got %v", err)
+	}
+	if err := client.Call(ctx, "missing", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Fatalf("expected method not found, got %v", err)
+	}
+	if err := client.Call(ctx, "add", "not a map", nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidParams {
+		t.Fatalf("expected invalid params, got %v", err)
+	}
+
+	client.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("ServeConn: %v", err)
+	}
+	if err := client.Call(ctx, "add", nil, nil); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestServeConnLimit(t *testing.T) {
+	srv := NewServer()
+	srv.SetMaxConcurrent(2)
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	srv.Handle("wait", func(ctx context.Context, params poculum.Raw) (any, error) {
+		if params != nil {
+			return nil, Errorf(CodeInvalidParams, "params = %x, want nil", params)
+		}
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return nil, nil
+	})
+
+	serverConn, clientConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.ServeConn(ctx, serverConn)
+	client := NewClient(clientConn)
+	defer client.Close()
+
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		go func() { errs <- client.Call(ctx, "wait", nil, nil) }()
+	}
+	for running.Load() < 2 {
+		runtime.Gosched()
+	}
+	close(release)
+	for i := 0; i < 6; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if p := peak.Load(); p != 2 {
+		t.Fatalf("peak concurrency = %d, want 2", p)
+	}
+}
@@ -0,0 +1,178 @@
+package poculumrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// HandlerFunc 处理一个请求，params 是请求中 params 的原始字节，请求中没有 params 或者 params 为 nil 时为 nil。
+// 返回 *Error 时原样发送给客户端，其他错误作为 CodeInternalError 发送
+type HandlerFunc func(ctx context.Context, params poculum.Raw) (any, error)
+
+// DefaultMaxConcurrent 每个连接默认同时处理的最大请求数
+const DefaultMaxConcurrent = 64
+
+// nilParams 编码后的 nil，客户端把 nil 的 params 编码为它
+var nilParams = poculum.MustDump(nil)
+
+// Server RPC 服务端，方法在开始服务之前或者服务期间都可以注册
+type Server struct {
+	poc           *poculum.Poculum
+	mu            sync.RWMutex
+	handlers      map[string]HandlerFunc
+	maxConcurrent int
+}
+
+// NewServer 创建服务端，使用 poculum.NewPoculumSecure 的限制解码请求，opts 可以调整
+func NewServer(opts ...poculum.ConfigOption) *Server {
+	return &Server{poc: poculum.NewPoculumSecure(opts...), handlers: make(map[string]HandlerFunc)}
+}
+
+// Handle 注册方法，已经存在的同名方法被替换
+func (s *Server) Handle(method string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = fn
+}
+
+// SetMaxConcurrent 设置每个连接同时处理的最大请求数，n 不大于 0 时使用 DefaultMaxConcurrent。
+// 达到上限时暂停读取该连接上的请求，客户端连续发送的请求不会无限制地占用内存。
+// 只影响之后开始服务的连接
+func (s *Server) SetMaxConcurrent(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxConcurrent = n
+}
+
+// Serve 接受 l 上的连接并分别处理，直到 ctx 被取消或者 Accept 出错。返回前关闭 l
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { l.Close() })
+	defer stop()
+	defer l.Close()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.ServeConn(ctx, conn)
+		}()
+	}
+}
+
+// ServeConn 处理一个连接上的请求，直到连接关闭（返回 nil）、读取出错或者 ctx 被取消。
+// 返回前等待进行中的请求处理完成并关闭 conn
+func (s *Server) ServeConn(ctx context.Context, conn io.ReadWriteCloser) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	s.mu.RLock()
+	limit := s.maxConcurrent
+	s.mu.RUnlock()
+	if limit <= 0 {
+		limit = DefaultMaxConcurrent
+	}
+	sem := make(chan struct{}, limit)
+
+	var wmu sync.Mutex
+	fw := s.poc.NewFrameWriter(conn)
+	reply := func(id uint64, result poculum.Raw, rpcErr *Error) {
+		data, err := encodeResponse(s.poc, id, result, rpcErr)
+		if err != nil {
+			data, _ = encodeResponse(s.poc, id, nil, Errorf(CodeInternalError, "encode result: %v", err))
+		}
+		wmu.Lock()
+		defer wmu.Unlock()
+		if fw.WriteRaw(data) != nil {
+			cancel()
+		}
+	}
+
+	fr := s.poc.NewFrameReader(conn)
+	for {
+		msg, err := fr.ReadRaw()
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		id, err := messageID(s.poc, msg)
+		if err != nil {
+			reply(0, nil, Errorf(CodeInvalidRequest, "invalid id: %v", err))
+			continue
+		}
+		var method string
+		raw, ok, err := field(msg, "method")
+		if err == nil && ok {
+			err = s.poc.Unmarshal(raw, &method)
+		}
+		if err != nil || !ok {
+			reply(id, nil, Errorf(CodeInvalidRequest, "invalid method"))
+			continue
+		}
+		params, _, err := field(msg, "params")
+		if err != nil {
+			reply(id, nil, Errorf(CodeInvalidParams, "%v", err))
+			continue
+		}
+		if bytes.Equal(params, nilParams) {
+			params = nil
+		}
+
+		s.mu.RLock()
+		fn := s.handlers[method]
+		s.mu.RUnlock()
+		if fn == nil {
+			reply(id, nil, Errorf(CodeMethodNotFound, "method %q not found", method))
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result, err := fn(ctx, params)
+			if err != nil {
+				var rpcErr *Error
+				if !errors.As(err, &rpcErr) {
+					rpcErr = Errorf(CodeInternalError, "%v", err)
+				}
+				reply(id, nil, rpcErr)
+				return
+			}
+			data, err := s.poc.Dump(result)
+			if err != nil {
+				reply(id, nil, Errorf(CodeInternalError, "encode result: %v", err))
+				return
+			}
+			reply(id, data, nil)
+		}()
+	}
+}
@@ -0,0 +1,113 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// 字段投影
+/*
+	WithFields 指定解码时保留的键，其余的键通过 skipValue 跳过，不构造任何 Go 对象，
+适合只需要读取大文档中少数几个字段的场景。
+
+路径使用与 Get 相同的 "." 分隔语法，例如 "user.name" 只保留顶层 user 中的 name，
+路径的最后一段对应的值会被完整解码。投影只作用于 map，路径中间遇到 list、扩展类型等其他值时，
+该值会被完整解码。
+
+使用 Unmarshal 解码到 struct 时，被跳过的字段按照缺失处理，default 标签仍然生效，
+标记为 required 的字段需要包含在投影中
+*/
+
+// projection 投影树，键对应的子树为 nil 时表示完整解码该键的值
+type projection map[string]projection
+
+// WithFields 解码时只保留 paths 中的键，没有 paths 时解码全部内容
+func WithFields(paths ...string) ConfigOption {
+	return func(poc *Poculum) {
+		if len(paths) == 0 {
+			poc.fields = nil
+			return
+		}
+		poc.fields = newProjection(paths)
+	}
+}
+
+// newProjection 把路径列表转换为投影树，较短的路径覆盖以它为前缀的较长路径
+func newProjection(paths []string) projection {
+	root := projection{}
+	for _, path := range paths {
+		node := root
+		segments := splitPath(path)
+		for i, seg := range segments {
+			child, exists := node[seg]
+			if exists && child == nil {
+				// 已经完整保留
+				break
+			}
+			if i == len(segments)-1 {
+				node[seg] = nil
+				break
+			}
+			if child == nil {
+				child = projection{}
+				node[seg] = child
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+// decodeProjected 按照投影树解码下一个值，值不是 map 时完整解码
+func (poc *Poculum) decodeProjected(reader *bytes.Reader, depth int, proj projection) (any, error) {
+	if depth > poc.maxRecursionDepth {
+		return nil, newError(MaxRecursionDepth, "Maximum recursion depth exceeded while parsing nested structure")
+	}
+
+	start := reader.Size() - int64(reader.Len())
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isMap() {
+		if _, err := reader.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return poc.decodeValue(reader, depth)
+	}
+	if h.length > poc.maxContainerItems {
+		return nil, newError(DataTooLarge, fmt.Sprintf("Object length too large: %d items (max %d)", h.length, poc.maxContainerItems))
+	}
+
+	obj := make(map[string]any, min(h.length, len(proj)))
+	for i := 0; i < h.length; i++ {
+		key, err := poc.readKey(reader)
+		if err == nil {
+			key, err = poc.mapKey(obj, key)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sub, keep := proj[key]
+		if !keep {
+			if err := poc.skipValue(reader, depth+1); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var value any
+		if sub == nil {
+			value, err = poc.decodeValue(reader, depth+1)
+		} else {
+			value, err = poc.decodeProjected(reader, depth+1, sub)
+		}
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = poc.afterDecode(value)
+	}
+	return obj, nil
+}
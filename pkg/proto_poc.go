@@ -0,0 +1,403 @@
+package poculum
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Protobuf 消息
+/*
+	EncodeProto 把 protoc-gen-go 生成的消息编码为 map，键为 .proto 文件中的字段名，
+已有 proto schema 的服务可以输出 poculum 给 Python 实现等动态的消费方。
+为了不依赖 google.golang.org/protobuf，这里读取生成代码中的 protobuf 结构体标签，而不是使用 protoreflect：
+
+	字段           与 proto 二进制格式一样，未设置的字段（零值、空的 repeated 与 map、nil 的消息）不写入
+	enum           编码为整数
+	bytes          编码为字节数据
+	repeated       编码为 list
+	map            字符串键编码为 map，整数键使用整数键 map 扩展类型，布尔键转换为 "true"/"false"
+	oneof          只写入被设置的成员，键为成员的字段名
+	嵌套的消息     编码为 map
+
+DecodeProto 忽略未知的键。解码 oneof 成员需要知道包装类型：当前的生成代码（protoc-gen-go v1.20 及以后）
+把它们记录在 protoimpl.MessageInfo 的 OneofWrappers 字段中，这里通过反射调用 ProtoReflect().Type() 读取；
+旧版本的生成代码与 gogo/protobuf 提供 XXX_OneofWrappers 方法。两者都没有时无法区分 oneof 成员与未知的键，
+有 oneof 字段的消息中出现无法识别的键时返回 UnsupportedType 错误
+*/
+
+// protoField proto 消息中的一个字段
+type protoField struct {
+	name  string // .proto 文件中的字段名
+	index int    // 在 struct 中的下标
+	oneof bool   // oneof 的 interface 字段
+}
+
+// protoMessage proto 消息的字段信息
+type protoMessage struct {
+	fields   []protoField
+	byName   map[string]protoField
+	wrappers map[string]reflect.Type // oneof 成员的字段名到包装类型（指针）的映射
+}
+
+var protoMessages sync.Map // map[reflect.Type]*protoMessage
+
+// protoTagName 从 protobuf 标签中读取字段名
+func protoTagName(tag string) string {
+	for _, part := range strings.Split(tag, ",") {
+		if name, ok := strings.CutPrefix(part, "name="); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// isProtoMessage 判断 t 是否是生成的 proto 消息的 struct 类型
+func isProtoMessage(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("protobuf") != "" || f.Tag.Get("protobuf_oneof") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedProtoMessage 返回 proto 消息 struct 类型的字段信息
+func cachedProtoMessage(t reflect.Type) *protoMessage {
+	if m, ok := protoMessages.Load(t); ok {
+		return m.(*protoMessage)
+	}
+
+	m := &protoMessage{byName: map[string]protoField{}, wrappers: map[string]reflect.Type{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Tag.Get("protobuf_oneof") != "" {
+			m.fields = append(m.fields, protoField{index: i, oneof: true})
+			continue
+		}
+		name := protoTagName(f.Tag.Get("protobuf"))
+		if name == "" {
+			continue
+		}
+		pf := protoField{name: name, index: i}
+		m.fields = append(m.fields, pf)
+		m.byName[name] = pf
+	}
+
+	for _, wrapper := range protoOneofWrappers(t) {
+		wt := reflect.TypeOf(wrapper)
+		if wt.Kind() == reflect.Pointer && wt.Elem().Kind() == reflect.Struct && wt.Elem().NumField() == 1 {
+			if name := protoTagName(wt.Elem().Field(0).Tag.Get("protobuf")); name != "" {
+				m.wrappers[name] = wt
+			}
+		}
+	}
+
+	actual, _ := protoMessages.LoadOrStore(t, m)
+	return actual.(*protoMessage)
+}
+
+// protoOneofWrappers 返回消息的 oneof 包装类型，找不到时返回 nil
+func protoOneofWrappers(t reflect.Type) (wrappers []any) {
+	ptr := reflect.New(t)
+	if w, ok := ptr.Interface().(interface{ XXX_OneofWrappers() []any }); ok {
+		return w.XXX_OneofWrappers()
+	}
+
+	// ProtoReflect().Type() 返回生成代码中的 *protoimpl.MessageInfo
+	defer func() {
+		if recover() != nil {
+			wrappers = nil
+		}
+	}()
+	method := ptr.MethodByName("ProtoReflect")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+	msg := method.Call(nil)[0]
+	if msg.Kind() == reflect.Interface && msg.IsNil() {
+		return nil
+	}
+	typeMethod := msg.MethodByName("Type")
+	if !typeMethod.IsValid() || typeMethod.Type().NumIn() != 0 || typeMethod.Type().NumOut() != 1 {
+		return nil
+	}
+	info := typeMethod.Call(nil)[0]
+	for info.Kind() == reflect.Interface || info.Kind() == reflect.Pointer {
+		if info.IsNil() {
+			return nil
+		}
+		info = info.Elem()
+	}
+	if info.Kind() != reflect.Struct {
+		return nil
+	}
+	field := info.FieldByName("OneofWrappers")
+	if !field.IsValid() || field.Type() != reflect.TypeOf([]any(nil)) {
+		return nil
+	}
+	return field.Interface().([]any)
+}
+
+// EncodeProto 使用默认实例编码 proto 消息
+func EncodeProto(m any) ([]byte, error) {
+	return Default().EncodeProto(m)
+}
+
+// EncodeProto 编码 proto 消息，m 是生成代码中消息的指针，nil 编码为 nil
+func (poc *Poculum) EncodeProto(m any) ([]byte, error) {
+	rv := reflect.ValueOf(m)
+	if rv.Kind() != reflect.Pointer || !isProtoMessage(rv.Type().Elem()) {
+		return nil, newError(UnsupportedType, fmt.Sprintf("EncodeProto requires a pointer to a generated proto message, got %T", m))
+	}
+	value, err := protoValue(rv)
+	if err != nil {
+		return nil, err
+	}
+	return poc.dump(value)
+}
+
+// protoValue 把 proto 消息中的值转换为编码使用的值
+func protoValue(rv reflect.Value) (any, error) {
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if isProtoMessage(rv.Type().Elem()) {
+			return protoMessageValue(rv.Elem())
+		}
+		return protoValue(rv.Elem())
+
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+		list := make([]any, rv.Len())
+		for i := range list {
+			item, err := protoValue(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+
+	case reflect.Map:
+		keyType := rv.Type().Key()
+		var m reflect.Value
+		switch {
+		case keyType.Kind() == reflect.String:
+			m = reflect.ValueOf(map[string]any{})
+		case isIntKind(keyType.Kind()):
+			m = reflect.MakeMap(reflect.MapOf(keyType, reflect.TypeOf((*any)(nil)).Elem()))
+		case keyType.Kind() == reflect.Bool:
+			m = reflect.ValueOf(map[string]any{})
+		default:
+			return nil, newError(UnsupportedType, fmt.Sprintf("Unsupported proto map key %s", keyType))
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			item, err := protoValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			key := iter.Key()
+			if key.Kind() == reflect.Bool {
+				key = reflect.ValueOf(strconv.FormatBool(key.Bool()))
+			}
+			m.SetMapIndex(key, reflect.ValueOf(&item).Elem())
+		}
+		return m.Interface(), nil
+
+	case reflect.Struct:
+		if isProtoMessage(rv.Type()) {
+			return protoMessageValue(rv)
+		}
+		return nil, newError(UnsupportedType, fmt.Sprintf("Unsupported proto field type %s", rv.Type()))
+
+	case reflect.Int32:
+		// enum 是以 int32 定义的命名类型
+		return int32(rv.Int()), nil
+	}
+	return rv.Interface(), nil
+}
+
+// protoMessageValue 把 proto 消息转换为 map，未设置的字段不写入
+func protoMessageValue(rv reflect.Value) (map[string]any, error) {
+	info := cachedProtoMessage(rv.Type())
+	obj := make(map[string]any, len(info.fields))
+	for _, f := range info.fields {
+		field := rv.Field(f.index)
+		name := f.name
+		if f.oneof {
+			// interface 中是指向包装 struct 的指针，包装 struct 只有一个字段
+			if field.IsNil() {
+				continue
+			}
+			wrapper := field.Elem()
+			if wrapper.Kind() != reflect.Pointer || wrapper.IsNil() || wrapper.Elem().NumField() != 1 {
+				return nil, newError(UnsupportedType, fmt.Sprintf("Unsupported oneof wrapper %s", wrapper.Type()))
+			}
+			name = protoTagName(wrapper.Elem().Type().Field(0).Tag.Get("protobuf"))
+			field = wrapper.Elem().Field(0)
+		} else if field.IsZero() || (field.Kind() == reflect.Slice || field.Kind() == reflect.Map) && field.Len() == 0 {
+			continue
+		}
+
+		value, err := protoValue(field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		obj[name] = value
+	}
+	return obj, nil
+}
+
+// DecodeProto 使用默认实例把 data 解码到 proto 消息中
+func DecodeProto(data []byte, m any) error {
+	return Default().DecodeProto(data, m)
+}
+
+// DecodeProto 把 EncodeProto 编码的数据（或者键为 proto 字段名的任意 map）解码到 proto 消息中，
+// m 是生成代码中消息的非 nil 指针，数据中没有的字段保持不变
+func (poc *Poculum) DecodeProto(data []byte, m any) error {
+	rv := reflect.ValueOf(m)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || !isProtoMessage(rv.Type().Elem()) {
+		return newError(InvalidTarget, fmt.Sprintf("DecodeProto requires a non-nil pointer to a generated proto message, got %T", m))
+	}
+	value, err := poc.load(data)
+	if err != nil {
+		return err
+	}
+	return poc.assignProto(rv.Elem(), value, "")
+}
+
+// assignProto 把解码得到的值赋给 proto 消息中的值
+func (poc *Poculum) assignProto(dst reflect.Value, src any, path string) error {
+	switch dst.Kind() {
+	case reflect.Pointer:
+		if src == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return poc.assignProto(dst.Elem(), src, path)
+
+	case reflect.Struct:
+		if !isProtoMessage(dst.Type()) {
+			break
+		}
+		obj, ok := src.(map[string]any)
+		if !ok {
+			return mismatchError(path, src, dst)
+		}
+		return poc.assignProtoMessage(dst, obj, path)
+
+	case reflect.Slice:
+		items, ok := src.([]any)
+		if !ok || dst.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		list := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := poc.assignProto(list.Index(i), item, joinPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		dst.Set(list)
+		return nil
+
+	case reflect.Map:
+		sv := reflect.ValueOf(src)
+		if src == nil || sv.Kind() != reflect.Map {
+			break
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), sv.Len())
+		iter := sv.MapRange()
+		for iter.Next() {
+			key := iter.Key().Interface()
+			itemPath := joinPath(path, fmt.Sprint(key))
+			k := reflect.New(dst.Type().Key()).Elem()
+			if s, ok := key.(string); ok && k.Kind() == reflect.Bool {
+				b, err := strconv.ParseBool(s)
+				if err != nil {
+					return mismatchError(itemPath, key, k)
+				}
+				key = b
+			}
+			if err := poc.assign(k, key, itemPath); err != nil {
+				return err
+			}
+			v := reflect.New(dst.Type().Elem()).Elem()
+			if err := poc.assignProto(v, iter.Value().Interface(), itemPath); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+		return nil
+	}
+	return poc.assign(dst, src, path)
+}
+
+// assignProtoMessage 把 map 赋给 proto 消息，键为 proto 字段名
+func (poc *Poculum) assignProtoMessage(dst reflect.Value, obj map[string]any, path string) error {
+	info := cachedProtoMessage(dst.Type())
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := joinPath(path, key)
+		if f, ok := info.byName[key]; ok {
+			if err := poc.assignProto(dst.Field(f.index), obj[key], fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+		wt, ok := info.wrappers[key]
+		if !ok {
+			if info.hasOneof() && len(info.wrappers) == 0 {
+				return newError(UnsupportedType, fmt.Sprintf("%s: cannot tell an unknown field from a oneof member, the generated code of %s exposes neither ProtoReflect nor XXX_OneofWrappers", fieldPath, dst.Type()))
+			}
+			continue
+		}
+
+		wrapper := reflect.New(wt.Elem())
+		if err := poc.assignProto(wrapper.Elem().Field(0), obj[key], fieldPath); err != nil {
+			return err
+		}
+		for _, f := range info.fields {
+			if f.oneof && wt.Implements(dst.Field(f.index).Type()) {
+				dst.Field(f.index).Set(wrapper)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// hasOneof 判断消息是否有 oneof 字段
+func (m *protoMessage) hasOneof() bool {
+	for _, f := range m.fields {
+		if f.oneof {
+			return true
+		}
+	}
+	return false
+}
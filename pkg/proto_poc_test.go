@@ -0,0 +1,170 @@
+package poculum
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 以下类型模仿 protoc-gen-go 生成的代码
+
+type testStatus int32
+
+type testItem struct {
+	state         struct{}
+	sizeCache     int32
+	unknownFields []byte
+
+	Sku string `protobuf:"bytes,1,opt,name=sku,proto3" json:"sku,omitempty"`
+	Qty uint32 `protobuf:"varint,2,opt,name=qty,proto3" json:"qty,omitempty"`
+}
+
+type testOrder struct {
+	state     struct{}
+	sizeCache int32
+
+	OrderId  int64               `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status   testStatus          `protobuf:"varint,2,opt,name=status,proto3,enum=test.Status" json:"status,omitempty"`
+	Items    []*testItem         `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	Labels   map[string]string   `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Counts   map[int32]*testItem `protobuf:"bytes,5,rep,name=counts,proto3" json:"counts,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Note     *string             `protobuf:"bytes,6,opt,name=note,proto3,oneof" json:"note,omitempty"`
+	Checksum []byte              `protobuf:"bytes,7,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// Types that are assignable to Payment:
+	//
+	//	*testOrder_Card
+	//	*testOrder_Cash
+	Payment isTestOrder_Payment `protobuf_oneof:"payment"`
+}
+
+type isTestOrder_Payment interface{ isTestOrder_Payment() }
+
+type testOrder_Card struct {
+	Card string `protobuf:"bytes,8,opt,name=card,proto3,oneof"`
+}
+
+type testOrder_Cash struct {
+	Cash bool `protobuf:"varint,9,opt,name=cash,proto3,oneof"`
+}
+
+func (*testOrder_Card) isTestOrder_Payment() {}
+func (*testOrder_Cash) isTestOrder_Payment() {}
+
+// 当前的生成代码在 file_*_proto_init 中设置 MessageInfo.OneofWrappers，
+// ProtoReflect 返回的 protoreflect.Message 的 Type 方法返回 *protoimpl.MessageInfo
+
+type testMessageInfo struct {
+	GoReflectType reflect.Type
+	OneofWrappers []any
+}
+
+type testReflectMessage interface{ Type() any }
+
+type testMessageState struct{ mi *testMessageInfo }
+
+func (s testMessageState) Type() any { return s.mi }
+
+var testOrderInfo = &testMessageInfo{
+	GoReflectType: reflect.TypeOf((*testOrder)(nil)),
+	OneofWrappers: []any{(*testOrder_Card)(nil), (*testOrder_Cash)(nil)},
+}
+
+func (x *testOrder) ProtoReflect() testReflectMessage {
+	return testMessageState{mi: testOrderInfo}
+}
+
+// testLegacyShape 模仿旧版本的生成代码，通过 XXX_OneofWrappers 列出包装类型
+type testLegacyShape struct {
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Shape isTestLegacyShape_Kind `protobuf_oneof:"kind"`
+}
+
+type isTestLegacyShape_Kind interface{ isTestLegacyShape_Kind() }
+
+type testLegacyShape_Radius struct {
+	Radius float64 `protobuf:"fixed64,2,opt,name=radius,proto3,oneof"`
+}
+
+func (*testLegacyShape_Radius) isTestLegacyShape_Kind() {}
+
+func (*testLegacyShape) XXX_OneofWrappers() []any {
+	return []any{(*testLegacyShape_Radius)(nil)}
+}
+
+// testOpaqueShape 有 oneof 字段，但是无法得到包装类型
+type testOpaqueShape struct {
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Shape isTestLegacyShape_Kind `protobuf_oneof:"kind"`
+}
+
+func TestProto(t *testing.T) {
+	note := ""
+	in := &testOrder{
+		OrderId:  42,
+		Status:   2,
+		Items:    []*testItem{{Sku: "pen", Qty: 3}, {Sku: "ink"}},
+		Labels:   map[string]string{"channel": "web"},
+		Counts:   map[int32]*testItem{7: {Qty: 1}},
+		Note:     &note,
+		Checksum: []byte{1, 2},
+		Payment:  &testOrder_Card{Card: "visa"},
+	}
+	data, err := EncodeProto(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := MustLoad(data).(map[string]any)
+	want := map[string]any{
+		"order_id": int64(42),
+		"status":   int32(2),
+		"items":    []any{map[string]any{"sku": "pen", "qty": uint32(3)}, map[string]any{"sku": "ink"}},
+		"labels":   map[string]any{"channel": "web"},
+		"note":     "",
+		"checksum": []byte{1, 2},
+		"card":     "visa",
+	}
+	delete(m, "counts")
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("got %#v", m)
+	}
+
+	var out testOrder
+	if err := DecodeProto(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&out, in) {
+		t.Fatalf("got %+v, want %+v", &out, in)
+	}
+
+	// 消息必须以指针传入
+	if _, err := EncodeProto(testItem{}); !IsKind(err, UnsupportedType) {
+		t.Fatalf("expected UnsupportedType for non-pointer, got %v", err)
+	}
+}
+
+func TestProtoOneofSources(t *testing.T) {
+	in := &testLegacyShape{Name: "c", Shape: &testLegacyShape_Radius{Radius: 1.5}}
+	data, err := EncodeProto(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out testLegacyShape
+	if err := DecodeProto(data, &out); err != nil || !reflect.DeepEqual(&out, in) {
+		t.Fatalf("got %+v, %v", &out, err)
+	}
+
+	// 未知的键在能够得到包装类型时被忽略
+	extra := MustDump(map[string]any{"name": "c", "unknown": true})
+	if err := DecodeProto(extra, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var opaque testOpaqueShape
+	if err := DecodeProto(data, &opaque); !IsKind(err, UnsupportedType) || !strings.Contains(err.Error(), "radius") {
+		t.Fatalf("expected UnsupportedType, got %v", err)
+	}
+	if err := DecodeProto(MustDump(map[string]any{"name": "c"}), &opaque); err != nil || opaque.Name != "c" {
+		t.Fatalf("got %+v, %v", opaque, err)
+	}
+}
@@ -0,0 +1,207 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// RawMessage 是一段已经编码好的 poculum 值
+//
+// 编码时 RawMessage 会被原样写入输出，不会再次解码和编码，
+// 可以把缓存的编码片段直接拼接到 map 的值或者 list 的元素中。
+// nil 或者空的 RawMessage 会被编码为 nil
+type RawMessage []byte
+
+// WriteTo 把 RawMessage 原样写入 w，实现 io.WriterTo，例如 raw.WriteTo(w) 直接写入 http.ResponseWriter 或者文件。nil 或者空的 RawMessage 写入 nil 值，与编码时一致
+func (m RawMessage) WriteTo(w io.Writer) (int64, error) {
+	data := []byte(m)
+	if len(data) == 0 {
+		data = []byte{typeNil}
+	}
+	n, err := w.Write(data)
+	if err != nil {
+		return int64(n), wrapError(IOError, "write raw message", err)
+	}
+	return int64(n), nil
+}
+
+// ReadFrom 从 r 读取到 io.EOF 为止，实现 io.ReaderFrom，例如 raw.ReadFrom(req.Body)。读取的数据必须恰好是一个完整的值，
+// 使用默认实例的 maxInputSize 限制读取的字节数。出错时 m 不变
+func (m *RawMessage) ReadFrom(r io.Reader) (int64, error) {
+	poc := Default()
+	if poc.maxInputSize > 0 {
+		r = io.LimitReader(r, int64(poc.maxInputSize)+1)
+	}
+	var buf bytes.Buffer
+	n, err := buf.ReadFrom(r)
+	if err != nil {
+		return n, wrapError(IOError, "read raw message", err)
+	}
+	if poc.maxInputSize > 0 && buf.Len() > poc.maxInputSize {
+		return n, newError(DataTooLarge, fmt.Sprintf("Input too large: more than %d bytes", poc.maxInputSize))
+	}
+	if err := poc.validRaw(buf.Bytes()); err != nil {
+		return n, err
+	}
+	*m = buf.Bytes()
+	return n, nil
+}
+
+// valueHeader 描述一个值的类型字节以及长度信息
+type valueHeader struct {
+	typeByte byte
+	tag      byte // 扩展类型的扩展标识
+	// length 对于字符串与字节数据是数据的字节数，对于 list、map 是元素个数，对于标量是数据的字节数
+	length int
+}
+
+// isContainer 判断是否是 list 或者 map
+func (h valueHeader) isContainer() bool {
+	return h.isList() || h.isMap()
+}
+
+func (h valueHeader) isList() bool {
+	return (h.typeByte >= typeFixListBase && h.typeByte <= typeFixListBase+15) ||
+		h.typeByte == typeList16 || h.typeByte == typeList32
+}
+
+func (h valueHeader) isMap() bool {
+	return (h.typeByte >= typeFixMapBase && h.typeByte <= typeFixMapBase+15) ||
+		h.typeByte == typeMap16 || h.typeByte == typeMap32
+}
+
+// readHeader 读取类型字节与长度字段，读取完成后 reader 位于数据部分的开头
+func readHeader(reader *bytes.Reader) (valueHeader, error) {
+	typeByte, err := reader.ReadByte()
+	if err != nil {
+		return valueHeader{}, newError(InsufficientData, "No type byte")
+	}
+
+	h := valueHeader{typeByte: typeByte}
+	switch {
+	case typeByte == typeUInt8 || typeByte == typeInt8:
+		h.length = 1
+	case typeByte == typeUInt16 || typeByte == typeInt16:
+		h.length = 2
+	case typeByte == typeUInt32 || typeByte == typeInt32 || typeByte == typeFloat32:
+		h.length = 4
+	case typeByte == typeUInt64 || typeByte == typeInt64 || typeByte == typeFloat64:
+		h.length = 8
+	case typeByte == typeTrue || typeByte == typeFalse || typeByte == typeNil:
+		h.length = 0
+	case typeByte >= typeFixStringBase && typeByte <= typeFixStringBase+15:
+		h.length = int(typeByte - typeFixStringBase)
+	case typeByte >= typeFixListBase && typeByte <= typeFixListBase+15:
+		h.length = int(typeByte - typeFixListBase)
+	case typeByte >= typeFixMapBase && typeByte <= typeFixMapBase+15:
+		h.length = int(typeByte - typeFixMapBase)
+	case typeByte == typeExt:
+		tag, err := reader.ReadByte()
+		if err != nil {
+			return h, newError(InsufficientData, "ext tag")
+		}
+		h.tag = tag
+	case typeByte == typeBytes8:
+		length, err := reader.ReadByte()
+		if err != nil {
+			return h, newError(InsufficientData, "bytes8 length")
+		}
+		h.length = int(length)
+	case typeByte == typeString16 || typeByte == typeList16 || typeByte == typeMap16 || typeByte == typeBytes16:
+		var length uint16
+		if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+			return h, newError(InsufficientData, fmt.Sprintf("length of type 0x%02x", typeByte))
+		}
+		h.length = int(length)
+	case typeByte == typeString32 || typeByte == typeList32 || typeByte == typeMap32 || typeByte == typeBytes32:
+		var length uint32
+		if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+			return h, newError(InsufficientData, fmt.Sprintf("length of type 0x%02x", typeByte))
+		}
+		h.length = int(length)
+	default:
+		return h, newError(UnknownTypeID, fmt.Sprintf("Unknown type identifier: 0x%02x", typeByte))
+	}
+
+	return h, nil
+}
+
+// skipValue 跳过 reader 中的一个完整值，不构造任何 Go 对象
+func (poc *Poculum) skipValue(reader *bytes.Reader, depth int) error {
+	if depth > poc.maxRecursionDepth {
+		return newError(MaxRecursionDepth, "Maximum recursion depth exceeded while skipping nested structure")
+	}
+
+	h, err := readHeader(reader)
+	if err != nil {
+		return err
+	}
+
+	if h.typeByte == typeExt {
+		return poc.skipValue(reader, depth+1)
+	}
+	if !h.isContainer() {
+		if h.length > reader.Len() {
+			return newError(InsufficientData, fmt.Sprintf("value of type 0x%02x", h.typeByte))
+		}
+		_, err := reader.Seek(int64(h.length), io.SeekCurrent)
+		return err
+	}
+
+	if h.length > poc.maxContainerItems {
+		return newError(DataTooLarge, fmt.Sprintf("Container length too large: %d items (max %d)", h.length, poc.maxContainerItems))
+	}
+
+	items := h.length
+	if h.isMap() {
+		items *= 2
+	}
+	for i := 0; i < items; i++ {
+		if err := poc.skipValue(reader, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readRaw 读取下一个完整值的原始字节
+func (poc *Poculum) readRaw(reader *bytes.Reader, depth int) ([]byte, error) {
+	start := reader.Size() - int64(reader.Len())
+	if err := poc.skipValue(reader, depth); err != nil {
+		return nil, err
+	}
+	end := reader.Size() - int64(reader.Len())
+
+	raw := make([]byte, end-start)
+	if _, err := reader.ReadAt(raw, start); err != nil {
+		return nil, newError(InsufficientData, "raw value")
+	}
+	return raw, nil
+}
+
+// validRaw 校验 data 恰好是一个完整的编码值
+func (poc *Poculum) validRaw(data []byte) error {
+	reader := bytes.NewReader(data)
+	if err := poc.skipValue(reader, 0); err != nil {
+		return err
+	}
+	if reader.Len() != 0 {
+		return newError(InvalidRaw, fmt.Sprintf("%d trailing bytes after value", reader.Len()))
+	}
+	return nil
+}
+
+// encodeRaw 把已经编码好的值原样写入缓冲区
+func (poc *Poculum) encodeRaw(raw RawMessage, buf *bytes.Buffer) error {
+	if len(raw) == 0 {
+		return buf.WriteByte(typeNil)
+	}
+	if err := poc.validRaw(raw); err != nil {
+		return err
+	}
+	buf.Write(raw)
+	return nil
+}
@@ -0,0 +1,73 @@
+package poculum
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestRawMessageSplice(t *testing.T) {
+	cached, err := DumpPoculum(map[string]any{"id": uint8(7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := DumpPoculum([]any{RawMessage(cached), "tail"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, cached) {
+		t.Fatalf("raw fragment not spliced verbatim: %x", data)
+	}
+
+	value, err := LoadPoculum(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := value.([]any)
+	if list[0].(map[string]any)["id"] != uint8(7) || list[1] != "tail" {
+		t.Fatalf("unexpected value: %#v", value)
+	}
+}
+
+func TestRawMessageInvalid(t *testing.T) {
+	// 声明了 2 个元素的 list，但只有 1 个元素
+	if _, err := DumpPoculum([]any{RawMessage{typeFixListBase + 2, typeTrue}}); err == nil {
+		t.Fatal("expected error for truncated raw fragment")
+	}
+	// 值后面有多余的字节
+	if _, err := DumpPoculum(RawMessage{typeTrue, typeTrue}); err == nil {
+		t.Fatal("expected error for trailing bytes")
+	}
+}
+
+func TestRawMessageIO(t *testing.T) {
+	raw := RawMessage(MustDump(map[string]any{"id": uint8(7)}))
+
+	var buf bytes.Buffer
+	if n, err := raw.WriteTo(&buf); err != nil || n != int64(len(raw)) {
+		t.Fatalf("WriteTo = %d, %v", n, err)
+	}
+
+	var copied RawMessage
+	if n, err := copied.ReadFrom(io.MultiReader(&buf)); err != nil || n != int64(len(raw)) || !bytes.Equal(copied, raw) {
+		t.Fatalf("ReadFrom = %d, %v, %x", n, err, copied)
+	}
+
+	buf.Reset()
+	RawMessage(nil).WriteTo(&buf)
+	if v, err := LoadPoculum(buf.Bytes()); err != nil || v != nil {
+		t.Fatalf("nil RawMessage wrote %x", buf.Bytes())
+	}
+
+	// 不完整或者有多余数据时返回错误，原来的值不变
+	if _, err := copied.ReadFrom(bytes.NewReader(raw[:len(raw)-1])); !IsKind(err, InsufficientData) {
+		t.Fatalf("expected InsufficientData, got %v", err)
+	}
+	if _, err := copied.ReadFrom(bytes.NewReader(append(raw[:len(raw):len(raw)], 0x01, 0x02))); !IsKind(err, InvalidRaw) {
+		t.Fatalf("expected InvalidRaw, got %v", err)
+	}
+	if !bytes.Equal(copied, raw) {
+		t.Fatal("failed ReadFrom modified the message")
+	}
+}
@@ -0,0 +1,59 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// JSON 扩展类型
+/*
+	json.RawMessage 编码为带有 JSON 扩展标识的字符串，内容是原样的 JSON 文本，
+混合系统可以把旧的 JSON 片段放进 poculum 文档中，不需要先解析再编码，也不会变成转义后的字符串。
+解码得到 json.RawMessage，不认识这个扩展标识的实现得到其中的 JSON 字符串。
+
+编码与解码时检查内容是否是合法的 JSON，不合法时返回 InvalidJSON 错误，可信配置不检查。
+nil 的 json.RawMessage 编码为 nil。Unmarshal 到 json.RawMessage 以外的目标时使用 json.Unmarshal 解码
+*/
+
+// encodeJSON 编码 JSON 片段
+func (poc *Poculum) encodeJSON(raw json.RawMessage, buf *bytes.Buffer) error {
+	if raw == nil {
+		return buf.WriteByte(typeNil)
+	}
+	if !poc.trusted && !json.Valid(raw) {
+		return newError(InvalidJSON, "json.RawMessage is not valid JSON")
+	}
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extJSON)
+	return poc.encodeString(string(raw), buf)
+}
+
+// decodeJSON 解码 JSON 片段
+func (poc *Poculum) decodeJSON(reader *bytes.Reader, depth int) (json.RawMessage, error) {
+	value, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, newError(TypeMismatch, fmt.Sprintf("JSON must contain a string, got %T", value))
+	}
+	raw := json.RawMessage(s)
+	if !poc.trusted && !json.Valid(raw) {
+		return nil, newError(InvalidJSON, "Embedded JSON is not valid")
+	}
+	return raw, nil
+}
+
+// assignJSON 使用 json.Unmarshal 把 JSON 片段解码到 dst 中
+func assignJSON(dst reflect.Value, raw json.RawMessage, path string) error {
+	if !dst.CanAddr() {
+		return mismatchError(path, raw, dst)
+	}
+	if err := json.Unmarshal(raw, dst.Addr().Interface()); err != nil {
+		return wrapError(TypeMismatch, fmt.Sprintf("%s: cannot decode embedded JSON into %s", displayPath(path), dst.Type()), err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEmbeddedJSON(t *testing.T) {
+	type event struct {
+		Kind    string          `poculum:"kind"`
+		Legacy  json.RawMessage `poculum:"legacy"`
+		Missing json.RawMessage `poculum:"missing"`
+	}
+	in := event{Kind: "order", Legacy: json.RawMessage(`{"id": 1, "items": ["a"]}`)}
+	data := MustDump(in)
+	if !bytes.Contains(data, []byte(`{"id": 1, "items": ["a"]}`)) {
+		t.Fatal("JSON text must be stored unmodified")
+	}
+
+	var out event
+	if err := Unmarshal(data, &out); err != nil || !reflect.DeepEqual(out, in) {
+		t.Fatalf("got %+v, %v", out, err)
+	}
+	v := MustLoad(data).(map[string]any)
+	if _, ok := v["legacy"].(json.RawMessage); !ok {
+		t.Fatalf("Load returned %T", v["legacy"])
+	}
+
+	// 解码到其它类型时使用 json.Unmarshal
+	var typed struct {
+		Legacy struct {
+			ID    int      `json:"id"`
+			Items []string `json:"items"`
+		} `poculum:"legacy"`
+	}
+	if err := Unmarshal(data, &typed); err != nil || typed.Legacy.ID != 1 || typed.Legacy.Items[0] != "a" {
+		t.Fatalf("got %+v, %v", typed, err)
+	}
+
+	if _, err := DumpPoculum(json.RawMessage(`{"id":`)); !IsKind(err, InvalidJSON) {
+		t.Fatalf("expected InvalidJSON, got %v", err)
+	}
+}
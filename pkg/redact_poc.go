@@ -0,0 +1,109 @@
+package poculum
+
+import "reflect"
+
+// 脱敏
+/*
+	WithRedaction 启用后，编码时以下值会被替换为 fn 的返回值：
+
+	type Account struct {
+		Name string `poculum:"name"`
+		SSN  string `poculum:"ssn,redact"` // 带有 redact 选项的字段
+	}
+
+	poc := poculum.NewPoculum(poculum.WithRedaction(nil, "headers.Authorization"))
+
+1. struct 中带有 redact 选项的字段；
+2. paths 所指的值，路径语法与 Get 相同，只能经过 map 与 struct 的字段。
+
+没有启用 WithRedaction 时 redact 选项不起作用，字段正常编码，
+所以可以为写日志、调试输出单独派生一个实例：logPoc := poc.With(poculum.WithRedaction(nil))
+*/
+
+// Redacted 默认的脱敏函数替换后的值
+const Redacted = "[REDACTED]"
+
+// WithRedaction 编码时使用 fn 替换带有 redact 选项的字段以及 paths 所指的值，
+// fn 为 nil 时替换为 Redacted
+func WithRedaction(fn func(v any) any, paths ...string) ConfigOption {
+	return func(poc *Poculum) {
+		if fn == nil {
+			fn = func(any) any { return Redacted }
+		}
+		poc.redactor = fn
+		poc.redactPaths = nil
+		if len(paths) > 0 {
+			poc.redactPaths = newProjection(paths)
+		}
+	}
+}
+
+// redactFields 替换 struct 中带有 redact 选项的字段的值，values 是 structToMap 的结果
+func (poc *Poculum) redactFields(t reflect.Type, values map[string]any) {
+	for _, f := range cachedFields(t) {
+		if !f.redact {
+			continue
+		}
+		if value, ok := values[f.name]; ok {
+			values[f.name] = poc.redactor(value)
+		}
+	}
+}
+
+// redactPath 按照路径树替换 v 中的值，返回替换后的副本，v 本身不被修改
+func (poc *Poculum) redactPath(v any, paths projection) any {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		rv := reflect.ValueOf(v)
+		for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		if !rv.IsValid() || poc.encoders[rv.Type()] != nil {
+			return v
+		}
+
+		switch {
+		case rv.Kind() == reflect.Struct && hasField(rv.Type(), paths):
+			values, err := structToMap(rv)
+			if err != nil {
+				// 由编码过程报告错误
+				return v
+			}
+			poc.redactFields(rv.Type(), values)
+			obj = values
+		case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String && !isSetMap(rv.Type()):
+			obj = make(map[string]any, rv.Len())
+			iter := rv.MapRange()
+			for iter.Next() {
+				obj[iter.Key().String()] = iter.Value().Interface()
+			}
+		default:
+			return v
+		}
+	}
+
+	redacted := make(map[string]any, len(obj))
+	for key, value := range obj {
+		sub, ok := paths[key]
+		switch {
+		case !ok:
+			redacted[key] = value
+		case sub == nil:
+			redacted[key] = poc.redactor(value)
+		default:
+			redacted[key] = poc.redactPath(value, sub)
+		}
+	}
+	return redacted
+}
+
+// hasField 判断 struct 中是否有路径树中的字段，没有时 struct 按照原来的方式编码，
+// 例如 time.Time 等没有导出字段的类型
+func hasField(t reflect.Type, paths projection) bool {
+	for _, f := range cachedFields(t) {
+		if _, ok := paths[f.name]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package poculum
+
+// DecodeReport 一次解码的数据的形状，用于监控数据是否在逐渐接近解码限制
+type DecodeReport struct {
+	Size          int // 输入的字节数
+	MaxDepth      int // 最大嵌套深度，顶层值的深度为 0
+	Lists         int // list 的数量
+	Maps          int // map 的数量
+	Elements      int // 所有 list 的元素与 map 的键值对的总数
+	LargestList   int // 元素最多的 list 的元素个数
+	LargestMap    int // 键值对最多的 map 的键值对个数
+	LargestString int // 最长字符串的字节数（不含 map 的键）
+	LargestBytes  int // 最长字节数据的字节数
+}
+
+// WithDecodeReport 每次 Load、Unmarshal 成功之后使用 fn 报告数据的形状，例如记录到监控系统中。
+// 报告需要再读取一遍数据的类型与长度信息，不构造其中的值，解码失败时不调用 fn
+func WithDecodeReport(fn func(DecodeReport)) ConfigOption {
+	return func(poc *Poculum) {
+		poc.reporter = fn
+	}
+}
+
+// LoadReport 使用默认实例解码 data，并返回数据的形状
+func LoadReport(data []byte) (any, DecodeReport, error) {
+	return Default().LoadReport(data)
+}
+
+// LoadReport 解码 data，并返回数据的形状
+func (poc *Poculum) LoadReport(data []byte) (any, DecodeReport, error) {
+	value, err := poc.Load(data)
+	if err != nil {
+		return nil, DecodeReport{}, err
+	}
+	report, err := poc.report(data)
+	return value, report, err
+}
+
+// report 统计 data 中第一个值的形状
+func (poc *Poculum) report(data []byte) (DecodeReport, error) {
+	report := DecodeReport{Size: len(data)}
+	if len(data) == 0 {
+		return report, nil
+	}
+	s, err := poc.describe(data)
+	if err != nil {
+		return report, err
+	}
+	report.MaxDepth = s.MaxDepth
+	report.Lists = s.Lists
+	report.Maps = s.Maps
+	report.Elements = s.ListItems + s.MapEntries
+	report.LargestList = s.LargestList
+	report.LargestMap = s.LargestMap
+	report.LargestString = s.LargestString
+	report.LargestBytes = s.LargestBytes
+	return report, nil
+}
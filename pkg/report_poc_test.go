@@ -0,0 +1,33 @@
+package poculum
+
+import (
+	"testing"
+)
+
+func TestDecodeReport(t *testing.T) {
+	data := MustDump(map[string]any{
+		"name":  "abc",
+		"blob":  []byte{1, 2, 3, 4},
+		"items": []any{[]any{1, 2}, "longer string"},
+	})
+	want := DecodeReport{Size: len(data), MaxDepth: 3, Lists: 2, Maps: 1, Elements: 7,
+		LargestList: 2, LargestMap: 3, LargestString: 13, LargestBytes: 4}
+
+	_, got, err := LoadReport(data)
+	if err != nil || got != want {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+
+	var reported []DecodeReport
+	poc := NewPoculum(WithDecodeReport(func(r DecodeReport) { reported = append(reported, r) }))
+	var v map[string]any
+	if err := poc.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := poc.Load(data[:len(data)-1]); err == nil {
+		t.Fatal("expected error for truncated data")
+	}
+	if len(reported) != 1 || reported[0] != want {
+		t.Fatalf("reported %+v", reported)
+	}
+}
@@ -0,0 +1,96 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// 抽样
+/*
+	Sample 与 SampleRandom 只解码很长的 list 中被选中的元素，其余的元素只跳过，
+监控任务可以低成本地查看有代表性的值：
+
+	values, err := poculum.Sample(data, 100)                  // 均匀间隔的 100 个元素
+	values, err := poculum.SampleRandom(data, 100, nil)       // 随机的 100 个元素，保持原来的顺序
+
+顶层值必须是普通的 list，稀疏 list 等扩展类型返回 TypeMismatch 错误。n 不小于元素个数时返回全部元素
+*/
+
+// Sample 解码顶层 list 中均匀间隔的 n 个元素，第一个元素总是被选中
+func Sample(data []byte, n int) ([]any, error) {
+	return Default().sample(data, n, func(length int) []int {
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = int(int64(i) * int64(length) / int64(n))
+		}
+		return indices
+	})
+}
+
+// SampleRandom 解码顶层 list 中随机选取的 n 个不同的元素，结果按照元素在 list 中的顺序排列。
+// r 为 nil 时使用全局的随机数生成器
+func SampleRandom(data []byte, n int, r *rand.Rand) ([]any, error) {
+	intN := rand.IntN
+	if r != nil {
+		intN = r.IntN
+	}
+	return Default().sample(data, n, func(length int) []int {
+		// Floyd 算法，只使用 O(n) 的内存
+		chosen := make(map[int]bool, n)
+		for j := length - n; j < length; j++ {
+			if k := intN(j + 1); !chosen[k] {
+				chosen[k] = true
+			} else {
+				chosen[j] = true
+			}
+		}
+		indices := make([]int, 0, n)
+		for i := range chosen {
+			indices = append(indices, i)
+		}
+		slices.Sort(indices)
+		return indices
+	})
+}
+
+// sample 解码 pick 选出的元素，pick 只在 n 小于元素个数时调用，返回严格递增的下标
+func (poc *Poculum) sample(data []byte, n int, pick func(length int) []int) ([]any, error) {
+	reader := bytes.NewReader(data)
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isList() {
+		return nil, newError(TypeMismatch, fmt.Sprintf("Sample requires a list, got %s", typeName(h.typeByte)))
+	}
+	if n <= 0 {
+		return []any{}, nil
+	}
+
+	if err := poc.checkItems(reader, h.length, "Array"); err != nil {
+		return nil, err
+	}
+	if n >= h.length {
+		return poc.decodeArray(reader, h.length, 0)
+	}
+
+	indices := pick(h.length)
+	values := make([]any, 0, len(indices))
+	next := 0
+	for _, index := range indices {
+		for ; next < index; next++ {
+			if err := poc.skipValue(reader, 1); err != nil {
+				return nil, err
+			}
+		}
+		value, err := poc.decodeValue(reader, 1)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, poc.afterDecode(value))
+		next++
+	}
+	return values, nil
+}
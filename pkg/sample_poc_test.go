@@ -0,0 +1,47 @@
+package poculum
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	items := make([]any, 1000)
+	for i := range items {
+		items[i] = uint32(i)
+	}
+	data := MustDump(items)
+
+	got, err := Sample(data, 4)
+	if err != nil || !reflect.DeepEqual(got, []any{uint32(0), uint32(250), uint32(500), uint32(750)}) {
+		t.Fatalf("Sample = %v, %v", got, err)
+	}
+	if got, err := Sample(MustDump([]any{1, 2}), 5); err != nil || len(got) != 2 {
+		t.Fatalf("Sample of short list = %v, %v", got, err)
+	}
+
+	got, err = SampleRandom(data, 50, rand.New(rand.NewPCG(1, 2)))
+	if err != nil || len(got) != 50 {
+		t.Fatalf("SampleRandom = %v, %v", got, err)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].(uint32) <= got[i-1].(uint32) {
+			t.Fatalf("samples not distinct and ordered: %v", got)
+		}
+	}
+
+	if _, err := Sample(MustDump(map[string]any{}), 1); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+}
+
+func TestSampleForgedLength(t *testing.T) {
+	data := []byte{typeList32, 0x7F, 0xFF, 0xFF, 0xFF}
+	if _, err := Sample(data, 1<<40); !IsKind(err, InsufficientData) {
+		t.Fatalf("expected InsufficientData, got %v", err)
+	}
+	if _, err := SampleRandom(data, 3, nil); !IsKind(err, InsufficientData) {
+		t.Fatalf("expected InsufficientData, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package schema
+
+import (
+	"fmt"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// 按字段编号的紧凑编码
+/*
+	默认的编码是自描述的，每个 map 都会重复写入字段名。
+紧凑编码把 schema 中的 struct 编码为 list，其中编号与值交替出现：
+
+	[编号1, 值1, 编号2, 值2, ...]
+
+编号使用 uint8 或者 uint16 编码，值为 nil 的可选字段不写入（any 类型的字段除外，nil 也是它的值）。
+紧凑编码的数据仍然是合法的 poculum 数据，但是必须配合同一份 schema 才能还原出字段名。
+解码时会忽略 schema 中不存在的编号，以便旧版本的读取方兼容新增的字段
+*/
+
+// fieldNumber 把字段编号转换为最小宽度的整数
+func fieldNumber(n int) any {
+	if n <= 0xFF {
+		return uint8(n)
+	}
+	return uint16(n)
+}
+
+func (s *Schema) compactValue(t *Type, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch t.Kind {
+	case KindStruct:
+		m, err := Map(v)
+		if err != nil {
+			return nil, err
+		}
+		return s.compactStruct(s.Struct(t.Name), m)
+	case KindList:
+		l, err := List(v)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]any, len(l))
+		for i, e := range l {
+			if out[i], err = s.compactValue(t.Elem, e); err != nil {
+				return nil, WrapIndex(i, err)
+			}
+		}
+		return out, nil
+	case KindMap:
+		m, err := Map(v)
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[string]any, len(m))
+		for k, e := range m {
+			if out[k], err = s.compactValue(t.Elem, e); err != nil {
+				return nil, WrapField(k, err)
+			}
+		}
+		return out, nil
+	default:
+		return v, s.checkValue(t, v)
+	}
+}
+
+func (s *Schema) compactStruct(st *Struct, m map[string]any) ([]any, error) {
+	for key := range m {
+		if st.Field(key) == nil {
+			return nil, WrapField(key, fmt.Errorf("field not defined in struct %s", st.Name))
+		}
+	}
+
+	out := make([]any, 0, len(m)*2)
+	for _, f := range st.Fields {
+		v, ok := f.lookup(m)
+		if !ok {
+			if f.Optional {
+				continue
+			}
+			return nil, WrapField(f.Name, ErrMissing)
+		}
+		cv, err := s.compactValue(f.Type, v)
+		if err != nil {
+			return nil, WrapField(f.Name, err)
+		}
+		out = append(out, fieldNumber(f.Number), cv)
+	}
+	return out, nil
+}
+
+func (s *Schema) expandValue(t *Type, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch t.Kind {
+	case KindStruct:
+		l, err := List(v)
+		if err != nil {
+			return nil, err
+		}
+		return s.expandStruct(s.Struct(t.Name), l)
+	case KindList:
+		l, err := List(v)
+		if err != nil {
+			return nil, err
+		}
+		for i, e := range l {
+			if l[i], err = s.expandValue(t.Elem, e); err != nil {
+				return nil, WrapIndex(i, err)
+			}
+		}
+		return l, nil
+	case KindMap:
+		m, err := Map(v)
+		if err != nil {
+			return nil, err
+		}
+		for k, e := range m {
+			if m[k], err = s.expandValue(t.Elem, e); err != nil {
+				return nil, WrapField(k, err)
+			}
+		}
+		return m, nil
+	default:
+		return v, s.checkValue(t, v)
+	}
+}
+
+func (s *Schema) expandStruct(st *Struct, l []any) (map[string]any, error) {
+	if len(l)%2 != 0 {
+		return nil, fmt.Errorf("compact struct %s has odd number of items", st.Name)
+	}
+
+	m := make(map[string]any, len(l)/2)
+	for i := 0; i < len(l); i += 2 {
+		number, err := Uint[uint16](l[i])
+		if err != nil {
+			return nil, WrapIndex(i, err)
+		}
+		f := st.FieldByNumber(int(number))
+		if f == nil {
+			continue
+		}
+		if m[f.Name], err = s.expandValue(f.Type, l[i+1]); err != nil {
+			return nil, WrapField(f.Name, err)
+		}
+	}
+
+	for _, f := range st.Fields {
+		if _, ok := f.lookup(m); !ok && !f.Optional {
+			return nil, WrapField(f.Name, ErrMissing)
+		}
+	}
+	return m, nil
+}
+
+// EncodeCompact 按照名为 name 的 struct 定义，把 value 以字段编号代替字段名进行编码
+func (s *Schema) EncodeCompact(name string, value map[string]any) ([]byte, error) {
+	st := s.Struct(name)
+	if st == nil {
+		return nil, fmt.Errorf("pocschema: undefined struct %q", name)
+	}
+	compact, err := s.compactStruct(st, value)
+	if err != nil {
+		return nil, err
+	}
+	return poculum.DumpPoculum(compact)
+}
+
+// DecodeCompact 解码 EncodeCompact 生成的数据，还原为以字段名为键的 map
+func (s *Schema) DecodeCompact(name string, data []byte) (map[string]any, error) {
+	st := s.Struct(name)
+	if st == nil {
+		return nil, fmt.Errorf("pocschema: undefined struct %q", name)
+	}
+	value, err := poculum.LoadPoculum(data)
+	if err != nil {
+		return nil, err
+	}
+	l, err := List(value)
+	if err != nil {
+		return nil, err
+	}
+	return s.expandStruct(st, l)
+}
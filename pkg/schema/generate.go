@@ -0,0 +1,236 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// 代码生成
+/*
+	对于每个 struct 定义，生成一个同名的 Go struct 以及以下方法，全部不使用反射：
+
+	ToPoculum() map[string]any             转换为可以直接编码的 map
+	FromPoculum(m map[string]any) error    从解码得到的 map 转换，同时检查类型、整数范围与非可选字段
+	MarshalPoculum() ([]byte, error)       编码
+	UnmarshalPoculum(data []byte) error    解码
+
+可选的标量与 struct 字段生成为指针，可选的 bytes、list、map、any 字段以 nil 表示不存在
+*/
+
+const (
+	poculumImport = "github.com/shinyes/poculum-go/pkg"
+	schemaImport  = "github.com/shinyes/poculum-go/pkg/schema"
+)
+
+// GoName 把字段名转换为导出的 Go 标识符，例如 "user_id" 转换为 "UserId"
+func GoName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	goName := b.String()
+	switch {
+	case goName == "":
+		return "X" + name
+	case goName[0] >= '0' && goName[0] <= '9':
+		// 例如 "_1st"
+		return "X" + goName
+	}
+	return goName
+}
+
+// goType 返回类型对应的 Go 类型
+func goType(t *Type) string {
+	switch t.Kind {
+	case KindAny:
+		return "any"
+	case KindBytes:
+		return "[]byte"
+	case KindList:
+		return "[]" + goType(t.Elem)
+	case KindMap:
+		return "map[string]" + goType(t.Elem)
+	case KindStruct:
+		return t.Name
+	default:
+		return t.Kind.String()
+	}
+}
+
+// nilable 判断类型的零值是否是 nil，这种类型的可选字段不需要使用指针
+func nilable(t *Type) bool {
+	switch t.Kind {
+	case KindAny, KindBytes, KindList, KindMap:
+		return true
+	}
+	return false
+}
+
+// converter 返回把 any 转换为标量类型的运行时函数
+func converter(t *Type) string {
+	switch t.Kind {
+	case KindInt8, KindInt16, KindInt32, KindInt64:
+		return "schema.Int[" + goType(t) + "]"
+	case KindUInt8, KindUInt16, KindUInt32, KindUInt64:
+		return "schema.Uint[" + goType(t) + "]"
+	case KindFloat32, KindFloat64:
+		return "schema.Float[" + goType(t) + "]"
+	case KindString:
+		return "schema.String"
+	case KindBytes:
+		return "schema.Bytes"
+	case KindBool:
+		return "schema.Bool"
+	}
+	return ""
+}
+
+type generator struct {
+	buf bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// encodeExpr 返回把 expr 转换为可编码值的表达式
+func encodeExpr(t *Type, expr string, depth int) string {
+	switch t.Kind {
+	case KindStruct:
+		return expr + ".ToPoculum()"
+	case KindList:
+		i, e := fmt.Sprintf("i%d", depth), fmt.Sprintf("e%d", depth)
+		return fmt.Sprintf("func() []any { out := make([]any, len(%s)); for %s, %s := range %s { out[%s] = %s }; return out }()",
+			expr, i, e, expr, i, encodeExpr(t.Elem, e, depth+1))
+	case KindMap:
+		k, e := fmt.Sprintf("k%d", depth), fmt.Sprintf("e%d", depth)
+		return fmt.Sprintf("func() map[string]any { out := make(map[string]any, len(%s)); for %s, %s := range %s { out[%s] = %s }; return out }()",
+			expr, k, e, expr, k, encodeExpr(t.Elem, e, depth+1))
+	default:
+		return expr
+	}
+}
+
+// decode 生成把 src 转换后存入 target 的语句，出错时返回 wrap 包装后的错误
+func (g *generator) decode(t *Type, target, src string, wrap func(string) string, depth int) {
+	switch t.Kind {
+	case KindAny:
+		g.printf("%s = %s\n", target, src)
+	case KindStruct:
+		m := fmt.Sprintf("m%d", depth)
+		g.printf("if %s, err := schema.Map(%s); err != nil {\nreturn %s\n", m, src, wrap("err"))
+		g.printf("} else if err := %s.FromPoculum(%s); err != nil {\nreturn %s\n}\n", target, m, wrap("err"))
+	case KindList:
+		l, i, e := fmt.Sprintf("l%d", depth), fmt.Sprintf("i%d", depth), fmt.Sprintf("e%d", depth)
+		g.printf("if %s, err := schema.List(%s); err != nil {\nreturn %s\n} else {\n", l, src, wrap("err"))
+		g.printf("%s = make(%s, len(%s))\n", target, goType(t), l)
+		g.printf("for %s, %s := range %s {\n", i, e, l)
+		g.decode(t.Elem, fmt.Sprintf("%s[%s]", target, i), e, func(err string) string {
+			return wrap(fmt.Sprintf("schema.WrapIndex(%s, %s)", i, err))
+		}, depth+1)
+		g.printf("}\n}\n")
+	case KindMap:
+		m, k, e, v := fmt.Sprintf("m%d", depth), fmt.Sprintf("k%d", depth), fmt.Sprintf("e%d", depth), fmt.Sprintf("v%d", depth)
+		g.printf("if %s, err := schema.Map(%s); err != nil {\nreturn %s\n} else {\n", m, src, wrap("err"))
+		g.printf("%s = make(%s, len(%s))\n", target, goType(t), m)
+		g.printf("for %s, %s := range %s {\n", k, e, m)
+		g.printf("var %s %s\n", v, goType(t.Elem))
+		g.decode(t.Elem, v, e, func(err string) string {
+			return wrap(fmt.Sprintf("schema.WrapField(%s, %s)", k, err))
+		}, depth+1)
+		g.printf("%s[%s] = %s\n}\n}\n", target, k, v)
+	default:
+		x := fmt.Sprintf("x%d", depth)
+		g.printf("if %s, err := %s(%s); err != nil {\nreturn %s\n} else {\n%s = %s\n}\n", x, converter(t), src, wrap("err"), target, x)
+	}
+}
+
+func (g *generator) genStruct(st *Struct) {
+	g.printf("type %s struct {\n", st.Name)
+	for _, f := range st.Fields {
+		typ := goType(f.Type)
+		if f.Optional && !nilable(f.Type) {
+			typ = "*" + typ
+		}
+		g.printf("%s %s `poculum:%q`\n", GoName(f.Name), typ, f.Name)
+	}
+	g.printf("}\n\n")
+
+	// ToPoculum
+	g.printf("// ToPoculum 把 %s 转换为可以直接编码的 map\n", st.Name)
+	g.printf("func (v *%s) ToPoculum() map[string]any {\n", st.Name)
+	g.printf("m := make(map[string]any, %d)\n", len(st.Fields))
+	for _, f := range st.Fields {
+		field := "v." + GoName(f.Name)
+		switch {
+		case f.Optional && nilable(f.Type):
+			g.printf("if %s != nil {\nm[%q] = %s\n}\n", field, f.Name, encodeExpr(f.Type, field, 0))
+		case f.Optional:
+			g.printf("if %s != nil {\nm[%q] = %s\n}\n", field, f.Name, encodeExpr(f.Type, "(*"+field+")", 0))
+		default:
+			g.printf("m[%q] = %s\n", f.Name, encodeExpr(f.Type, field, 0))
+		}
+	}
+	g.printf("return m\n}\n\n")
+
+	// FromPoculum
+	g.printf("// FromPoculum 从解码得到的 map 转换为 %s，同时检查字段类型\n", st.Name)
+	g.printf("func (v *%s) FromPoculum(m map[string]any) error {\n", st.Name)
+	for _, f := range st.Fields {
+		name := f.Name
+		wrap := func(err string) string {
+			return fmt.Sprintf("schema.WrapField(%q, %s)", name, err)
+		}
+		field := "v." + GoName(f.Name)
+		if f.Type.Kind == KindAny {
+			// any 类型的字段可以是 nil，有这个键就视为存在
+			g.printf("if x, ok := m[%q]; ok {\n", f.Name)
+		} else {
+			g.printf("if x, ok := m[%q]; ok && x != nil {\n", f.Name)
+		}
+		if f.Optional && !nilable(f.Type) {
+			g.printf("var p %s\n", goType(f.Type))
+			g.decode(f.Type, "p", "x", wrap, 0)
+			g.printf("%s = &p\n", field)
+		} else {
+			g.decode(f.Type, field, "x", wrap, 0)
+		}
+		if f.Optional {
+			g.printf("}\n")
+		} else {
+			g.printf("} else {\nreturn %s\n}\n", wrap("schema.ErrMissing"))
+		}
+	}
+	g.printf("return nil\n}\n\n")
+
+	// MarshalPoculum / UnmarshalPoculum
+	g.printf("// MarshalPoculum 把 %s 编码为 poculum 数据\n", st.Name)
+	g.printf("func (v *%s) MarshalPoculum() ([]byte, error) {\nreturn poculum.DumpPoculum(v.ToPoculum())\n}\n\n", st.Name)
+	g.printf("// UnmarshalPoculum 从 poculum 数据解码 %s\n", st.Name)
+	g.printf("func (v *%s) UnmarshalPoculum(data []byte) error {\n", st.Name)
+	g.printf("value, err := poculum.LoadPoculum(data)\nif err != nil {\nreturn err\n}\n")
+	g.printf("m, err := schema.Map(value)\nif err != nil {\nreturn err\n}\nreturn v.FromPoculum(m)\n}\n\n")
+}
+
+// Generate 为 s 中的所有 struct 生成 Go 代码，pkg 是生成代码所在的包名
+func Generate(s *Schema, pkg string) ([]byte, error) {
+	g := &generator{}
+	g.printf("// Code generated by pocgen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", pkg)
+	g.printf("import (\npoculum %q\n%q\n)\n\n", poculumImport, schemaImport)
+	for _, st := range s.Structs {
+		g.genStruct(st)
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("pocgen: formatting generated code: %w", err)
+	}
+	return src, nil
+}
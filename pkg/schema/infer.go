@@ -0,0 +1,279 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// shape 记录某个位置上在所有样本中观察到的值的形状
+type shape struct {
+	null, boolean, str, bytes bool
+	ints, floats              bool
+	float64s                  bool // 是否出现过 float64
+	negative                  bool // 是否出现过负整数
+	minInt                    int64
+	maxUint                   uint64
+
+	list *shape // list 元素的形状，出现过 list 时不为 nil
+	obj  *objShape
+}
+
+// objShape 记录 map 的字段
+type objShape struct {
+	seen   int // 观察到的 map 个数
+	order  []string
+	fields map[string]*shape
+	counts map[string]int // 每个字段出现的次数
+}
+
+func (s *shape) observe(v any) error {
+	switch x := v.(type) {
+	case nil:
+		s.null = true
+	case bool:
+		s.boolean = true
+	case string:
+		s.str = true
+	case []byte:
+		s.bytes = true
+	case float32:
+		s.floats = true
+	case float64:
+		s.floats = true
+		s.float64s = true
+	case int8, int16, int32, int64:
+		n, _ := Int[int64](x)
+		s.observeInt(n)
+	case uint8, uint16, uint32, uint64:
+		n, _ := Uint[uint64](x)
+		s.observeUint(n)
+	case []any:
+		if s.list == nil {
+			s.list = &shape{}
+		}
+		for _, e := range x {
+			if err := s.list.observe(e); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		if s.obj == nil {
+			s.obj = &objShape{fields: make(map[string]*shape), counts: make(map[string]int)}
+		}
+		s.obj.seen++
+		for _, key := range sortedKeys(x) {
+			field, ok := s.obj.fields[key]
+			if !ok {
+				field = &shape{}
+				s.obj.fields[key] = field
+				s.obj.order = append(s.obj.order, key)
+			}
+			s.obj.counts[key]++
+			if err := field.observe(x[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("schema: unsupported value %T", v)
+	}
+	return nil
+}
+
+func (s *shape) observeInt(n int64) {
+	if n < 0 {
+		if !s.negative || n < s.minInt {
+			s.minInt = n
+		}
+		s.negative = true
+	} else {
+		s.observeUint(uint64(n))
+		return
+	}
+	s.ints = true
+}
+
+func (s *shape) observeUint(n uint64) {
+	if n > s.maxUint {
+		s.maxUint = n
+	}
+	s.ints = true
+}
+
+// intKind 选择能容纳所有观察到的整数的最小类型
+func (s *shape) intKind() Kind {
+	if !s.negative {
+		switch {
+		case s.maxUint <= math.MaxUint8:
+			return KindUInt8
+		case s.maxUint <= math.MaxUint16:
+			return KindUInt16
+		case s.maxUint <= math.MaxUint32:
+			return KindUInt32
+		default:
+			return KindUInt64
+		}
+	}
+
+	switch {
+	case s.maxUint > math.MaxInt64:
+		// 同时出现了负数与超出 int64 的正数，没有合适的整数类型
+		return KindFloat64
+	case s.minInt >= math.MinInt8 && s.maxUint <= math.MaxInt8:
+		return KindInt8
+	case s.minInt >= math.MinInt16 && s.maxUint <= math.MaxInt16:
+		return KindInt16
+	case s.minInt >= math.MinInt32 && s.maxUint <= math.MaxInt32:
+		return KindInt32
+	default:
+		return KindInt64
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// inferrer 把 shape 转换为 Schema
+type inferrer struct {
+	schema *Schema
+}
+
+// structName 生成不重复的 struct 名称
+func (in *inferrer) structName(base string) string {
+	name := base
+	for i := 2; in.schema.Struct(name) != nil; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	return name
+}
+
+func (in *inferrer) typeOf(s *shape, name string) (*Type, error) {
+	categories := 0
+	for _, seen := range []bool{s.boolean, s.str, s.bytes, s.ints || s.floats, s.list != nil, s.obj != nil} {
+		if seen {
+			categories++
+		}
+	}
+	if categories != 1 {
+		return &Type{Kind: KindAny}, nil
+	}
+
+	switch {
+	case s.boolean:
+		return &Type{Kind: KindBool}, nil
+	case s.str:
+		return &Type{Kind: KindString}, nil
+	case s.bytes:
+		return &Type{Kind: KindBytes}, nil
+	case s.floats:
+		if s.float64s || s.ints {
+			return &Type{Kind: KindFloat64}, nil
+		}
+		return &Type{Kind: KindFloat32}, nil
+	case s.ints:
+		return &Type{Kind: s.intKind()}, nil
+	case s.list != nil:
+		elem, err := in.typeOf(s.list, name+"Item")
+		if err != nil {
+			return nil, err
+		}
+		return &Type{Kind: KindList, Elem: elem}, nil
+	default:
+		st, err := in.addStruct(s.obj, name)
+		if err != nil {
+			return nil, err
+		}
+		return &Type{Kind: KindStruct, Name: st}, nil
+	}
+}
+
+// fieldName 把 map 的键转换为 schema 可以解析的字段名：
+// 不能用于标识符的字符替换为 '_'，空键或者以数字开头的键加上 '_' 前缀
+func fieldName(key string) string {
+	var b strings.Builder
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		b.WriteByte('_')
+	}
+	for _, c := range key {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func (in *inferrer) addStruct(obj *objShape, name string) (string, error) {
+	st := &Struct{Name: in.structName(name)}
+	in.schema.Structs = append(in.schema.Structs, st)
+
+	// 不同的键可能得到相同的字段名或者相同的 Go 字段名，例如 "a-b" 与 "a_b"、"a_b" 与 "aB"
+	keys := make(map[string]string, len(obj.order))
+	goKeys := make(map[string]string, len(obj.order))
+	for _, key := range obj.order {
+		fname := fieldName(key)
+		if prev, ok := keys[fname]; ok {
+			return "", fmt.Errorf("schema: %s: keys %q and %q both map to field %q", st.Name, prev, key, fname)
+		}
+		goName := GoName(fname)
+		if prev, ok := goKeys[goName]; ok {
+			return "", fmt.Errorf("schema: %s: keys %q and %q both map to Go field %s", st.Name, prev, key, goName)
+		}
+		keys[fname], goKeys[goName] = key, key
+
+		field := obj.fields[key]
+		t, err := in.typeOf(field, goName)
+		if err != nil {
+			return "", err
+		}
+		st.Fields = append(st.Fields, &Field{
+			Name:     fname,
+			Type:     t,
+			Optional: field.null || obj.counts[key] < obj.seen,
+			Number:   len(st.Fields) + 1,
+		})
+	}
+	return st.Name, nil
+}
+
+// Infer 根据真实的数据样本推导 Schema，每个样本的顶层值必须是 map，生成的顶层 struct 名为 Root
+//
+// 没有在所有样本中出现或者出现过 nil 的字段被推导为可选字段，
+// 整数字段推导为能够容纳所有样本值的最小宽度，同一位置出现多种类型时推导为 any。
+// 不是合法标识符的键（例如 "a-b"）转换为合法的字段名（"a_b"），需要在数据中同样改名；
+// 不同的键转换后得到相同的字段名或者 Go 字段名时返回错误
+func Infer(samples ...[]byte) (*Schema, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("schema: no samples")
+	}
+
+	root := &shape{}
+	for i, data := range samples {
+		value, err := poculum.LoadPoculum(data)
+		if err != nil {
+			return nil, fmt.Errorf("schema: sample %d: %w", i, err)
+		}
+		if _, ok := value.(map[string]any); !ok {
+			return nil, fmt.Errorf("schema: sample %d: top-level value must be a map, got %T", i, value)
+		}
+		if err := root.observe(value); err != nil {
+			return nil, fmt.Errorf("schema: sample %d: %w", i, err)
+		}
+	}
+
+	in := &inferrer{schema: &Schema{}}
+	if _, err := in.addStruct(root.obj, "Root"); err != nil {
+		return nil, err
+	}
+	return in.schema, nil
+}
@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registered 注册中心中的一个 schema
+type Registered struct {
+	ID      uint32  // 注册中心分配的编号，写入每条消息的前缀
+	Subject string  // 主题，通常与 Kafka topic 对应
+	Struct  string  // 消息对应的 struct 名称
+	Schema  *Schema // 解析后的 schema
+}
+
+// Registry 注册中心客户端
+type Registry interface {
+	// Register 在 subject 下注册 schema，消息对应名为 structName 的 struct，返回编号。
+	// 相同的 schema 重复注册返回相同的编号
+	Register(ctx context.Context, subject, structName string, s *Schema) (uint32, error)
+	// Latest 返回 subject 下最新注册的 schema
+	Latest(ctx context.Context, subject string) (*Registered, error)
+	// Lookup 按照编号查找 schema
+	Lookup(ctx context.Context, id uint32) (*Registered, error)
+}
+
+// MemoryRegistry 内存中的注册中心，用于测试以及单进程的场景
+type MemoryRegistry struct {
+	mu       sync.Mutex
+	byID     []*Registered            // 下标为编号减 1
+	subjects map[string][]*Registered // 每个 subject 按照注册顺序的 schema
+}
+
+// NewMemoryRegistry 创建内存中的注册中心
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{subjects: make(map[string][]*Registered)}
+}
+
+// Register 在 subject 下注册 schema
+func (r *MemoryRegistry) Register(ctx context.Context, subject, structName string, s *Schema) (uint32, error) {
+	if s.Struct(structName) == nil {
+		return 0, fmt.Errorf("pocschema: undefined struct %q", structName)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	text := s.String()
+	for _, reg := range r.subjects[subject] {
+		if reg.Struct == structName && reg.Schema.String() == text {
+			return reg.ID, nil
+		}
+	}
+	reg := &Registered{ID: uint32(len(r.byID) + 1), Subject: subject, Struct: structName, Schema: s}
+	r.byID = append(r.byID, reg)
+	r.subjects[subject] = append(r.subjects[subject], reg)
+	return reg.ID, nil
+}
+
+// Latest 返回 subject 下最新注册的 schema
+func (r *MemoryRegistry) Latest(ctx context.Context, subject string) (*Registered, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	regs := r.subjects[subject]
+	if len(regs) == 0 {
+		return nil, fmt.Errorf("pocschema: subject %q not found", subject)
+	}
+	return regs[len(regs)-1], nil
+}
+
+// Lookup 按照编号查找 schema
+func (r *MemoryRegistry) Lookup(ctx context.Context, id uint32) (*Registered, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id == 0 || int(id) > len(r.byID) {
+		return nil, fmt.Errorf("pocschema: schema %d not found", id)
+	}
+	return r.byID[id-1], nil
+}
+
+// HTTPRegistry 通过 HTTP 访问的注册中心，接口与 Confluent Schema Registry 类似，内容为 JSON：
+//
+//	POST {base}/subjects/{subject}/versions   {"schema": 文本, "struct": 名称}  -> {"id": 编号}
+//	GET  {base}/subjects/{subject}/versions/latest  -> {"id": 编号, "schema": 文本, "struct": 名称}
+//	GET  {base}/schemas/ids/{id}                    -> {"schema": 文本, "struct": 名称, "subject": 主题}
+//
+// 查询结果不在这里缓存，由 Serializer 缓存
+type HTTPRegistry struct {
+	BaseURL string       // 例如 http://registry:8081
+	Client  *http.Client // 为 nil 时使用 http.DefaultClient
+}
+
+// registryEntry 注册中心的 JSON 内容
+type registryEntry struct {
+	ID      uint32 `json:"id,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Schema  string `json:"schema,omitempty"`
+	Struct  string `json:"struct,omitempty"`
+}
+
+// do 发送请求并把 JSON 响应解码到 out 中
+func (r *HTTPRegistry) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(r.BaseURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pocschema: registry %s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// registered 把 JSON 内容转换为 Registered
+func (e *registryEntry) registered() (*Registered, error) {
+	s, err := ParseString(e.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if s.Struct(e.Struct) == nil {
+		return nil, fmt.Errorf("pocschema: schema %d has no struct %q", e.ID, e.Struct)
+	}
+	return &Registered{ID: e.ID, Subject: e.Subject, Struct: e.Struct, Schema: s}, nil
+}
+
+// Register 在 subject 下注册 schema
+func (r *HTTPRegistry) Register(ctx context.Context, subject, structName string, s *Schema) (uint32, error) {
+	var out registryEntry
+	in := registryEntry{Schema: s.String(), Struct: structName}
+	if err := r.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", in, &out); err != nil {
+		return 0, err
+	}
+	return out.ID, nil
+}
+
+// Latest 返回 subject 下最新注册的 schema
+func (r *HTTPRegistry) Latest(ctx context.Context, subject string) (*Registered, error) {
+	var out registryEntry
+	if err := r.do(ctx, http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/latest", nil, &out); err != nil {
+		return nil, err
+	}
+	out.Subject = subject
+	return out.registered()
+}
+
+// Lookup 按照编号查找 schema
+func (r *HTTPRegistry) Lookup(ctx context.Context, id uint32) (*Registered, error) {
+	var out registryEntry
+	if err := r.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.FormatUint(uint64(id), 10), nil, &out); err != nil {
+		return nil, err
+	}
+	out.ID = id
+	return out.registered()
+}
@@ -0,0 +1,365 @@
+// Package schema 实现 poculum 的 .pocschema 结构描述语言，
+// 以及基于它的校验与 Go 代码生成，Python/JS/Rust 实现共用同一份 .pocschema 文件
+package schema
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// .pocschema 语法说明
+/*
+	一个文件由若干 struct 定义组成，# 之后的内容为注释：
+
+	struct Person {
+	    name: string
+	    age: uint8
+	    email: string?        # 类型后面带 ? 代表可选字段
+	    tags: list<string>
+	    scores: map<float64>  # map 的键固定为字符串
+	    address: Address      # 引用同一文件中的其它 struct
+	    nickname: string = 9  # 可以显式指定字段编号，用于紧凑编码
+	}
+
+没有显式指定编号的字段，编号为上一个字段的编号加 1，第一个字段的编号为 1。
+基本类型有 bool、int8/16/32/64、uint8/16/32/64、float32、float64、string、bytes、any
+*/
+
+// maxFieldNumber 字段编号的最大值
+const maxFieldNumber = 0xFFFF
+
+// Kind 字段类型的种类
+type Kind int
+
+const (
+	KindAny Kind = iota
+	KindBool
+	KindInt8
+	KindInt16
+	KindInt32
+	KindInt64
+	KindUInt8
+	KindUInt16
+	KindUInt32
+	KindUInt64
+	KindFloat32
+	KindFloat64
+	KindString
+	KindBytes
+	KindList
+	KindMap
+	KindStruct
+)
+
+var kindNames = map[Kind]string{
+	KindAny:     "any",
+	KindBool:    "bool",
+	KindInt8:    "int8",
+	KindInt16:   "int16",
+	KindInt32:   "int32",
+	KindInt64:   "int64",
+	KindUInt8:   "uint8",
+	KindUInt16:  "uint16",
+	KindUInt32:  "uint32",
+	KindUInt64:  "uint64",
+	KindFloat32: "float32",
+	KindFloat64: "float64",
+	KindString:  "string",
+	KindBytes:   "bytes",
+	KindList:    "list",
+	KindMap:     "map",
+	KindStruct:  "struct",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Type 字段的类型
+type Type struct {
+	Kind Kind
+	Elem *Type  // list 与 map 的元素类型
+	Name string // struct 的名称
+}
+
+func (t *Type) String() string {
+	switch t.Kind {
+	case KindList:
+		return "list<" + t.Elem.String() + ">"
+	case KindMap:
+		return "map<" + t.Elem.String() + ">"
+	case KindStruct:
+		return t.Name
+	default:
+		return t.Kind.String()
+	}
+}
+
+// Field struct 中的一个字段
+type Field struct {
+	Name     string
+	Type     *Type
+	Optional bool
+	Number   int // 字段编号，范围 1~65535
+}
+
+// Struct 一个 struct 定义
+type Struct struct {
+	Name   string
+	Fields []*Field
+}
+
+// FieldByNumber 按编号查找字段
+func (s *Struct) FieldByNumber(number int) *Field {
+	for _, f := range s.Fields {
+		if f.Number == number {
+			return f
+		}
+	}
+	return nil
+}
+
+// Field 按名称查找字段
+func (s *Struct) Field(name string) *Field {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Schema 一个 .pocschema 文件的内容
+type Schema struct {
+	Structs []*Struct
+}
+
+// Struct 按名称查找 struct 定义
+func (s *Schema) Struct(name string) *Struct {
+	for _, st := range s.Structs {
+		if st.Name == name {
+			return st
+		}
+	}
+	return nil
+}
+
+// String 把 Schema 输出为 .pocschema 文本
+func (s *Schema) String() string {
+	var b strings.Builder
+	for i, st := range s.Structs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "struct %s {\n", st.Name)
+		for _, f := range st.Fields {
+			optional := ""
+			if f.Optional {
+				optional = "?"
+			}
+			fmt.Fprintf(&b, "    %s: %s%s = %d\n", f.Name, f.Type, optional, f.Number)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// ParseError 解析 .pocschema 时的错误
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("pocschema:%d: %s", e.Line, e.Message)
+}
+
+// Parse 从 r 中解析 .pocschema
+func Parse(r io.Reader) (*Schema, error) {
+	s := &Schema{}
+	var current *Struct
+	line := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		if current == nil {
+			name, ok := strings.CutPrefix(text, "struct ")
+			if !ok || !strings.HasSuffix(name, "{") {
+				return nil, &ParseError{line, fmt.Sprintf("expected \"struct Name {\", got %q", text)}
+			}
+			name = strings.TrimSpace(strings.TrimSuffix(name, "{"))
+			if !isIdent(name) {
+				return nil, &ParseError{line, fmt.Sprintf("invalid struct name %q", name)}
+			}
+			if s.Struct(name) != nil {
+				return nil, &ParseError{line, fmt.Sprintf("duplicate struct %q", name)}
+			}
+			current = &Struct{Name: name}
+			s.Structs = append(s.Structs, current)
+			continue
+		}
+
+		if text == "}" {
+			current = nil
+			continue
+		}
+
+		field, err := parseField(text)
+		if err != nil {
+			return nil, &ParseError{line, err.Error()}
+		}
+		if current.Field(field.Name) != nil {
+			return nil, &ParseError{line, fmt.Sprintf("duplicate field %q", field.Name)}
+		}
+		if field.Number == 0 {
+			field.Number = 1
+			if n := len(current.Fields); n > 0 {
+				field.Number = current.Fields[n-1].Number + 1
+			}
+		}
+		if field.Number < 1 || field.Number > maxFieldNumber {
+			return nil, &ParseError{line, fmt.Sprintf("field number %d out of range", field.Number)}
+		}
+		if current.FieldByNumber(field.Number) != nil {
+			return nil, &ParseError{line, fmt.Sprintf("duplicate field number %d", field.Number)}
+		}
+		current.Fields = append(current.Fields, field)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return nil, &ParseError{line, fmt.Sprintf("struct %q is not closed", current.Name)}
+	}
+
+	// 检查引用的 struct 是否都已定义
+	for _, st := range s.Structs {
+		for _, f := range st.Fields {
+			if err := s.checkRefs(f.Type); err != nil {
+				return nil, fmt.Errorf("pocschema: %s.%s: %w", st.Name, f.Name, err)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// ParseString 从字符串解析 .pocschema
+func ParseString(text string) (*Schema, error) {
+	return Parse(strings.NewReader(text))
+}
+
+// ParseFile 从文件解析 .pocschema
+func ParseFile(path string) (*Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+func (s *Schema) checkRefs(t *Type) error {
+	switch t.Kind {
+	case KindList, KindMap:
+		return s.checkRefs(t.Elem)
+	case KindStruct:
+		if s.Struct(t.Name) == nil {
+			return fmt.Errorf("undefined struct %q", t.Name)
+		}
+	}
+	return nil
+}
+
+// parseField 解析 "name: type"、"name: type?" 或者 "name: type = 3"
+func parseField(text string) (*Field, error) {
+	name, typ, ok := strings.Cut(text, ":")
+	if !ok {
+		return nil, fmt.Errorf("expected \"name: type\", got %q", text)
+	}
+	name = strings.TrimSpace(name)
+	typ = strings.TrimSpace(typ)
+	if !isIdent(name) {
+		return nil, fmt.Errorf("invalid field name %q", name)
+	}
+
+	field := &Field{Name: name}
+	if before, number, ok := strings.Cut(typ, "="); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(number))
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("invalid field number %q", strings.TrimSpace(number))
+		}
+		field.Number = n
+		typ = strings.TrimSpace(before)
+	}
+	if strings.HasSuffix(typ, "?") {
+		field.Optional = true
+		typ = strings.TrimSpace(strings.TrimSuffix(typ, "?"))
+	}
+
+	t, err := ParseType(typ)
+	if err != nil {
+		return nil, err
+	}
+	field.Type = t
+	return field, nil
+}
+
+// ParseType 解析类型表达式，例如 "list<map<int64>>"
+func ParseType(text string) (*Type, error) {
+	for kind, name := range kindNames {
+		if text == name && kind != KindList && kind != KindMap && kind != KindStruct {
+			return &Type{Kind: kind}, nil
+		}
+	}
+
+	for _, kind := range []Kind{KindList, KindMap} {
+		inner, ok := strings.CutPrefix(text, kind.String()+"<")
+		if !ok {
+			continue
+		}
+		if !strings.HasSuffix(inner, ">") {
+			return nil, fmt.Errorf("unterminated type %q", text)
+		}
+		elem, err := ParseType(strings.TrimSpace(strings.TrimSuffix(inner, ">")))
+		if err != nil {
+			return nil, err
+		}
+		return &Type{Kind: kind, Elem: elem}, nil
+	}
+
+	if isIdent(text) {
+		return &Type{Kind: KindStruct, Name: text}, nil
+	}
+	return nil, fmt.Errorf("invalid type %q", text)
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
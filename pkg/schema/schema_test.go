@@ -0,0 +1,366 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+const testSchema = `
+struct Person {
+    name: string
+    age: uint8
+    email: string?   # optional
+    tags: list<string>
+    address: Address
+}
+
+struct Address {
+    city: string
+}
+`
+
+func TestParseAndValidate(t *testing.T) {
+	s, err := ParseString(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Structs) != 2 || s.Struct("Person").Field("email").Optional != true {
+		t.Fatalf("unexpected schema: %s", s)
+	}
+
+	valid := map[string]any{
+		"name":    "poc",
+		"age":     uint8(30),
+		"tags":    []any{"a"},
+		"address": map[string]any{"city": "x"},
+	}
+	if err := s.Validate("Person", valid); err != nil {
+		t.Fatal(err)
+	}
+
+	valid["age"] = uint16(300)
+	if err := s.Validate("Person", valid); err == nil {
+		t.Fatal("expected overflow error")
+	}
+
+	valid["age"] = uint8(1)
+	delete(valid["address"].(map[string]any), "city")
+	err = s.Validate("Person", valid)
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "address.city" || !errors.Is(err, ErrMissing) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"struct A {\n x: list<int8\n}",
+		"struct A {\n x: B\n}",
+		"struct A {\n x: int8\n",
+		"field x: int8",
+	} {
+		if _, err := ParseString(text); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+}
+
+// generateProgram 使用生成的代码进行往返测试，Event 的 payload 是值为 nil 的必填 any 字段
+const generateProgram = `package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"gentest/model"
+)
+
+func main() {
+	email := "a@b.c"
+	in := model.Event{Who: &model.Person{Name: "poc", Age: 30, Email: &email, Tags: []string{"a"}, Address: model.Address{City: "x"}}}
+	data, err := in.MarshalPoculum()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	var out model.Event
+	if err := out.UnmarshalPoculum(data); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if !reflect.DeepEqual(in, out) {
+		fmt.Printf("got %+v, want %+v\n", out, in)
+		os.Exit(1)
+	}
+	if err := new(model.Event).FromPoculum(map[string]any{}); err == nil {
+		fmt.Println("expected missing payload error")
+		os.Exit(1)
+	}
+}
+`
+
+func TestGenerate(t *testing.T) {
+	s, err := ParseString(testSchema + "\nstruct Event {\n    payload: any\n    note: any?\n    who: Person?\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Generate(s, "model")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 必填的 any 字段为 nil 时，Validate 与 Compact 编码同样视为存在
+	event := map[string]any{"payload": nil}
+	if err := s.Validate("Event", event); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.EncodeCompact("Event", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err := s.DecodeCompact("Event", data); err != nil || len(m) != 1 {
+		t.Fatalf("DecodeCompact = %v, %v", m, err)
+	}
+
+	if testing.Short() {
+		t.Skip("skipping compilation of generated code in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module gentest\n\ngo 1.22\n\nrequire github.com/shinyes/poculum-go v0.0.0\n\nreplace github.com/shinyes/poculum-go => " + root + "\n",
+		"model/model.go": string(src),
+		"main.go":        generateProgram,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code: %v\n%s", err, out)
+	}
+}
+
+func TestInfer(t *testing.T) {
+	a, _ := poculum.DumpPoculum(map[string]any{
+		"id":    uint8(1),
+		"delta": int8(-3),
+		"name":  "a",
+		"tags":  []any{"x"},
+		"geo":   map[string]any{"lat": float32(1.5)},
+	})
+	b, _ := poculum.DumpPoculum(map[string]any{
+		"id":    uint32(70000),
+		"delta": int16(-300),
+		"name":  "b",
+		"tags":  []any{},
+		"geo":   map[string]any{"lat": float64(2.5)},
+		"note":  nil,
+	})
+
+	s, err := Infer(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := s.Struct("Root")
+	for name, want := range map[string]string{
+		"id":    "uint32",
+		"delta": "int16",
+		"name":  "string",
+		"tags":  "list<string>",
+		"geo":   "Geo",
+		"note":  "any",
+	} {
+		if got := root.Field(name).Type.String(); got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+	}
+	if !root.Field("note").Optional || root.Field("name").Optional {
+		t.Error("unexpected optionality")
+	}
+	if got := s.Struct("Geo").Field("lat").Type.String(); got != "float64" {
+		t.Errorf("lat: got %s", got)
+	}
+}
+
+func TestInferNames(t *testing.T) {
+	data, _ := poculum.DumpPoculum(map[string]any{
+		"user-id": uint8(1),
+		"2fa":     true,
+		"http.headers": map[string]any{
+			"content-type": "text/plain",
+		},
+	})
+	s, err := Infer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"user_id", "_2fa", "http_headers"} {
+		if s.Struct("Root").Field(name) == nil {
+			t.Errorf("missing field %s in\n%s", name, s)
+		}
+	}
+	if s.Struct("HttpHeaders").Field("content_type") == nil {
+		t.Errorf("unexpected schema:\n%s", s)
+	}
+
+	// 推导得到的 schema 可以重新解析，并且可以生成代码
+	if _, err := ParseString(s.String()); err != nil {
+		t.Fatalf("%v\n%s", err, s)
+	}
+	if _, err := Generate(s, "model"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, keys := range [][]string{{"a-b", "a_b"}, {"a_b", "aB"}} {
+		data, _ := poculum.DumpPoculum(map[string]any{keys[0]: uint8(1), keys[1]: uint8(2)})
+		if _, err := Infer(data); err == nil {
+			t.Errorf("%q: expected collision error", keys)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	s, err := ParseString(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := map[string]any{
+		"name":    "poc",
+		"age":     uint8(30),
+		"tags":    []any{"a", "b"},
+		"address": map[string]any{"city": "x"},
+	}
+
+	compact, err := s.EncodeCompact("Person", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, _ := poculum.DumpPoculum(value)
+	if len(compact) >= len(full) {
+		t.Errorf("compact encoding is not smaller: %d >= %d", len(compact), len(full))
+	}
+
+	decoded, err := s.DecodeCompact("Person", compact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded["name"] != "poc" || decoded["address"].(map[string]any)["city"] != "x" {
+		t.Fatalf("unexpected value: %v", decoded)
+	}
+	if _, ok := decoded["email"]; ok {
+		t.Fatal("absent optional field should not be decoded")
+	}
+}
+
+// registryHandler 用 MemoryRegistry 实现 HTTPRegistry 的接口，用于测试
+func registryHandler(t *testing.T, reg *MemoryRegistry) http.Handler {
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, r *Registered, err error) {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(registryEntry{ID: r.ID, Subject: r.Subject, Schema: r.Schema.String(), Struct: r.Struct})
+	}
+	mux.HandleFunc("POST /subjects/{subject}/versions", func(w http.ResponseWriter, r *http.Request) {
+		var in registryEntry
+		json.NewDecoder(r.Body).Decode(&in)
+		s, err := ParseString(in.Schema)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		id, err := reg.Register(r.Context(), r.PathValue("subject"), in.Struct, s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		json.NewEncoder(w).Encode(registryEntry{ID: id})
+	})
+	mux.HandleFunc("GET /subjects/{subject}/versions/latest", func(w http.ResponseWriter, r *http.Request) {
+		latest, err := reg.Latest(r.Context(), r.PathValue("subject"))
+		reply(w, latest, err)
+	})
+	mux.HandleFunc("GET /schemas/ids/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.PathValue("id"))
+		found, err := reg.Lookup(r.Context(), uint32(id))
+		reply(w, found, err)
+	})
+	return mux
+}
+
+func TestSerializer(t *testing.T) {
+	s, err := ParseString(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(registryHandler(t, NewMemoryRegistry()))
+	defer srv.Close()
+	registry := &HTTPRegistry{BaseURL: srv.URL}
+	ctx := context.Background()
+
+	id, err := registry.Register(ctx, "people", "Person", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := registry.Register(ctx, "people", "Person", s); again != id {
+		t.Fatalf("re-registering returned %d, want %d", again, id)
+	}
+
+	type person struct {
+		Name    string            `poculum:"name"`
+		Age     uint8             `poculum:"age"`
+		Tags    []string          `poculum:"tags"`
+		Address map[string]string `poculum:"address"`
+	}
+	in := person{Name: "ann", Age: 30, Tags: []string{}, Address: map[string]string{"city": "Oslo"}}
+
+	ser := NewSerializer(registry, nil)
+	msg, err := ser.Serialize(ctx, "people", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := SchemaID(msg); got != id {
+		t.Fatalf("prefix has schema %d, want %d", got, id)
+	}
+
+	var out person
+	reg, err := NewSerializer(registry, nil).Deserialize(ctx, msg, &out)
+	if err != nil || reg.Struct != "Person" || out.Name != "ann" || out.Address["city"] != "Oslo" {
+		t.Fatalf("got %+v, %v", out, err)
+	}
+
+	if _, err := ser.Serialize(ctx, "people", map[string]any{"name": "bob"}); err == nil {
+		t.Fatal("expected validation error for incomplete value")
+	}
+	if _, err := ser.Deserialize(ctx, poculum.MustDump("x"), nil); !errors.Is(err, ErrNoSchemaPrefix) {
+		t.Fatalf("expected ErrNoSchemaPrefix, got %v", err)
+	}
+}
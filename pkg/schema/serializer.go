@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// 带有 schema 编号的消息
+/*
+	Serializer 编码的消息与 Confluent Schema Registry 的格式相同：
+
+	0x00 | schema 编号 4 字节（大端序） | poculum 编码的值
+
+编码时使用 subject 下最新的 schema 校验值，解码时按照编号查找 schema 并校验。
+查到的 schema 会被缓存，编号对应的 schema 不会改变，subject 的最新 schema 缓存到调用 Refresh 为止
+*/
+
+// wireMagic 消息的第一个字节
+const wireMagic = 0x00
+
+// wireHeaderSize 消息前缀的字节数
+const wireHeaderSize = 5
+
+// ErrNoSchemaPrefix 消息没有 schema 编号前缀
+var ErrNoSchemaPrefix = errors.New("pocschema: message has no schema ID prefix")
+
+// Serializer 使用注册中心中的 schema 编码、校验与解码消息，可以被多个 goroutine 同时使用
+type Serializer struct {
+	registry Registry
+	poc      *poculum.Poculum
+
+	mu     sync.RWMutex
+	byID   map[uint32]*Registered
+	latest map[string]*Registered
+}
+
+// NewSerializer 创建使用 registry 的 Serializer，poc 为 nil 时使用默认实例
+func NewSerializer(registry Registry, poc *poculum.Poculum) *Serializer {
+	if poc == nil {
+		poc = poculum.Default()
+	}
+	return &Serializer{
+		registry: registry,
+		poc:      poc,
+		byID:     make(map[uint32]*Registered),
+		latest:   make(map[string]*Registered),
+	}
+}
+
+// Refresh 清除 subject 的最新 schema 缓存，下次编码时重新查询
+func (s *Serializer) Refresh(subject string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.latest, subject)
+}
+
+// latestSchema 返回 subject 下最新的 schema
+func (s *Serializer) latestSchema(ctx context.Context, subject string) (*Registered, error) {
+	s.mu.RLock()
+	reg := s.latest[subject]
+	s.mu.RUnlock()
+	if reg != nil {
+		return reg, nil
+	}
+
+	reg, err := s.registry.Latest(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.latest[subject] = reg
+	s.byID[reg.ID] = reg
+	s.mu.Unlock()
+	return reg, nil
+}
+
+// schemaByID 按照编号返回 schema
+func (s *Serializer) schemaByID(ctx context.Context, id uint32) (*Registered, error) {
+	s.mu.RLock()
+	reg := s.byID[id]
+	s.mu.RUnlock()
+	if reg != nil {
+		return reg, nil
+	}
+
+	reg, err := s.registry.Lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.byID[id] = reg
+	s.mu.Unlock()
+	return reg, nil
+}
+
+// Serialize 使用 subject 下最新的 schema 校验 v，编码并加上 schema 编号前缀
+func (s *Serializer) Serialize(ctx context.Context, subject string, v any) ([]byte, error) {
+	reg, err := s.latestSchema(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	body, err := s.poc.Dump(v)
+	if err != nil {
+		return nil, err
+	}
+	value, err := s.poc.Load(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := reg.Schema.Validate(reg.Struct, value); err != nil {
+		return nil, err
+	}
+
+	return Prefix(reg.ID, body), nil
+}
+
+// Prefix 在 poculum 数据 body 之前加上 schema 编号前缀
+func Prefix(id uint32, body []byte) []byte {
+	msg := make([]byte, wireHeaderSize, wireHeaderSize+len(body))
+	msg[0] = wireMagic
+	binary.BigEndian.PutUint32(msg[1:], id)
+	return append(msg, body...)
+}
+
+// SchemaID 返回消息前缀中的 schema 编号以及之后的 poculum 数据
+func SchemaID(msg []byte) (uint32, []byte, error) {
+	if len(msg) < wireHeaderSize || msg[0] != wireMagic {
+		return 0, nil, ErrNoSchemaPrefix
+	}
+	return binary.BigEndian.Uint32(msg[1:]), msg[wireHeaderSize:], nil
+}
+
+// Deserialize 按照消息前缀中的编号查找 schema，校验后把值解码到 dst 中，返回使用的 schema
+func (s *Serializer) Deserialize(ctx context.Context, msg []byte, dst any) (*Registered, error) {
+	id, body, err := SchemaID(msg)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := s.schemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	value, err := s.poc.Load(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := reg.Schema.Validate(reg.Struct, value); err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+	if dst == nil {
+		return reg, nil
+	}
+	return reg, s.poc.Unmarshal(body, dst)
+}
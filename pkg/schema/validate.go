@@ -0,0 +1,268 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	poculum "github.com/shinyes/poculum-go/pkg"
+)
+
+// 以下是校验逻辑以及生成代码在运行时使用的转换函数
+
+// ErrMissing 缺少非可选字段时返回的错误
+var ErrMissing = errors.New("missing required field")
+
+// FieldError 带有字段路径的错误
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// WrapField 给错误加上字段路径，嵌套的 FieldError 会把路径拼接起来
+func WrapField(path string, err error) error {
+	if inner, ok := err.(*FieldError); ok {
+		return &FieldError{Path: path + "." + inner.Path, Err: inner.Err}
+	}
+	return &FieldError{Path: path, Err: err}
+}
+
+// WrapIndex 给错误加上 list 下标
+func WrapIndex(index int, err error) error {
+	return WrapField(fmt.Sprint(index), err)
+}
+
+// Int 把解码得到的任意整数转换为 T，超出 T 的范围时返回错误
+func Int[T int8 | int16 | int32 | int64](v any) (T, error) {
+	var n int64
+	switch x := v.(type) {
+	case int8:
+		n = int64(x)
+	case int16:
+		n = int64(x)
+	case int32:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint8:
+		n = int64(x)
+	case uint16:
+		n = int64(x)
+	case uint32:
+		n = int64(x)
+	case uint64:
+		if x > math.MaxInt64 {
+			return 0, fmt.Errorf("value %d overflows %T", x, T(0))
+		}
+		n = int64(x)
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", v)
+	}
+	if int64(T(n)) != n {
+		return 0, fmt.Errorf("value %d overflows %T", n, T(0))
+	}
+	return T(n), nil
+}
+
+// Uint 把解码得到的任意非负整数转换为 T，超出 T 的范围时返回错误
+func Uint[T uint8 | uint16 | uint32 | uint64](v any) (T, error) {
+	var n uint64
+	switch x := v.(type) {
+	case uint8:
+		n = uint64(x)
+	case uint16:
+		n = uint64(x)
+	case uint32:
+		n = uint64(x)
+	case uint64:
+		n = x
+	case int8, int16, int32, int64:
+		i, _ := Int[int64](x)
+		if i < 0 {
+			return 0, fmt.Errorf("value %d overflows %T", i, T(0))
+		}
+		n = uint64(i)
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", v)
+	}
+	if uint64(T(n)) != n {
+		return 0, fmt.Errorf("value %d overflows %T", n, T(0))
+	}
+	return T(n), nil
+}
+
+// Float 把解码得到的浮点数或者整数转换为 T
+func Float[T float32 | float64](v any) (T, error) {
+	switch x := v.(type) {
+	case float32:
+		return T(x), nil
+	case float64:
+		return T(x), nil
+	}
+	if i, err := Int[int64](v); err == nil {
+		return T(i), nil
+	}
+	if u, err := Uint[uint64](v); err == nil {
+		return T(u), nil
+	}
+	return 0, fmt.Errorf("expected number, got %T", v)
+}
+
+// String 断言 v 为字符串
+func String(v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expected string, got %T", v)
+	}
+	return s, nil
+}
+
+// Bytes 断言 v 为字节数据
+func Bytes(v any) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("expected bytes, got %T", v)
+	}
+	return b, nil
+}
+
+// Bool 断言 v 为布尔值
+func Bool(v any) (bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected bool, got %T", v)
+	}
+	return b, nil
+}
+
+// List 断言 v 为 list
+func List(v any) ([]any, error) {
+	l, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected list, got %T", v)
+	}
+	return l, nil
+}
+
+// Map 断言 v 为 map
+func Map(v any) (map[string]any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected map, got %T", v)
+	}
+	return m, nil
+}
+
+// checkValue 检查 v 是否符合类型 t
+func (s *Schema) checkValue(t *Type, v any) error {
+	var err error
+	switch t.Kind {
+	case KindAny:
+	case KindBool:
+		_, err = Bool(v)
+	case KindInt8:
+		_, err = Int[int8](v)
+	case KindInt16:
+		_, err = Int[int16](v)
+	case KindInt32:
+		_, err = Int[int32](v)
+	case KindInt64:
+		_, err = Int[int64](v)
+	case KindUInt8:
+		_, err = Uint[uint8](v)
+	case KindUInt16:
+		_, err = Uint[uint16](v)
+	case KindUInt32:
+		_, err = Uint[uint32](v)
+	case KindUInt64:
+		_, err = Uint[uint64](v)
+	case KindFloat32:
+		_, err = Float[float32](v)
+	case KindFloat64:
+		_, err = Float[float64](v)
+	case KindString:
+		_, err = String(v)
+	case KindBytes:
+		_, err = Bytes(v)
+	case KindList:
+		var l []any
+		if l, err = List(v); err == nil {
+			for i, e := range l {
+				if err := s.checkValue(t.Elem, e); err != nil {
+					return WrapIndex(i, err)
+				}
+			}
+		}
+	case KindMap:
+		var m map[string]any
+		if m, err = Map(v); err == nil {
+			for k, e := range m {
+				if err := s.checkValue(t.Elem, e); err != nil {
+					return WrapField(k, err)
+				}
+			}
+		}
+	case KindStruct:
+		var m map[string]any
+		if m, err = Map(v); err == nil {
+			err = s.checkStruct(s.Struct(t.Name), m)
+		}
+	}
+	return err
+}
+
+// lookup 返回字段在 m 中的值以及字段是否存在。
+// 值为 nil 的字段视为不存在，但 any 类型的字段可以是 nil，只要有这个键就视为存在
+func (f *Field) lookup(m map[string]any) (any, bool) {
+	v, ok := m[f.Name]
+	if f.Type.Kind == KindAny {
+		return v, ok
+	}
+	return v, ok && v != nil
+}
+
+func (s *Schema) checkStruct(st *Struct, m map[string]any) error {
+	for _, f := range st.Fields {
+		v, ok := f.lookup(m)
+		if !ok {
+			if f.Optional {
+				continue
+			}
+			return WrapField(f.Name, ErrMissing)
+		}
+		if err := s.checkValue(f.Type, v); err != nil {
+			return WrapField(f.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate 检查已解码的值是否符合名为 name 的 struct 定义
+func (s *Schema) Validate(name string, value any) error {
+	st := s.Struct(name)
+	if st == nil {
+		return fmt.Errorf("pocschema: undefined struct %q", name)
+	}
+	m, err := Map(value)
+	if err != nil {
+		return err
+	}
+	return s.checkStruct(st, m)
+}
+
+// ValidateBytes 解码 data 并检查是否符合名为 name 的 struct 定义
+func (s *Schema) ValidateBytes(name string, data []byte) error {
+	value, err := poculum.LoadPoculum(data)
+	if err != nil {
+		return err
+	}
+	return s.Validate(name, value)
+}
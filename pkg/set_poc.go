@@ -0,0 +1,106 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Set 元素不重复的集合，编码为带有集合扩展标识的 list
+//
+// 元素是否重复按照编码后的字节判断，编码与解码时出现重复元素都会返回错误。
+// Go 中的 map[T]struct{} 会自动编码为 Set，也可以使用 Unmarshal 把 Set 解码到 map[T]struct{} 或者切片中。
+// 普通的 list 也可以解码到 map[T]struct{} 中，其它语言的实现不需要支持集合扩展类型就可以写入这种字段
+type Set []any
+
+// isSetMap 判断是否是 map[T]struct{} 类型
+func isSetMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Elem().Kind() == reflect.Struct && t.Elem().NumField() == 0
+}
+
+// encodeSet 编码集合
+//
+// 元素总是使用规范编码并按照编码后的字节排序，内容相同的 map 等元素能够被判断为重复，
+// map[T]struct{} 等无序的来源也总是得到相同的字节
+func (poc *Poculum) encodeSet(set Set, buf *bytes.Buffer, depth int) error {
+	if len(set) > poc.maxContainerItems {
+		return newError(DataTooLarge, fmt.Sprintf("Set too large: %d items (max %d)", len(set), poc.maxContainerItems))
+	}
+
+	// 元素的编码方式只影响 map 键的顺序，派生的实例保留其它的配置
+	itemPoc := poc
+	if !poc.canonical {
+		itemPoc = poc.With(WithCanonical())
+	}
+
+	var items bytes.Buffer
+	bounds := make([]int, 0, len(set)+1)
+	seen := make(map[string]struct{}, len(set))
+	for _, item := range set {
+		start := items.Len()
+		bounds = append(bounds, start)
+		if err := itemPoc.encodeValue(item, &items, depth+2); err != nil {
+			return err
+		}
+		if poc.trusted {
+			continue
+		}
+		key := string(items.Bytes()[start:])
+		if _, dup := seen[key]; dup {
+			return newError(DuplicateItem, fmt.Sprintf("Duplicate set item: %v", item))
+		}
+		seen[key] = struct{}{}
+	}
+
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extSet)
+	writeListHeader(buf, len(set))
+
+	encoded := make([][]byte, len(bounds))
+	bounds = append(bounds, items.Len())
+	for i := range encoded {
+		encoded[i] = items.Bytes()[bounds[i]:bounds[i+1]]
+	}
+	sort.Slice(encoded, func(i, j int) bool { return bytes.Compare(encoded[i], encoded[j]) < 0 })
+	for _, item := range encoded {
+		buf.Write(item)
+	}
+	return nil
+}
+
+// decodeSet 解码集合中的 list，出现重复元素时返回错误（可信配置下不检查）
+func (poc *Poculum) decodeSet(reader *bytes.Reader, depth int) (Set, error) {
+	length, err := expectList(reader, "Set")
+	if err != nil {
+		return nil, err
+	}
+	if err := poc.checkItems(reader, length, "Set"); err != nil {
+		return nil, err
+	}
+
+	set := make(Set, length)
+	seen := make(map[string]struct{}, length)
+	for i := 0; i < length; i++ {
+		if poc.trusted {
+			if set[i], err = poc.decodeValue(reader, depth+1); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		raw, err := poc.readRaw(reader, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[string(raw)]; dup {
+			return nil, newError(DuplicateItem, fmt.Sprintf("Duplicate set item at index %d", i))
+		}
+		seen[string(raw)] = struct{}{}
+
+		if set[i], err = poc.decodeValue(bytes.NewReader(raw), depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,61 @@
+package poculum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	in := map[int]struct{}{1: {}, 300: {}, -5: {}}
+	data, err := DumpPoculum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := LoadPoculum(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set, ok := value.(Set); !ok || len(set) != 3 {
+		t.Fatalf("unexpected value: %#v", value)
+	}
+
+	var out map[int]struct{}
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 {
+		t.Fatalf("unexpected value: %v", out)
+	}
+
+	if _, err := DumpPoculum(Set{"a", "a"}); err == nil {
+		t.Fatal("expected error for duplicate items on encode")
+	}
+	dup := []byte{typeExt, extSet, typeFixListBase + 2, typeTrue, typeTrue}
+	if _, err := LoadPoculum(dup); err == nil {
+		t.Fatal("expected error for duplicate items on decode")
+	}
+}
+
+func TestSetCanonicalItems(t *testing.T) {
+	// 内容相同的 map 无论插入顺序如何都是重复的元素
+	a := map[string]any{"x": 1, "y": 2, "z": 3, "w": 4}
+	b := map[string]any{"w": 4, "z": 3, "y": 2, "x": 1}
+	for i := 0; i < 20; i++ {
+		if _, err := DumpPoculum(Set{a, b}); !IsKind(err, DuplicateItem) {
+			t.Fatalf("expected DuplicateItem, got %v", err)
+		}
+	}
+
+	// 无序的来源总是得到相同的字节
+	in := make(map[int]struct{})
+	for i := 0; i < 64; i++ {
+		in[i*7] = struct{}{}
+	}
+	first := MustDump(in)
+	for i := 0; i < 20; i++ {
+		if data := MustDump(in); !bytes.Equal(data, first) {
+			t.Fatalf("set encoding not deterministic: %x != %x", data, first)
+		}
+	}
+}
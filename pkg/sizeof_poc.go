@@ -0,0 +1,115 @@
+package poculum
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// SizeOf 估计 v 在内存中占用的字节数，包括 v 本身以及通过指针、slice、map、string 和 interface 引用的数据，
+// 用于估计缓存解码结果需要的内存，例如 SizeOf(LoadPoculum(data)) 的结果。
+//
+// 多次引用的同一份数据只计算一次，循环引用不会导致死循环。
+// map 的大小按照每个桶 8 个键值对、装载因子 6.5 估计，不计算内存分配器的对齐与 GC 的元数据，
+// 所以结果是近似值，适合比较与容量规划，不适合精确的计量
+func SizeOf(v any) int {
+	if v == nil {
+		return 0
+	}
+	s := sizer{seen: map[uintptr]bool{}}
+	rv := reflect.ValueOf(v)
+	return int(rv.Type().Size()) + s.indirect(rv)
+}
+
+// sizer 记录已经计算过的数据的地址
+type sizer struct {
+	seen map[uintptr]bool
+}
+
+// visit 判断地址为 p 的数据是否需要计算，第一次访问时返回 true
+func (s *sizer) visit(p uintptr) bool {
+	if p == 0 || s.seen[p] {
+		return false
+	}
+	s.seen[p] = true
+	return true
+}
+
+// indirect 返回 rv 引用的数据的大小，不包括 rv 本身
+func (s *sizer) indirect(rv reflect.Value) int {
+	switch rv.Kind() {
+	case reflect.String:
+		if rv.Len() == 0 || !s.visit(uintptr(unsafe.Pointer(unsafe.StringData(rv.String())))) {
+			return 0
+		}
+		return rv.Len()
+
+	case reflect.Slice:
+		if rv.Cap() == 0 || !s.visit(rv.Pointer()) {
+			return 0
+		}
+		size := rv.Cap() * int(rv.Type().Elem().Size())
+		for i := 0; i < rv.Len(); i++ {
+			size += s.indirect(rv.Index(i))
+		}
+		return size
+
+	case reflect.Array:
+		size := 0
+		for i := 0; i < rv.Len(); i++ {
+			size += s.indirect(rv.Index(i))
+		}
+		return size
+
+	case reflect.Struct:
+		size := 0
+		for i := 0; i < rv.NumField(); i++ {
+			size += s.indirect(rv.Field(i))
+		}
+		return size
+
+	case reflect.Pointer:
+		if rv.IsNil() || !s.visit(rv.Pointer()) {
+			return 0
+		}
+		return int(rv.Type().Elem().Size()) + s.indirect(rv.Elem())
+
+	case reflect.Interface:
+		if rv.IsNil() {
+			return 0
+		}
+		elem := rv.Elem()
+		switch elem.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			// 指针形状的值直接保存在 interface 中
+			return s.indirect(elem)
+		}
+		return int(elem.Type().Size()) + s.indirect(elem)
+
+	case reflect.Map:
+		if rv.IsNil() || !s.visit(rv.Pointer()) {
+			return 0
+		}
+		size := mapOverhead(rv.Type(), rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			size += s.indirect(iter.Key()) + s.indirect(iter.Value())
+		}
+		return size
+	}
+	return 0
+}
+
+// mapOverhead 估计有 n 个键值对的 map 的表头与桶占用的字节数
+func mapOverhead(t reflect.Type, n int) int {
+	const (
+		headerSize   = 48
+		bucketItems  = 8
+		loadFactor10 = 65 // 装载因子 6.5
+	)
+	buckets := 1
+	for buckets*loadFactor10 < n*10 {
+		buckets *= 2
+	}
+	bucket := bucketItems + bucketItems*int(t.Key().Size()+t.Elem().Size()) + int(unsafe.Sizeof(uintptr(0)))
+	return headerSize + buckets*bucket
+}
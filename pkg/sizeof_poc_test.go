@@ -0,0 +1,38 @@
+package poculum
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSizeOf(t *testing.T) {
+	if got := SizeOf("hello"); got != 16+5 {
+		t.Errorf("string: got %d", got)
+	}
+	if got := SizeOf(make([]byte, 10, 100)); got != 24+100 {
+		t.Errorf("slice: got %d", got)
+	}
+
+	// 共享的数据只计算一次，循环引用可以结束
+	type node struct {
+		Name string
+		Next *node
+	}
+	n := &node{Name: "loop"}
+	n.Next = n
+	if got := SizeOf(n); got != 8+24+4 {
+		t.Errorf("cycle: got %d", got)
+	}
+	s := strings.Repeat("x", 1000)
+	if shared, twice := SizeOf([]string{s, s}), SizeOf([]string{s, strings.Repeat("x", 1000)}); shared+1000 != twice {
+		t.Errorf("shared: got %d, separate %d", shared, twice)
+	}
+
+	v, err := LoadPoculum(MustDump(map[string]any{"name": strings.Repeat("a", 4096), "ids": []any{1, 2, 3}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SizeOf(v); got < 4096+3*8 || got > 4096+1024 {
+		t.Errorf("decoded tree: got %d", got)
+	}
+}
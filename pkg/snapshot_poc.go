@@ -0,0 +1,126 @@
+package poculum
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// 快照格式
+/*
+	Snapshot 把状态写为一个自描述的快照，Restore 读取并校验后解码：
+
+	"POCS" | 格式版本 1 字节 | 标志 1 字节 | 数据长度 4 字节 | CRC32 4 字节 | 数据
+
+数据为 poculum 编码的状态，编码后不小于 snapshotCompressMin 字节时使用 gzip 压缩，并设置压缩标志。
+CRC32（IEEE）针对写入的数据（压缩后）计算，多字节字段使用大端序
+*/
+
+const (
+	snapshotMagic       = "POCS"
+	snapshotVersion     = 1
+	snapshotHeaderSize  = 4 + 1 + 1 + 4 + 4
+	snapshotCompressed  = 0x01 // 数据使用 gzip 压缩
+	snapshotCompressMin = 512  // 压缩的最小字节数，更小的数据压缩没有收益
+)
+
+// Snapshot 把 state 编码为快照写入 w
+func (poc *Poculum) Snapshot(w io.Writer, state any) error {
+	payload, err := poc.dump(state)
+	if err != nil {
+		return err
+	}
+
+	var flags byte
+	if len(payload) >= snapshotCompressMin {
+		var compressed bytes.Buffer
+		zw := gzip.NewWriter(&compressed)
+		if _, err := zw.Write(payload); err != nil {
+			return wrapError(IOError, "compress snapshot", err)
+		}
+		if err := zw.Close(); err != nil {
+			return wrapError(IOError, "compress snapshot", err)
+		}
+		payload = compressed.Bytes()
+		flags |= snapshotCompressed
+	}
+	if uint64(len(payload)) > 0xFFFFFFFF {
+		return newError(DataTooLarge, fmt.Sprintf("Snapshot too large: %d bytes", len(payload)))
+	}
+
+	header := make([]byte, snapshotHeaderSize)
+	copy(header, snapshotMagic)
+	header[4] = snapshotVersion
+	header[5] = flags
+	binary.BigEndian.PutUint32(header[6:], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[10:], crc32.ChecksumIEEE(payload))
+	if _, err := w.Write(header); err != nil {
+		return wrapError(IOError, "write snapshot", err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return wrapError(IOError, "write snapshot", err)
+	}
+	return nil
+}
+
+// Restore 从 r 读取一个快照，校验后解码到 dst 中
+func (poc *Poculum) Restore(r io.Reader, dst any) error {
+	header := make([]byte, snapshotHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return wrapError(InsufficientData, "snapshot header", err)
+	}
+	if string(header[:4]) != snapshotMagic {
+		return newError(InvalidSnapshot, "Not a poculum snapshot")
+	}
+	if header[4] != snapshotVersion {
+		return newError(InvalidSnapshot, fmt.Sprintf("Unsupported snapshot version %d", header[4]))
+	}
+	flags := header[5]
+	if flags&^snapshotCompressed != 0 {
+		return newError(InvalidSnapshot, fmt.Sprintf("Unknown snapshot flags 0x%02x", flags))
+	}
+	length := int64(binary.BigEndian.Uint32(header[6:]))
+	if poc.maxInputSize > 0 && length > int64(poc.maxInputSize) {
+		return newError(DataTooLarge, fmt.Sprintf("Snapshot too large: %d bytes (max %d)", length, poc.maxInputSize))
+	}
+
+	var payload bytes.Buffer
+	if _, err := io.CopyN(&payload, r, length); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return wrapError(InsufficientData, "snapshot data", err)
+	}
+	if crc32.ChecksumIEEE(payload.Bytes()) != binary.BigEndian.Uint32(header[10:]) {
+		return newError(ChecksumMismatch, "Snapshot checksum mismatch")
+	}
+
+	data := payload.Bytes()
+	if flags&snapshotCompressed != 0 {
+		zr, err := gzip.NewReader(&payload)
+		if err != nil {
+			return wrapError(InvalidSnapshot, "decompress", err)
+		}
+		var body io.Reader = zr
+		if poc.maxInputSize > 0 {
+			body = io.LimitReader(zr, int64(poc.maxInputSize)+1)
+		}
+		if data, err = io.ReadAll(body); err != nil {
+			return wrapError(InvalidSnapshot, "decompress", err)
+		}
+	}
+	return poc.Unmarshal(data, dst)
+}
+
+// Snapshot 使用默认实例把 state 编码为快照写入 w
+func Snapshot(w io.Writer, state any) error {
+	return Default().Snapshot(w, state)
+}
+
+// Restore 使用默认实例从 r 读取快照并解码到 dst 中
+func Restore(r io.Reader, dst any) error {
+	return Default().Restore(r, dst)
+}
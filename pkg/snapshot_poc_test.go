@@ -0,0 +1,47 @@
+package poculum
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	type State struct {
+		Level uint8
+		Log   []string
+	}
+
+	for _, entries := range []int{1, 200} {
+		in := State{Level: 3}
+		for i := 0; i < entries; i++ {
+			in.Log = append(in.Log, "player moved north")
+		}
+
+		var buf bytes.Buffer
+		if err := Snapshot(&buf, in); err != nil {
+			t.Fatal(err)
+		}
+		if compressed := buf.Bytes()[5]&snapshotCompressed != 0; compressed != (entries > 1) {
+			t.Fatalf("%d entries: compressed = %v", entries, compressed)
+		}
+
+		var out State
+		if err := Restore(bytes.NewReader(buf.Bytes()), &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.Level != 3 || len(out.Log) != entries {
+			t.Fatalf("got %+v", out)
+		}
+
+		corrupt := append([]byte(nil), buf.Bytes()...)
+		corrupt[len(corrupt)-1] ^= 0xFF
+		if err := Restore(bytes.NewReader(corrupt), &out); !IsKind(err, ChecksumMismatch) {
+			t.Fatalf("expected checksum error, got %v", err)
+		}
+	}
+
+	if err := Restore(strings.NewReader("JSON{}"), &struct{}{}); err == nil {
+		t.Fatal("expected error for non-snapshot input")
+	}
+}
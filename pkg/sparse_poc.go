@@ -0,0 +1,209 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// 稀疏 list
+/*
+	启用 WithSparseLists 后，如果 list 中最常见的零值（nil、数字 0、false）所占比例达到设定值，
+list 会编码为带有稀疏扩展标识的 list：
+
+	[逻辑长度, 填充值, 下标1, 值1, 下标2, 值2, ...]
+
+等于填充值的元素不写入，解码时还原为普通的 list，所以对解码方是透明的。
+下标严格递增，并且使用能容纳它的最小宽度的无符号整数编码
+*/
+
+const (
+	minSparseLength     = 8       // 使用稀疏编码的 list 的最小长度，太短的 list 稀疏编码没有收益
+	defaultSparseLength = 1 << 24 // 解码时稀疏 list 还原后的默认最大长度
+)
+
+// WithSparseLists 对零值比例不低于 ratio（0~1）的 list 使用稀疏编码
+func WithSparseLists(ratio float64) ConfigOption {
+	return func(poc *Poculum) {
+		poc.sparseRatio = ratio
+	}
+}
+
+// WithMaxSparseLength 设置解码时稀疏 list 还原后的最大长度。
+// 稀疏 list 用几个字节就可以声明很长的 list，所以除了 maxContainerItems 之外还有单独的上限，
+// 默认为 16M 与 maxContainerItems 中较小的值
+func WithMaxSparseLength(n int) ConfigOption {
+	return func(poc *Poculum) {
+		poc.maxSparseLength = n
+	}
+}
+
+// sparseLimit 返回稀疏 list 还原后的最大长度
+func (poc *Poculum) sparseLimit() int {
+	if poc.maxSparseLength > 0 {
+		return poc.maxSparseLength
+	}
+	return min(poc.maxContainerItems, defaultSparseLength)
+}
+
+// isZeroItem 判断是否是可以作为稀疏填充值的零值
+func isZeroItem(item any) bool {
+	switch v := item.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case int8:
+		return v == 0
+	case int16:
+		return v == 0
+	case int32:
+		return v == 0
+	case int64:
+		return v == 0
+	case uint:
+		return v == 0
+	case uint8:
+		return v == 0
+	case uint16:
+		return v == 0
+	case uint32:
+		return v == 0
+	case uint64:
+		return v == 0
+	case float32:
+		return math.Float32bits(v) == 0 // -0.0 不是填充值，保留符号
+	case float64:
+		return math.Float64bits(v) == 0
+	}
+	return false
+}
+
+// compactUint 返回能容纳 n 的最小宽度的无符号整数
+func compactUint(n int) any {
+	switch {
+	case n <= 0xFF:
+		return uint8(n)
+	case n <= 0xFFFF:
+		return uint16(n)
+	default:
+		return uint32(n)
+	}
+}
+
+// sparseFill 选择稀疏编码的填充值，不适合稀疏编码时第二个返回值为 false
+func (poc *Poculum) sparseFill(arr []any) (any, int, bool) {
+	if poc.sparseRatio <= 0 || poc.noSparse || len(arr) < minSparseLength {
+		return nil, 0, false
+	}
+
+	counts := make(map[any]int)
+	var fill any
+	best := 0
+	for _, item := range arr {
+		if !isZeroItem(item) {
+			continue
+		}
+		counts[item]++
+		if counts[item] > best {
+			fill, best = item, counts[item]
+		}
+	}
+
+	if best == 0 || float64(best) < poc.sparseRatio*float64(len(arr)) {
+		return nil, 0, false
+	}
+	return fill, best, true
+}
+
+// encodeSparse 使用稀疏编码写入 list，fills 是等于 fill 的元素个数
+func (poc *Poculum) encodeSparse(arr []any, fill any, fills int, buf *bytes.Buffer, depth int) error {
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extSparse)
+	writeListHeader(buf, 2+2*(len(arr)-fills))
+
+	if err := poc.encodeValue(compactUint(len(arr)), buf, depth+1); err != nil {
+		return err
+	}
+	if err := poc.encodeValue(fill, buf, depth+1); err != nil {
+		return err
+	}
+	for i, item := range arr {
+		if isZeroItem(item) && item == fill {
+			continue
+		}
+		if err := poc.encodeValue(compactUint(i), buf, depth+1); err != nil {
+			return err
+		}
+		if err := poc.encodeValue(item, buf, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeSparse 解码稀疏 list，还原为普通的 list
+func (poc *Poculum) decodeSparse(reader *bytes.Reader, depth int) ([]any, error) {
+	items, err := expectList(reader, "Sparse list")
+	if err != nil {
+		return nil, err
+	}
+	if items < 2 || items%2 != 0 {
+		return nil, newError(InvalidSparse, fmt.Sprintf("Sparse list has %d items", items))
+	}
+	if err := poc.checkItems(reader, items, "Sparse list"); err != nil {
+		return nil, err
+	}
+
+	lengthValue, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	length, ok := toUint64(lengthValue)
+	if !ok {
+		return nil, newError(InvalidSparse, fmt.Sprintf("Sparse list length must be an unsigned integer, got %T", lengthValue))
+	}
+	if limit := poc.sparseLimit(); length > uint64(limit) {
+		return nil, newError(DataTooLarge, fmt.Sprintf("Sparse list length too large: %d items (max %d)", length, limit))
+	}
+	if uint64(items/2-1) > length {
+		return nil, newError(InvalidSparse, "Sparse list has more entries than its length")
+	}
+
+	fill, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	arr := make([]any, length)
+	for i := range arr {
+		arr[i] = fill
+	}
+
+	next := uint64(0)
+	for i := 1; i < items/2; i++ {
+		indexValue, err := poc.decodeValue(reader, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		index, ok := toUint64(indexValue)
+		if !ok || index < next || index >= length {
+			return nil, newError(InvalidSparse, fmt.Sprintf("Invalid sparse list index: %v", indexValue))
+		}
+		next = index + 1
+
+		if arr[index], err = poc.decodeValue(reader, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	if poc.hooks.AfterDecode != nil {
+		for i := range arr {
+			arr[i] = poc.afterDecode(arr[i])
+		}
+	}
+	if poc.numericList != nil {
+		return poc.normalizeNumbers(arr)
+	}
+	return arr, nil
+}
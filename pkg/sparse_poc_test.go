@@ -0,0 +1,72 @@
+package poculum
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestSparseList(t *testing.T) {
+	vector := make([]float64, 1000)
+	vector[3] = 1.5
+	vector[999] = -2
+
+	poc := NewPoculum(WithSparseLists(0.5))
+	sparse, err := poc.Dump(vector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dense, _ := DumpPoculum(vector)
+	if len(sparse) >= len(dense)/10 {
+		t.Fatalf("sparse encoding too large: %d vs %d", len(sparse), len(dense))
+	}
+
+	var out []float64
+	if err := Unmarshal(sparse, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1000 || out[3] != 1.5 || out[999] != -2 || out[0] != 0 {
+		t.Fatalf("unexpected value: %v", out[:5])
+	}
+
+	// 零值比例不够时使用普通编码
+	mostlySet := []any{1, 2, 3, 4, 5, 6, 7, 0, nil}
+	data, _ := poc.Dump(mostlySet)
+	plain, _ := DumpPoculum(mostlySet)
+	if !bytes.Equal(data, plain) {
+		t.Fatal("expected dense encoding")
+	}
+}
+
+func TestSparseNegativeZero(t *testing.T) {
+	vector := make([]float64, 16)
+	vector[5] = math.Copysign(0, -1)
+
+	data, err := NewPoculum(WithSparseLists(0.5)).Dump(vector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []float64
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !math.Signbit(out[5]) || math.Signbit(out[4]) {
+		t.Fatalf("sign of zero lost: %v", out)
+	}
+}
+
+func TestSparseLengthLimit(t *testing.T) {
+	// 逻辑长度 2^32-1，只有填充值
+	data := []byte{typeExt, extSparse, 0x52, typeUInt32, 0xFF, 0xFF, 0xFF, 0xFF, typeNil}
+	if _, err := NewPoculum().Load(data); !IsKind(err, DataTooLarge) {
+		t.Fatalf("expected DataTooLarge, got %v", err)
+	}
+
+	data = []byte{typeExt, extSparse, 0x52, typeUInt16, 0x01, 0x00, typeNil}
+	if _, err := NewPoculum(WithMaxSparseLength(255)).Load(data); !IsKind(err, DataTooLarge) {
+		t.Fatalf("expected DataTooLarge, got %v", err)
+	}
+	if value, err := NewPoculum().Load(data); err != nil || len(value.([]any)) != 256 {
+		t.Fatalf("got %v, %v", value, err)
+	}
+}
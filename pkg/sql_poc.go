@@ -0,0 +1,91 @@
+package poculum
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// 数据库类型
+/*
+	database/sql 中的 NullString、NullInt64、NullTime、Null[T] 等类型按照 driver.Valuer 返回的值编码，
+Valid 为 false 时编码为 nil；解码时调用 sql.Scanner，数据中的 nil 得到 Valid 为 false 的值。
+从数据库扫描得到的行可以直接编码：
+
+	type User struct {
+		Name  string         `poculum:"name"`
+		Email sql.NullString `poculum:"email"` // 编码为字符串或者 nil
+	}
+
+其它实现 driver.Valuer 与 sql.Scanner 的类型默认仍然按照 struct 等原本的类型编码，
+使用 WithDriverValues() 之后同样按照 driver.Valuer 与 sql.Scanner 处理
+*/
+
+var (
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// WithDriverValues 任何实现 driver.Valuer 的类型按照 Value 返回的值编码，解码到实现 sql.Scanner 的类型时调用 Scan
+func WithDriverValues() ConfigOption {
+	return func(poc *Poculum) {
+		poc.driverValues = true
+	}
+}
+
+// usesDriverValue 判断 t 是否按照数据库的值处理
+func (poc *Poculum) usesDriverValue(t reflect.Type) bool {
+	if poc.driverValues {
+		return true
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.PkgPath() == "database/sql"
+}
+
+// encodeDriverValue 编码实现 driver.Valuer 的值，返回是否处理了该值
+func (poc *Poculum) encodeDriverValue(value any, buf *bytes.Buffer, depth int) (bool, error) {
+	valuer, ok := value.(driver.Valuer)
+	if !ok || !poc.usesDriverValue(reflect.TypeOf(value)) {
+		return false, nil
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return true, buf.WriteByte(typeNil)
+	}
+	v, err := valuer.Value()
+	if err != nil {
+		return true, wrapError(UnsupportedType, fmt.Sprintf("Cannot get driver value of %T", value), err)
+	}
+	if reflect.TypeOf(v) == reflect.TypeOf(value) {
+		return true, newError(UnsupportedType, fmt.Sprintf("Driver value of %T returns itself", value))
+	}
+	return true, poc.encodeValue(v, buf, depth)
+}
+
+// assignScanner 解码到实现 sql.Scanner 的目标，返回是否处理了该目标
+func (poc *Poculum) assignScanner(dst reflect.Value, src any, path string) (bool, error) {
+	if !dst.CanAddr() || !reflect.PointerTo(dst.Type()).Implements(scannerType) || !poc.usesDriverValue(dst.Type()) {
+		return false, nil
+	}
+	// 转换为 driver.Value 中的整数与浮点数类型
+	switch v := src.(type) {
+	case float32:
+		src = float64(v)
+	case uint64:
+		if v <= math.MaxInt64 {
+			src = int64(v)
+		}
+	default:
+		if n, ok := toInt64(src); ok {
+			src = n
+		}
+	}
+	if err := dst.Addr().Interface().(sql.Scanner).Scan(src); err != nil {
+		return true, wrapError(TypeMismatch, fmt.Sprintf("%s: cannot scan %T into %s", displayPath(path), src, dst.Type()), err)
+	}
+	return true, nil
+}
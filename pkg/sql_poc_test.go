@@ -0,0 +1,89 @@
+package poculum
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type sqlRow struct {
+	Name    sql.NullString    `poculum:"name"`
+	Age     sql.NullInt64     `poculum:"age"`
+	Score   sql.NullFloat64   `poculum:"score"`
+	Seen    sql.NullTime      `poculum:"seen"`
+	Level   sql.Null[uint8]   `poculum:"level"`
+	Comment *sql.NullString   `poculum:"comment"`
+	Tags    []sql.NullString  `poculum:"tags"`
+	Extra   map[string]string `poculum:"extra"`
+}
+
+func TestSQLNullTypes(t *testing.T) {
+	seen := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	in := sqlRow{
+		Name:  sql.NullString{String: "ann", Valid: true},
+		Age:   sql.NullInt64{Int64: 30, Valid: true},
+		Seen:  sql.NullTime{Time: seen, Valid: true},
+		Level: sql.Null[uint8]{V: 3, Valid: true},
+		Tags:  []sql.NullString{{String: "x", Valid: true}, {}},
+		Extra: map[string]string{"k": "v"},
+	}
+	data, err := DumpPoculum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadPoculum(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := m.(map[string]any)
+	if row["name"] != "ann" || row["age"] != int64(30) || row["score"] != nil || row["comment"] != nil || row["level"] != int64(3) {
+		t.Fatalf("wire values: %v", row)
+	}
+	if tags := row["tags"].([]any); tags[0] != "x" || tags[1] != nil {
+		t.Fatalf("tags: %v", tags)
+	}
+
+	var out sqlRow
+	out.Score = sql.NullFloat64{Float64: 1, Valid: true}
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Score.Valid || out.Comment != nil {
+		t.Fatalf("nil should decode as invalid: %+v", out)
+	}
+	out.Score = sql.NullFloat64{}
+	if !reflect.DeepEqual(out, sqlRow{Name: in.Name, Age: in.Age, Seen: in.Seen, Level: in.Level, Tags: in.Tags, Extra: in.Extra}) {
+		t.Fatalf("got %+v", out)
+	}
+
+	// 整数可以扫描到 NullFloat64，字符串不能扫描到 NullInt64
+	var f sql.NullFloat64
+	if err := Unmarshal(MustDump(7), &f); err != nil || f.Float64 != 7 || !f.Valid {
+		t.Fatalf("got %+v, %v", f, err)
+	}
+	var n sql.NullInt64
+	if err := Unmarshal(MustDump("x"), &n); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+}
+
+type celsius float64
+
+func (c celsius) Value() (driver.Value, error) { return fmt.Sprintf("%.1fC", float64(c)), nil }
+
+func TestDriverValues(t *testing.T) {
+	if v, _ := LoadPoculum(MustDump(celsius(21.5))); v != 21.5 {
+		t.Fatalf("default encoding = %v", v)
+	}
+	data, err := NewPoculum(WithDriverValues()).Dump(celsius(21.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := LoadPoculum(data); v != "21.5C" {
+		t.Fatalf("driver value encoding = %v", v)
+	}
+}
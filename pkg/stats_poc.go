@@ -0,0 +1,192 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// TypeStat 一种线上类型的统计
+type TypeStat struct {
+	Count       int // 值的个数
+	Bytes       int // 占用的总字节数，包括类型字节与长度字段，list、map 与扩展类型不包括其中的值
+	HeaderBytes int // 其中类型字节与长度字段的字节数
+}
+
+func (s *TypeStat) add(header, data int) {
+	s.Count++
+	s.Bytes += header + data
+	s.HeaderBytes += header
+}
+
+// TypeHistogram 按照线上类型统计编码数据，由 Stats 生成，用于判断打包数组或者字符串表等编码方式能否减小数据
+type TypeHistogram struct {
+	Total int                 // 数据的总字节数
+	Types map[string]TypeStat // 键为线上类型的名称，例如 "uint8"、"fixstr"、"str16"、"list32"、"ext"，包括 map 的键
+
+	Keys TypeStat // map 的键，同时计入 Types
+
+	// RepeatedStrings 重复出现的字符串（包括 map 的键），每个不同的字符串第一次出现时不计入，
+	// Bytes 是使用字符串表最多可以节省的字节数
+	RepeatedStrings TypeStat
+
+	// PackableLists 至少有 2 个元素并且所有元素都是同一种数值类型的 list，Count 是 list 的个数，
+	// Bytes 是这些元素占用的字节数，HeaderBytes 是其中元素类型字节的字节数，即打包数组最多可以节省的字节数
+	PackableLists TypeStat
+}
+
+// wireTypeName 返回类型字节对应的线上类型名称，与 typeName 不同，会区分长度字段的宽度
+func wireTypeName(typeByte byte) string {
+	switch {
+	case typeByte >= typeFixStringBase && typeByte <= typeFixStringBase+15:
+		return "fixstr"
+	case typeByte == typeString16:
+		return "str16"
+	case typeByte == typeString32:
+		return "str32"
+	case typeByte >= typeFixListBase && typeByte <= typeFixListBase+15:
+		return "fixlist"
+	case typeByte == typeList16:
+		return "list16"
+	case typeByte == typeList32:
+		return "list32"
+	case typeByte >= typeFixMapBase && typeByte <= typeFixMapBase+15:
+		return "fixmap"
+	case typeByte == typeMap16:
+		return "map16"
+	case typeByte == typeMap32:
+		return "map32"
+	case typeByte == typeBytes8:
+		return "bytes8"
+	case typeByte == typeBytes16:
+		return "bytes16"
+	case typeByte == typeBytes32:
+		return "bytes32"
+	case typeByte == typeTrue:
+		return "true"
+	case typeByte == typeFalse:
+		return "false"
+	}
+	return typeName(typeByte)
+}
+
+// isNumericType 判断类型字节是否是整数或者浮点数
+func isNumericType(typeByte byte) bool {
+	switch typeByte {
+	case typeUInt8, typeUInt16, typeUInt32, typeUInt64, typeInt8, typeInt16, typeInt32, typeInt64, typeFloat32, typeFloat64:
+		return true
+	}
+	return false
+}
+
+// Stats 使用默认实例统计编码数据
+func Stats(data []byte) (TypeHistogram, error) {
+	return Default().Stats(data)
+}
+
+// Stats 统计 data 中每种线上类型的值的个数与字节数，以及重复的字符串与可以打包的数值 list，
+// 只读取类型与长度信息，不构造其中的值。data 可以包含多个首尾相接的值
+func (poc *Poculum) Stats(data []byte) (TypeHistogram, error) {
+	st := statser{
+		poc:     poc,
+		reader:  bytes.NewReader(data),
+		strings: make(map[string]bool),
+		hist:    TypeHistogram{Total: len(data), Types: make(map[string]TypeStat)},
+	}
+	for st.reader.Len() > 0 {
+		if _, err := st.value(0, false); err != nil {
+			return st.hist, err
+		}
+	}
+	return st.hist, nil
+}
+
+// statser 保存统计的状态
+type statser struct {
+	poc     *Poculum
+	reader  *bytes.Reader
+	strings map[string]bool // 出现过的字符串
+	hist    TypeHistogram
+}
+
+// value 统计下一个值，返回它的类型字节
+func (st *statser) value(depth int, isKey bool) (byte, error) {
+	if depth > st.poc.maxRecursionDepth {
+		return 0, newError(MaxRecursionDepth, "Maximum recursion depth exceeded while parsing nested structure")
+	}
+
+	start := st.reader.Len()
+	h, err := readHeader(st.reader)
+	if err != nil {
+		return 0, err
+	}
+	header := start - st.reader.Len()
+	name := wireTypeName(h.typeByte)
+
+	data := 0
+	if !h.isContainer() && h.typeByte != typeExt {
+		data = h.length
+	}
+	if data > st.reader.Len() {
+		return 0, newError(InsufficientData, fmt.Sprintf("%s data", name))
+	}
+	stat := st.hist.Types[name]
+	stat.add(header, data)
+	st.hist.Types[name] = stat
+	if isKey {
+		st.hist.Keys.add(header, data)
+	}
+
+	switch {
+	case h.typeByte == typeExt:
+		_, err := st.value(depth+1, false)
+		return h.typeByte, err
+
+	case h.isList():
+		var first byte
+		packable := h.length >= 2
+		itemsBytes := 0
+		for i := 0; i < h.length; i++ {
+			before := st.reader.Len()
+			typeByte, err := st.value(depth+1, false)
+			if err != nil {
+				return 0, err
+			}
+			itemsBytes += before - st.reader.Len()
+			if i == 0 {
+				first = typeByte
+			}
+			packable = packable && typeByte == first && isNumericType(typeByte)
+		}
+		if packable {
+			st.hist.PackableLists.Count++
+			st.hist.PackableLists.Bytes += itemsBytes
+			st.hist.PackableLists.HeaderBytes += h.length
+		}
+		return h.typeByte, nil
+
+	case h.isMap():
+		for i := 0; i < h.length; i++ {
+			if _, err := st.value(depth+1, true); err != nil {
+				return 0, err
+			}
+			if _, err := st.value(depth+1, false); err != nil {
+				return 0, err
+			}
+		}
+		return h.typeByte, nil
+	}
+
+	if typeName(h.typeByte) == "string" {
+		s := make([]byte, data)
+		st.reader.Read(s)
+		if st.strings[string(s)] {
+			st.hist.RepeatedStrings.add(header, data)
+		} else {
+			st.strings[string(s)] = true
+		}
+		return h.typeByte, nil
+	}
+	_, err = st.reader.Seek(int64(data), io.SeekCurrent)
+	return h.typeByte, err
+}
@@ -0,0 +1,53 @@
+package poculum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	rows := []any{
+		map[string]any{"id": 1, "tag": "a"},
+		map[string]any{"id": 2, "tag": "a"},
+	}
+	data := MustDump(map[string]any{"rows": rows, "ids": []any{1, 2, 3}})
+	hist, err := Stats(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hist.Total != len(data) {
+		t.Errorf("total = %d", hist.Total)
+	}
+	if got := hist.Types["fixmap"]; got.Count != 3 || got.Bytes != 3 {
+		t.Errorf("fixmap = %+v", got)
+	}
+	if got := hist.Types["uint32"]; got.Count != 5 || got.Bytes != 25 {
+		t.Errorf("uint32 = %+v", got)
+	}
+	if hist.Keys.Count != 6 {
+		t.Errorf("keys = %+v", hist.Keys)
+	}
+	// 第二行的 "id"、"tag" 与 "a" 是重复的字符串
+	if got := hist.RepeatedStrings; got.Count != 3 || got.Bytes != 3+4+2 {
+		t.Errorf("repeated = %+v", got)
+	}
+	if got := hist.PackableLists; got.Count != 1 || got.Bytes != 15 || got.HeaderBytes != 3 {
+		t.Errorf("packable = %+v", got)
+	}
+
+	sum := 0
+	for _, s := range hist.Types {
+		sum += s.Bytes
+	}
+	if sum != len(data) {
+		t.Errorf("type bytes add up to %d, want %d", sum, len(data))
+	}
+}
+
+func TestStatsExtChain(t *testing.T) {
+	// 扩展类型头部的链计入嵌套深度，不会耗尽栈
+	data := append(bytes.Repeat([]byte{typeExt, extSet}, 1<<16), typeNil)
+	if _, err := NewPoculumSecure().Stats(data); !IsKind(err, MaxRecursionDepth) {
+		t.Fatalf("expected MaxRecursionDepth, got %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package poculum
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// Decoder 从 io.Reader 中依次读取首尾相接的编码值
+//
+// 每次只读取一个完整值所需的字节，读取过程中的 I/O 错误被包装在 PoculumError 中，
+// 可以使用 errors.Is 判断 io.ErrUnexpectedEOF 或者网络超时等底层错误。
+// ReadRaw 与 Decode 需要把整个值读入内存，Forward 只缓冲单个字符串，可以用于转发很大的数据
+type Decoder struct {
+	poc *Poculum
+	r   *bufio.Reader
+	buf []byte
+}
+
+// NewDecoder 使用默认实例创建从 r 读取的 Decoder
+func NewDecoder(r io.Reader) *Decoder {
+	return Default().NewDecoder(r)
+}
+
+// NewDecoder 创建从 r 读取的 Decoder
+func (poc *Poculum) NewDecoder(r io.Reader) *Decoder {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Decoder{poc: poc, r: br}
+}
+
+// ReadRaw 读取下一个值的原始字节，没有更多的值时返回 io.EOF
+func (d *Decoder) ReadRaw() (RawMessage, error) {
+	d.buf = d.buf[:0]
+	if _, err := d.r.Peek(1); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, wrapError(IOError, "read value", err)
+	}
+	if err := d.readValue(0); err != nil {
+		return nil, err
+	}
+	return append(RawMessage(nil), d.buf...), nil
+}
+
+// Decode 读取下一个值并解码到 dst 中，没有更多的值时返回 io.EOF
+func (d *Decoder) Decode(dst any) error {
+	raw, err := d.ReadRaw()
+	if err != nil {
+		return err
+	}
+	return d.poc.Unmarshal(raw, dst)
+}
+
+// read 读取 n 个字节追加到缓冲区，数据提前结束时返回 io.ErrUnexpectedEOF
+func (d *Decoder) read(n int, what string) error {
+	if d.poc.maxInputSize > 0 && len(d.buf)+n > d.poc.maxInputSize {
+		return newError(DataTooLarge, fmt.Sprintf("Value too large: more than %d bytes", d.poc.maxInputSize))
+	}
+	start := len(d.buf)
+	// 分块读取，伪造的长度不会导致一次巨大的内存分配
+	for n > 0 {
+		chunk := min(n, 64<<10)
+		d.buf = append(d.buf, make([]byte, chunk)...)
+		if _, err := io.ReadFull(d.r, d.buf[len(d.buf)-chunk:]); err != nil {
+			d.buf = d.buf[:start]
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return wrapError(InsufficientData, what, err)
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// lengthBytes 返回类型字节之后长度字段（或者扩展标识）的字节数
+func lengthBytes(typeByte byte) int {
+	switch typeByte {
+	case typeExt, typeBytes8:
+		return 1
+	case typeString16, typeList16, typeMap16, typeBytes16:
+		return 2
+	case typeString32, typeList32, typeMap32, typeBytes32:
+		return 4
+	}
+	return 0
+}
+
+// readValue 读取一个完整值追加到缓冲区
+func (d *Decoder) readValue(depth int) error {
+	if depth > d.poc.maxRecursionDepth {
+		return newError(MaxRecursionDepth, "Maximum recursion depth exceeded while reading nested structure")
+	}
+
+	start := len(d.buf)
+	if err := d.read(1, "type byte"); err != nil {
+		return err
+	}
+	if err := d.read(lengthBytes(d.buf[start]), "length"); err != nil {
+		return err
+	}
+	h, err := readHeader(bytes.NewReader(d.buf[start:]))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case h.typeByte == typeExt:
+		return d.readValue(depth + 1)
+	case h.isContainer():
+		if h.length > d.poc.maxContainerItems {
+			return newError(DataTooLarge, fmt.Sprintf("Container length too large: %d items (max %d)", h.length, d.poc.maxContainerItems))
+		}
+		items := h.length
+		if h.isMap() {
+			items *= 2
+		}
+		for i := 0; i < items; i++ {
+			if err := d.readValue(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return d.read(h.length, typeName(h.typeByte)+" data")
+}
+
+// Forward 读取下一个值并在读取的同时写入 w，返回写入的字节数，没有更多的值时返回 io.EOF。
+//
+// 转发的过程中检查值的结构：嵌套深度、元素个数、字符串长度、字符串的 UTF-8（可信配置除外）以及 map 的键必须是字符串，
+// 不检查扩展类型的内容。内存中只保留 bufio.Reader 的缓冲区以及当前的字符串，list、map 与字节数据不会整个读入内存，
+// 代理可以边检查边转发大小远超内存的数据。出错时已经写入 w 的部分不会撤回，w 可以是 io.Discard
+func (d *Decoder) Forward(w io.Writer) (int64, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		return 0, wrapError(IOError, "read value", err)
+	}
+	f := forwarder{d: d, w: w}
+	err := f.value(0, false)
+	return f.n, err
+}
+
+// forwarder 保存 Forward 的状态
+type forwarder struct {
+	d      *Decoder
+	w      io.Writer
+	n      int64   // 已经写入的字节数
+	header [5]byte // 类型字节与长度字段
+}
+
+// value 转发一个值，key 为 true 时值必须是字符串
+func (f *forwarder) value(depth int, key bool) error {
+	poc := f.d.poc
+	if depth > poc.maxRecursionDepth {
+		return newError(MaxRecursionDepth, "Maximum recursion depth exceeded while reading nested structure")
+	}
+
+	typeByte, err := f.d.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return wrapError(InsufficientData, "type byte", err)
+	}
+	f.header[0] = typeByte
+	header := f.header[:1+lengthBytes(typeByte)]
+	if _, err := io.ReadFull(f.d.r, header[1:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return wrapError(InsufficientData, "length", err)
+	}
+	h, err := readHeader(bytes.NewReader(header))
+	if err != nil {
+		return err
+	}
+	isString := typeName(h.typeByte) == "string"
+	if key && !isString {
+		return newError(UnsupportedType, "Object key must be string")
+	}
+	if err := f.write(header); err != nil {
+		return err
+	}
+
+	switch {
+	case h.typeByte == typeExt:
+		return f.value(depth+1, false)
+	case h.isContainer():
+		if h.length > poc.maxContainerItems {
+			return newError(DataTooLarge, fmt.Sprintf("Container length too large: %d items (max %d)", h.length, poc.maxContainerItems))
+		}
+		items := h.length
+		if h.isMap() {
+			items *= 2
+		}
+		for i := 0; i < items; i++ {
+			if err := f.value(depth+1, h.isMap() && i%2 == 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	case isString:
+		if h.length > poc.maxStringSize {
+			return newError(DataTooLarge, fmt.Sprintf("String length too large: %d bytes (max %d)", h.length, poc.maxStringSize))
+		}
+		// 字符串需要完整地检查 UTF-8，读入缓冲区之后再写入
+		f.d.buf = f.d.buf[:0]
+		if err := f.d.read(h.length, "string data"); err != nil {
+			return err
+		}
+		if !poc.trusted && !utf8.Valid(f.d.buf) {
+			return newError(Utf8Error, "Invalid UTF-8 string")
+		}
+		return f.write(f.d.buf)
+	}
+	return f.copy(h.length, typeName(h.typeByte)+" data")
+}
+
+// copy 分块转发 n 个字节
+func (f *forwarder) copy(n int, what string) error {
+	var chunk [32 << 10]byte
+	for n > 0 {
+		m := min(n, len(chunk))
+		if _, err := io.ReadFull(f.d.r, chunk[:m]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return wrapError(InsufficientData, what, err)
+		}
+		if err := f.write(chunk[:m]); err != nil {
+			return err
+		}
+		n -= m
+	}
+	return nil
+}
+
+// write 写入 w 并检查 maxInputSize
+func (f *forwarder) write(p []byte) error {
+	if limit := f.d.poc.maxInputSize; limit > 0 && f.n+int64(len(p)) > int64(limit) {
+		return newError(DataTooLarge, fmt.Sprintf("Value too large: more than %d bytes", limit))
+	}
+	n, err := f.w.Write(p)
+	f.n += int64(n)
+	if err != nil {
+		return wrapError(IOError, "write value", err)
+	}
+	return nil
+}
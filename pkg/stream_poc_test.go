@@ -0,0 +1,107 @@
+package poculum
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoderStream(t *testing.T) {
+	var stream bytes.Buffer
+	for _, v := range []any{"a", map[string]any{"n": uint8(1)}, []any{true, nil}} {
+		stream.Write(MustDump(v))
+	}
+
+	dec := NewDecoder(iotest.OneByteReader(&stream))
+	var values []any
+	for {
+		var v any
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+	}
+	if len(values) != 3 || values[0] != "a" {
+		t.Fatalf("got %v", values)
+	}
+}
+
+func TestErrorCause(t *testing.T) {
+	data := MustDump(map[string]any{"key": "value"})
+	_, err := NewDecoder(bytes.NewReader(data[:len(data)-2])).ReadRaw()
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	var pe *PoculumError
+	if !errors.As(err, &pe) || pe.Kind != InsufficientData {
+		t.Fatalf("expected PoculumError, got %T", err)
+	}
+
+	timeout := errors.New("i/o timeout")
+	_, err = NewDecoder(iotest.ErrReader(timeout)).ReadRaw()
+	if !errors.Is(err, timeout) {
+		t.Fatalf("underlying error lost: %v", err)
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	_, err := LoadPoculum([]byte{0xEE})
+	if !IsKind(err, UnknownTypeID) {
+		t.Fatalf("expected UnknownTypeID, got %v", err)
+	}
+	// 字符串格式与引入 ErrorKind 之前一致
+	if got := err.Error(); got != "UnknownTypeId: Unknown type identifier: 0xee" {
+		t.Fatalf("got %q", got)
+	}
+	if ErrorKind(200).String() != "ErrorKind(200)" {
+		t.Fatal("unexpected name for unknown kind")
+	}
+}
+
+func TestDecoderForward(t *testing.T) {
+	blob := bytes.Repeat([]byte{0xAB}, 1<<20)
+	first := MustDump(map[string]any{"name": "big", "blob": blob, "items": []any{uint8(1), "x", nil}})
+	second := MustDump("tail")
+	dec := NewDecoder(io.MultiReader(bytes.NewReader(first), bytes.NewReader(second)))
+
+	var out bytes.Buffer
+	n, err := dec.Forward(&out)
+	if err != nil || n != int64(len(first)) || !bytes.Equal(out.Bytes(), first) {
+		t.Fatalf("forward: n=%d err=%v", n, err)
+	}
+	// 字节数据分块转发，没有进入 Decoder 的缓冲区
+	if cap(dec.buf) >= len(blob) {
+		t.Fatalf("buffer grew to %d bytes", cap(dec.buf))
+	}
+	out.Reset()
+	if _, err := dec.Forward(&out); err != nil || !bytes.Equal(out.Bytes(), second) {
+		t.Fatalf("second value: %v", err)
+	}
+	if _, err := dec.Forward(&out); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	invalid := []struct {
+		data []byte
+		kind ErrorKind
+	}{
+		{[]byte{0x32, 0xFF, 0xFE}, Utf8Error},             // 不合法的 UTF-8
+		{[]byte{0x71, 0x01, 0x01, 0x01}, UnsupportedType}, // map 的键不是字符串
+		{first[:len(first)-3], InsufficientData},          // 截断
+	}
+	for _, c := range invalid {
+		if _, err := NewDecoder(bytes.NewReader(c.data)).Forward(io.Discard); !IsKind(err, c.kind) {
+			t.Fatalf("%x: expected %s, got %v", c.data[:min(len(c.data), 8)], c.kind, err)
+		}
+	}
+	deep := MustDump([]any{[]any{[]any{}}})
+	if _, err := NewPoculum(WithMaxRecursionDepth(1)).NewDecoder(bytes.NewReader(deep)).Forward(io.Discard); !IsKind(err, MaxRecursionDepth) {
+		t.Fatalf("expected MaxRecursionDepth, got %v", err)
+	}
+}
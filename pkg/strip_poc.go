@@ -0,0 +1,165 @@
+package poculum
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// 删除字段
+/*
+	StripPaths 写出删除了指定字段之后的文档，例如在归档之前删除个人信息：
+
+	err := poculum.StripPaths(w, data, "user.email", "orders.*.card", "debug")
+
+路径使用与 GetRaw 相同的语法，段为 * 时匹配 map 的任意键与 list 的任意下标；路径指向 list 的元素时删除该元素。
+不包含要删除的字段的值按照原始字节复制，不会解码再编码，只有包含被删除字段的 map 与 list 重新写入头部。
+扩展类型（Set、张量等）作为一个整体处理，不会进入其中删除字段。不存在的路径被忽略
+*/
+
+// StripPaths 使用默认实例把删除了 paths 所指向的字段之后的 src 写入 dst
+func StripPaths(dst io.Writer, src []byte, paths ...string) error {
+	return Default().StripPaths(dst, src, paths...)
+}
+
+// StripPaths 把删除了 paths 所指向的字段之后的 src 写入 dst。写入之前先检查 src 是一个完整的值，
+// src 不合法时不会写入任何内容
+func (poc *Poculum) StripPaths(dst io.Writer, src []byte, paths ...string) error {
+	root := &pathNode{}
+	for _, path := range paths {
+		if path == "" {
+			return newError(InvalidPath, "Cannot strip the root value")
+		}
+		node := root
+		for _, seg := range splitPath(path) {
+			if node.children == nil {
+				node.children = map[string]*pathNode{}
+			}
+			if node.children[seg] == nil {
+				node.children[seg] = &pathNode{}
+			}
+			node = node.children[seg]
+		}
+		node.terminal, node.path = true, path
+	}
+	if err := poc.validRaw(src); err != nil {
+		return err
+	}
+
+	s := stripper{poc: poc, src: src, reader: bytes.NewReader(src), w: dst}
+	if err := s.value(root, 0); err != nil {
+		return err
+	}
+	return s.err
+}
+
+// match 返回段 seg 对应的子节点，精确的段与 * 都存在时合并两者
+func (n *pathNode) match(seg string) *pathNode {
+	return mergeNodes(n.children[seg], n.children["*"])
+}
+
+// mergeNodes 合并两个路径树，任意一个为 nil 时返回另一个
+func mergeNodes(a, b *pathNode) *pathNode {
+	if a == nil || a == b {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	merged := &pathNode{terminal: a.terminal || b.terminal, children: make(map[string]*pathNode, len(a.children)+len(b.children))}
+	for seg, child := range a.children {
+		merged.children[seg] = child
+	}
+	for seg, child := range b.children {
+		merged.children[seg] = mergeNodes(merged.children[seg], child)
+	}
+	return merged
+}
+
+// stripper 保存 StripPaths 的状态
+type stripper struct {
+	poc    *Poculum
+	src    []byte
+	reader *bytes.Reader
+	w      io.Writer
+	err    error // 第一个写入错误
+}
+
+// strippedItem list 的元素或者 map 的一项在 src 中的位置
+type strippedItem struct {
+	start int // 键（list 为值）的开头
+	value int // 值的开头
+	end   int
+	node  *pathNode
+}
+
+func (s *stripper) offset() int {
+	return len(s.src) - s.reader.Len()
+}
+
+func (s *stripper) write(p []byte) {
+	if s.err != nil {
+		return
+	}
+	if _, err := s.w.Write(p); err != nil {
+		s.err = wrapError(IOError, "write stripped document", err)
+	}
+}
+
+// value 写出从当前位置开始、对应路径树节点 node 的值
+func (s *stripper) value(node *pathNode, depth int) error {
+	start := s.offset()
+	h, err := readHeader(s.reader)
+	if err != nil {
+		return err
+	}
+	if node == nil || len(node.children) == 0 || !h.isContainer() {
+		s.reader.Seek(int64(start), io.SeekStart)
+		if err := s.poc.skipValue(s.reader, depth); err != nil {
+			return err
+		}
+		s.write(s.src[start:s.offset()])
+		return nil
+	}
+
+	// 先找出保留的项，才能写出新的元素个数
+	items := make([]strippedItem, 0, h.length)
+	for i := 0; i < h.length; i++ {
+		item := strippedItem{start: s.offset()}
+		seg := strconv.Itoa(i)
+		if h.isMap() {
+			if seg, err = s.poc.readKey(s.reader); err != nil {
+				return err
+			}
+		}
+		item.value = s.offset()
+		if err := s.poc.skipValue(s.reader, depth+1); err != nil {
+			return err
+		}
+		item.end = s.offset()
+		item.node = node.match(seg)
+		if item.node == nil || !item.node.terminal {
+			items = append(items, item)
+		}
+	}
+
+	var header []byte
+	if h.isMap() {
+		header = appendHeader(nil, typeFixMapBase, typeMap16, typeMap32, len(items))
+	} else {
+		header = appendHeader(nil, typeFixListBase, typeList16, typeList32, len(items))
+	}
+	s.write(header)
+	for _, item := range items {
+		if item.node == nil {
+			s.write(s.src[item.start:item.end])
+			continue
+		}
+		s.write(s.src[item.start:item.value])
+		s.reader.Seek(int64(item.value), io.SeekStart)
+		if err := s.value(item.node, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
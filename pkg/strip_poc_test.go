@@ -0,0 +1,54 @@
+package poculum
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestStripPaths(t *testing.T) {
+	doc := map[string]any{
+		"id":    uint8(1),
+		"user":  map[string]any{"name": "ann", "email": "a@example.com"},
+		"debug": "trace",
+		"orders": []any{
+			map[string]any{"sku": "x", "card": "4111"},
+			map[string]any{"sku": "y", "card": "5500", "note": "gift"},
+		},
+		"tags": []any{"a", "b", "c"},
+		"set":  Set{"email"},
+	}
+	var buf bytes.Buffer
+	if err := StripPaths(&buf, MustDump(doc), "user.email", "orders.*.card", "orders.1.note", "debug", "tags.1", "set.email", "missing.path"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadPoculum(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"id":     uint8(1),
+		"user":   map[string]any{"name": "ann"},
+		"orders": []any{map[string]any{"sku": "x"}, map[string]any{"sku": "y"}},
+		"tags":   []any{"a", "c"},
+		"set":    Set{"email"}, // 扩展类型不进入
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v", got)
+	}
+
+	// 没有删除任何字段时输出与输入相同
+	data := MustDump(doc)
+	buf.Reset()
+	if err := StripPaths(&buf, data, "nothing"); err != nil || !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("unchanged document differs: %v", err)
+	}
+
+	buf.Reset()
+	if err := StripPaths(&buf, data[:len(data)-1], "debug"); err == nil || buf.Len() != 0 {
+		t.Fatalf("invalid input: err=%v, wrote %d bytes", err, buf.Len())
+	}
+	if err := StripPaths(&buf, data, ""); !IsKind(err, InvalidPath) {
+		t.Fatalf("expected InvalidPath, got %v", err)
+	}
+}
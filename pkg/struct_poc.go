@@ -0,0 +1,305 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// struct 标签说明
+/*
+	struct 编码为 map，键默认为字段名，可以使用 poculum 标签修改：
+
+	type User struct {
+		Name  string `poculum:"name"`                    // 键为 name
+		Age   int    `poculum:"age,default=18"`          // 解码时数据中没有 age 则使用 18
+		Email string `poculum:"email,alias=mail"`        // 解码时 email 不存在则尝试旧的键名 mail
+		Token string `poculum:"-"`                       // 忽略该字段
+		Score int    `poculum:"score,required,min=0,max=100"` // 解码时必须存在，并且在 0~100 之间
+		SSN   string `poculum:"ssn,redact"`              // 启用 WithRedaction 时脱敏
+		Born  time.Time `poculum:"born,format=unix_ms"`   // 编码为 Unix 毫秒数，见 format_poc.go
+	}
+
+alias 可以出现多次，default 的值不能包含逗号，只支持布尔、整数、浮点数与字符串类型的字段，
+其它类型的默认值可以由 struct 的指针实现 Defaulter 提供，default 标签优先。
+required 要求解码的数据中存在该字段并且不为 nil；min、max 对数字字段限制取值范围，
+对字符串、切片、map 字段限制长度，这些检查只在 Unmarshal 时进行。
+未导出的字段总是被忽略
+
+匿名嵌入的 struct（或者 struct 指针）与 encoding/json 一样把字段提升到外层的 map 中：
+外层的同名字段优先，同一层中的同名字段只保留唯一一个带有标签名称的，否则都被忽略。
+嵌入字段的标签带有名称或者 noinline 选项时不提升，作为普通字段编码，键默认为类型名称：
+
+	type Audited struct {
+		Base                            // Base 的字段提升到外层
+		Meta Meta                       // 普通字段
+		Info `poculum:"info"`            // 不提升，键为 info
+		Tags `poculum:",noinline"`       // 不提升，键为 Tags
+	}
+
+编码时位于 nil 嵌入指针中的字段被跳过，解码时按需分配嵌入指针
+*/
+
+// fieldInfo 描述 struct 中的一个字段
+type fieldInfo struct {
+	name       string // 编码时使用的键
+	index      []int  // 在 struct 中的下标，提升的字段依次包含每一层的下标
+	named      bool   // 标签中指定了名称
+	noInline   bool   // 嵌入的 struct 不提升字段
+	redact     bool   // 启用 WithRedaction 时编码为脱敏后的值
+	format     string // 字段在数据中的表示方式，见 format_poc.go
+	aliases    []string
+	defaultTag string
+	hasDefault bool
+	required   bool
+	min, max   *float64
+	tagErr     error // 标签中存在无法解析的选项
+}
+
+// parseTag 解析 poculum 标签，返回 false 代表忽略该字段
+func parseTag(field reflect.StructField) (fieldInfo, bool) {
+	info := fieldInfo{name: field.Name}
+	tag, ok := field.Tag.Lookup("poculum")
+	if !ok {
+		return info, true
+	}
+	if tag == "-" {
+		return info, false
+	}
+
+	parts := strings.Split(tag, ",")
+	if parts[0] != "" {
+		info.name = parts[0]
+		info.named = true
+	}
+	for _, opt := range parts[1:] {
+		key, value, _ := strings.Cut(opt, "=")
+		switch key {
+		case "default":
+			info.defaultTag = value
+			info.hasDefault = true
+		case "alias":
+			if value != "" {
+				info.aliases = append(info.aliases, value)
+			}
+		case "required":
+			info.required = true
+		case "noinline":
+			info.noInline = true
+		case "redact":
+			info.redact = true
+		case "format":
+			// 不支持的 format 在编码时由 formatValue 报告
+			info.format = value
+			if err := checkFormat(value, field.Type); err != nil {
+				info.tagErr = err
+			}
+		case "min", "max":
+			bound, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				info.tagErr = fmt.Errorf("invalid %s %q", key, value)
+				continue
+			}
+			if key == "min" {
+				info.min = &bound
+			} else {
+				info.max = &bound
+			}
+		}
+	}
+	return info, true
+}
+
+// structFields 缓存每个 struct 类型的字段信息
+var structFields sync.Map // map[reflect.Type][]fieldInfo
+
+// cachedFields 返回 struct 类型的字段信息
+func cachedFields(t reflect.Type) []fieldInfo {
+	if fields, ok := structFields.Load(t); ok {
+		return fields.([]fieldInfo)
+	}
+
+	actual, _ := structFields.LoadOrStore(t, collectFields(t))
+	return actual.([]fieldInfo)
+}
+
+// collectFields 逐层收集 struct 的字段，并提升匿名嵌入 struct 的字段
+func collectFields(t reflect.Type) []fieldInfo {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []fieldInfo
+	seen := make(map[string]bool)          // 较浅的层中已经出现的键
+	visited := make(map[reflect.Type]bool) // 避免重复展开同一个类型
+	next := []embedded{{typ: t}}
+	for depth := 0; len(next) > 0; depth++ {
+		current := next
+		next = nil
+
+		var level []fieldInfo
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				field := e.typ.Field(i)
+				ft := field.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				promotable := field.Anonymous && ft.Kind() == reflect.Struct
+				if !field.IsExported() && !(promotable && field.Type.Kind() != reflect.Pointer) {
+					// 未导出的嵌入 struct 指针无法在解码时分配，与其它未导出字段一样忽略
+					continue
+				}
+				info, ok := parseTag(field)
+				if !ok {
+					continue
+				}
+
+				index := append(append([]int(nil), e.index...), i)
+				if promotable && !info.named && !info.noInline {
+					next = append(next, embedded{typ: ft, index: index})
+					continue
+				}
+				if !field.IsExported() {
+					continue
+				}
+				info.index = index
+				level = append(level, info)
+			}
+		}
+
+		if depth == 0 {
+			// 外层的同名字段由 structToMap 报告错误
+			fields = append(fields, level...)
+			for _, f := range level {
+				seen[f.name] = true
+			}
+			continue
+		}
+		fields = append(fields, dominantFields(level, seen)...)
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i].index, fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields
+}
+
+// dominantFields 处理同一层中提升的同名字段，并把这一层的键加入 seen
+func dominantFields(level []fieldInfo, seen map[string]bool) []fieldInfo {
+	byName := make(map[string][]fieldInfo)
+	var names []string
+	for _, f := range level {
+		if seen[f.name] {
+			continue
+		}
+		if _, ok := byName[f.name]; !ok {
+			names = append(names, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	var fields []fieldInfo
+	for _, name := range names {
+		seen[name] = true
+		candidates := byName[name]
+		if len(candidates) == 1 {
+			fields = append(fields, candidates[0])
+			continue
+		}
+		var tagged []fieldInfo
+		for _, f := range candidates {
+			if f.named {
+				tagged = append(tagged, f)
+			}
+		}
+		if len(tagged) == 1 {
+			fields = append(fields, tagged[0])
+		}
+	}
+	return fields
+}
+
+// fieldByIndex 返回提升的字段，途中遇到 nil 指针时返回 false
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// fieldByIndexAlloc 返回提升的字段，途中遇到 nil 指针时分配新的值
+func fieldByIndexAlloc(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
+
+// structToMap 把 struct 的字段收集到 map 中，字段的值不做转换
+func structToMap(rv reflect.Value) (map[string]any, error) {
+	fields := cachedFields(rv.Type())
+	values := make(map[string]any, len(fields))
+	for _, f := range fields {
+		if _, dup := values[f.name]; dup {
+			return nil, newError(UnsupportedType, fmt.Sprintf("Duplicate key %q in %s", f.name, rv.Type()))
+		}
+		field, ok := fieldByIndex(rv, f.index)
+		if !ok {
+			continue
+		}
+		value := field.Interface()
+		if isAbsentOption(value) {
+			continue
+		}
+		if f.format != "" {
+			formatted, err := formatValue(f.format, field)
+			if err != nil {
+				return nil, newError(InvalidTag, fmt.Sprintf("%s.%s: %v", rv.Type(), f.name, err))
+			}
+			value = formatted
+		}
+		values[f.name] = value
+	}
+	return values, nil
+}
+
+// encodeStruct 把 struct 编码为 map
+func (poc *Poculum) encodeStruct(rv reflect.Value, buf *bytes.Buffer, depth int) error {
+	values, err := structToMap(rv)
+	if err != nil {
+		return err
+	}
+	if poc.redactor != nil {
+		poc.redactFields(rv.Type(), values)
+	}
+	return poc.encodeMap(values, buf, depth)
+}
@@ -0,0 +1,670 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City string `poculum:"city"`
+}
+
+type testUser struct {
+	Name    string         `poculum:"name"`
+	Age     uint8          `poculum:"age"`
+	Tags    []string       `poculum:"tags"`
+	Scores  map[string]int `poculum:"scores"`
+	Home    *testAddress   `poculum:"home"`
+	Extra   any            `poculum:"extra"`
+	Secret  string         `poculum:"-"`
+	private int
+}
+
+func TestStructRoundTrip(t *testing.T) {
+	in := testUser{
+		Name:   "poc",
+		Age:    30,
+		Tags:   []string{"a", "b"},
+		Scores: map[string]int{"x": -1},
+		Home:   &testAddress{City: "c"},
+		Extra:  "e",
+		Secret: "s",
+	}
+	data, err := DumpPoculum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out testUser
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	in.Secret = ""
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestStructDefaultsAndAliases(t *testing.T) {
+	type v2 struct {
+		FullName string  `poculum:"full_name,alias=name,alias=nick"`
+		Retries  int     `poculum:"retries,default=3"`
+		Ratio    float64 `poculum:"ratio,default=0.5"`
+		Enabled  *bool   `poculum:"enabled,default=true"`
+	}
+
+	data, _ := DumpPoculum(map[string]any{"nick": "old", "retries": uint8(0)})
+	var out v2
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.FullName != "old" || out.Retries != 0 || out.Ratio != 0.5 || out.Enabled == nil || !*out.Enabled {
+		t.Fatalf("unexpected value: %+v", out)
+	}
+}
+
+func TestUnmarshalOverflow(t *testing.T) {
+	data, _ := DumpPoculum(map[string]any{"age": uint16(300)})
+	var out testUser
+	err := Unmarshal(data, &out)
+	if !IsKind(err, ValueOutOfRange) || !strings.Contains(err.Error(), "age: value 300 overflows uint8 (range 0..255)") {
+		t.Fatalf("expected overflow error, got %v", err)
+	}
+
+	type reading struct {
+		Temp  int8   `poculum:"temp"`
+		Count uint16 `poculum:"count"`
+		Delta int64  `poculum:"delta"`
+	}
+	poc := NewPoculum(WithSaturatingInts())
+	cases := []struct {
+		in   map[string]any
+		want reading
+	}{
+		{map[string]any{"temp": 1000, "count": -5, "delta": uint64(math.MaxUint64)}, reading{127, 0, math.MaxInt64}},
+		{map[string]any{"temp": -1000, "count": 70000, "delta": -1}, reading{-128, 65535, -1}},
+	}
+	for _, c := range cases {
+		var got reading
+		if err := poc.Unmarshal(MustDump(c.in), &got); err != nil || got != c.want {
+			t.Fatalf("%v: got %+v, %v", c.in, got, err)
+		}
+	}
+}
+
+func TestStructValidationTags(t *testing.T) {
+	type person struct {
+		Name string `poculum:"name,required,min=1"`
+		Age  int    `poculum:"age,required,min=0,max=150"`
+	}
+
+	cases := []struct {
+		input map[string]any
+		ok    bool
+	}{
+		{map[string]any{"name": "a", "age": uint8(30)}, true},
+		{map[string]any{"name": "a"}, false},
+		{map[string]any{"name": "a", "age": nil}, false},
+		{map[string]any{"name": "", "age": uint8(30)}, false},
+		{map[string]any{"name": "a", "age": int8(-1)}, false},
+		{map[string]any{"name": "a", "age": uint8(151)}, false},
+	}
+	for _, c := range cases {
+		data, _ := DumpPoculum(c.input)
+		var out person
+		if err := Unmarshal(data, &out); (err == nil) != c.ok {
+			t.Errorf("%v: unexpected error %v", c.input, err)
+		}
+	}
+}
+
+func TestEmbeddedPromotion(t *testing.T) {
+	type Base struct {
+		ID   int    `poculum:"id"`
+		Kind string `poculum:"kind"`
+	}
+	type Meta struct {
+		Owner string
+	}
+	type audit struct {
+		By string
+	}
+	type Doc struct {
+		Base
+		*Meta
+		audit
+		Kind  string `poculum:"kind"` // 外层字段优先
+		Extra Base   `poculum:",noinline"`
+	}
+
+	in := Doc{Base: Base{ID: 1, Kind: "hidden"}, Meta: &Meta{Owner: "ann"}, audit: audit{By: "bob"}, Kind: "doc"}
+	data, err := DumpPoculum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := LoadPoculum(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := value.(map[string]any)
+	if m["kind"] != "doc" || m["Owner"] != "ann" || m["By"] != "bob" || m["Base"] != nil {
+		t.Fatalf("unexpected map: %v", m)
+	}
+	if _, ok := m["Extra"].(map[string]any); !ok {
+		t.Fatalf("noinline field not kept as a map: %v", m["Extra"])
+	}
+
+	var out Doc
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.ID != 1 || out.Kind != "doc" || out.Base.Kind != "" || out.Meta == nil || out.Owner != "ann" || out.By != "bob" {
+		t.Fatalf("got %+v", out)
+	}
+
+	// nil 嵌入指针中的字段被跳过，解码时没有对应的键也不会分配
+	data, err = DumpPoculum(Doc{Kind: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = Doc{}
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Meta != nil {
+		t.Fatalf("Meta allocated for absent fields: %+v", out.Meta)
+	}
+}
+
+func TestCustomEncoder(t *testing.T) {
+	type User struct {
+		ID   uint32
+		Name string
+	}
+	type Post struct {
+		Author *User
+		Title  string
+	}
+	users := map[uint32]*User{7: {ID: 7, Name: "ann"}}
+
+	poc := NewPoculum()
+	RegisterEncoder(poc, func(u *User, e *Encoder) error {
+		return e.Encode(u.ID)
+	})
+	RegisterDecoder(poc, func(src any) (*User, error) {
+		id, ok := src.(uint32)
+		if !ok || users[id] == nil {
+			return nil, fmt.Errorf("unknown user %v", src)
+		}
+		return users[id], nil
+	})
+
+	data, err := poc.Dump(Post{Author: users[7], Title: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := GetRaw(data, "Author"); len(raw) != 5 {
+		t.Fatalf("Author encoded as %x", []byte(raw))
+	}
+
+	var out Post
+	if err := poc.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Author != users[7] || out.Title != "hi" {
+		t.Fatalf("got %+v", out)
+	}
+
+	bad, _ := DumpPoculum(map[string]any{"Author": uint32(8)})
+	if err := poc.Unmarshal(bad, &out); err == nil {
+		t.Fatal("expected decoder error")
+	}
+}
+
+func TestHooks(t *testing.T) {
+	type Reading struct {
+		Celsius float64 `poculum:"temp"`
+		Secret  string  `poculum:"secret"`
+	}
+	poc := NewPoculum(WithHooks(Hooks{
+		BeforeEncode: func(v any) any {
+			if m, ok := v.(map[string]any); ok {
+				delete(m, "secret")
+			}
+			return v
+		},
+		AfterDecode: func(v any) any {
+			if m, ok := v.(map[string]any); ok {
+				if c, ok := m["temp"].(float64); ok {
+					m["temp"] = c*9/5 + 32
+				}
+			}
+			return v
+		},
+	}))
+
+	data, err := poc.Dump([]any{map[string]any{"temp": 100.0, "secret": "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []Reading
+	if err := poc.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].Celsius != 212 || out[0].Secret != "" {
+		t.Fatalf("got %+v", out)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	defer SetDefault(nil)
+
+	SetDefault(NewPoculum(WithTimePrecision(time.Second)))
+	data, err := DumpPoculum(time.Unix(10, 600000000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := LoadPoculum(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := value.(time.Time); got.Unix() != 11 || got.Nanosecond() != 0 {
+		t.Fatalf("default instance not used: %v", got)
+	}
+
+	SetDefault(nil)
+	if Default() != standardPoculum {
+		t.Fatal("SetDefault(nil) did not restore the standard instance")
+	}
+}
+
+func TestWith(t *testing.T) {
+	type Color string
+	base := NewPoculum()
+	if err := RegisterEnum(base, Color("red"), Color("green")); err != nil {
+		t.Fatal(err)
+	}
+
+	strict := base.With(WithMaxStringSize(4))
+	if _, err := strict.Dump("too long"); err == nil {
+		t.Fatal("derived instance ignored the string limit")
+	}
+	if _, err := base.Dump("too long"); err != nil {
+		t.Fatalf("base instance affected by With: %v", err)
+	}
+
+	// 派生实例继承已注册的枚举
+	data, err := strict.Dump(Color("green"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[1] != 1 {
+		t.Fatalf("enum not inherited: %x", data)
+	}
+
+	RegisterEncoder(strict, func(c Color, e *Encoder) error { return e.Encode("c") })
+	if _, ok := base.encoders[reflect.TypeOf(Color(""))]; ok {
+		t.Fatal("registration on derived instance leaked into base")
+	}
+}
+
+func TestSecureProfile(t *testing.T) {
+	poc := NewPoculumSecure()
+
+	var nested any = "leaf"
+	for i := 0; i < 100; i++ {
+		nested = []any{nested}
+	}
+	data, err := DumpPoculum(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := poc.Load(data); err == nil {
+		t.Fatal("expected depth limit error")
+	}
+
+	// 声称 4GB 的字节数据不应该在检查之前分配内存
+	forged := []byte{typeBytes32, 0xFF, 0xFF, 0xFF, 0xFF, 0x00}
+	if _, err := poc.Load(forged); err == nil {
+		t.Fatal("expected error for forged bytes length")
+	}
+
+	small := NewPoculumSecure(WithMaxInputSize(8))
+	data, _ = DumpPoculum("a string longer than eight bytes")
+	if _, err := small.Load(data); err == nil {
+		t.Fatal("expected input size error")
+	}
+
+	// 头部声明的元素个数超过剩余数据时，在分配内存之前拒绝
+	headers := [][]byte{
+		{typeMap32, 0x00, 0x0F, 0xFF, 0xFF},
+		{typeExt, extSet, typeList32, 0x00, 0x0F, 0xFF, 0xFF},
+		{typeExt, extSparse, typeList32, 0x00, 0x0F, 0xFF, 0xFE},
+	}
+	for _, data := range headers {
+		if _, err := poc.Load(data); !IsKind(err, InsufficientData) {
+			t.Fatalf("%x: expected InsufficientData, got %v", data, err)
+		}
+	}
+}
+
+func TestTrustedProfile(t *testing.T) {
+	poc := NewPoculumTrusted()
+	data, err := poc.Dump(map[string]any{"name": "ann", "tags": Set{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := poc.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := value.(map[string]any); m["name"] != "ann" || len(m["tags"].(Set)) != 2 {
+		t.Fatalf("got %v", m)
+	}
+
+	// 可信配置不校验 UTF-8，默认配置会拒绝
+	invalid := string([]byte{0xff, 0xfe})
+	if _, err := poc.Dump(invalid); err != nil {
+		t.Fatalf("trusted profile validated UTF-8: %v", err)
+	}
+	if _, err := DumpPoculum(invalid); err == nil {
+		t.Fatal("default profile accepted invalid UTF-8")
+	}
+}
+
+func TestUnmarshalWithFields(t *testing.T) {
+	data, err := DumpPoculum(map[string]any{
+		"id":    uint8(7),
+		"blob":  bytes.Repeat([]byte{1}, 1024),
+		"user":  map[string]any{"name": "alice", "address": map[string]any{"city": "Paris"}},
+		"extra": []any{"a", "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	poc := NewPoculum(WithFields("id", "user.name", "user.address.city", "user.address"))
+	v, err := poc.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"id":   uint8(7),
+		"user": map[string]any{"name": "alice", "address": map[string]any{"city": "Paris"}},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("got %#v", v)
+	}
+
+	var dst struct {
+		ID   int    `poculum:"id"`
+		Blob []byte `poculum:"blob"`
+	}
+	if err := poc.Unmarshal(data, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst.ID != 7 || dst.Blob != nil {
+		t.Fatalf("got %+v", dst)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	type account struct {
+		Name string `poculum:"name"`
+		SSN  string `poculum:"ssn,redact"`
+	}
+	v := map[string]any{
+		"account": account{Name: "bob", SSN: "123-45-6789"},
+		"headers": map[string]string{"Authorization": "Bearer secret", "Accept": "*/*"},
+	}
+
+	plain, err := DumpPoculum(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(plain, []byte("123-45-6789")) {
+		t.Fatal("redact tag must not apply without WithRedaction")
+	}
+
+	data, err := NewPoculum(WithRedaction(nil, "headers.Authorization")).Dump(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"123-45-6789", "secret"} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Fatalf("encoded data contains %q", secret)
+		}
+	}
+	got, _ := LoadPoculum(data)
+	want := map[string]any{
+		"account": map[string]any{"name": "bob", "ssn": Redacted},
+		"headers": map[string]any{"Authorization": Redacted, "Accept": "*/*"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v", got)
+	}
+	if v["headers"].(map[string]string)["Authorization"] != "Bearer secret" {
+		t.Fatal("source value was modified")
+	}
+}
+
+func TestCaseInsensitiveKeys(t *testing.T) {
+	type profile struct {
+		UserName string `poculum:"username"`
+		Email    string `poculum:"email"`
+	}
+	data, _ := DumpPoculum(map[string]any{"userName": "bob", "email": "b@x", "EMAIL": "other"})
+
+	var p profile
+	if err := Unmarshal(data, &p); err != nil || p.UserName != "" {
+		t.Fatalf("default should be case-sensitive: %+v, %v", p, err)
+	}
+	poc := NewPoculum(WithCaseInsensitiveKeys())
+	if err := poc.Unmarshal(data, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.UserName != "bob" || p.Email != "b@x" {
+		t.Fatalf("got %+v", p)
+	}
+
+	data, _ = DumpPoculum(map[string]any{"UserName": "a", "USERNAME": "b"})
+	if err := poc.Unmarshal(data, &p); !IsKind(err, ValidationError) {
+		t.Fatalf("expected ambiguous key error, got %v", err)
+	}
+}
+
+func TestKeyMapper(t *testing.T) {
+	data, _ := DumpPoculum(map[string]any{"X-Name": "a", "X-Tags": []any{map[string]any{"X-Id": uint8(1)}}})
+	poc := NewPoculum(WithKeyMapper(func(key string) string {
+		return strings.ToLower(strings.TrimPrefix(key, "X-"))
+	}))
+	got, err := poc.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"name": "a", "tags": []any{map[string]any{"id": uint8(1)}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v", got)
+	}
+
+	data, _ = DumpPoculum(map[string]any{"A": 1, "a": 2})
+	if _, err := poc.Load(data); !IsKind(err, DuplicateItem) {
+		t.Fatalf("expected DuplicateItem, got %v", err)
+	}
+}
+
+func TestNilContainers(t *testing.T) {
+	type doc struct {
+		Tags  []string       `poculum:"tags"`
+		Attrs map[string]int `poculum:"attrs"`
+		Empty []string       `poculum:"empty"`
+	}
+	in := doc{Empty: []string{}}
+
+	// 默认 nil 编码为空容器
+	var out doc
+	if err := Unmarshal(MustDump(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Tags == nil || out.Attrs == nil {
+		t.Fatalf("default policy: got %#v", out)
+	}
+
+	poc := NewPoculum(WithNilEncoding(NilAsNil))
+	data, err := poc.Dump(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = doc{}
+	if err := poc.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Tags != nil || out.Attrs != nil || out.Empty == nil {
+		t.Fatalf("NilAsNil: got %#v", out)
+	}
+
+	out = doc{}
+	if err := NewPoculum(WithEmptyOnNil()).Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Tags == nil || out.Attrs == nil {
+		t.Fatalf("WithEmptyOnNil: got %#v", out)
+	}
+}
+
+func TestNilIntoStruct(t *testing.T) {
+	type inner struct {
+		N int `poculum:"n"`
+	}
+	type doc struct {
+		Tags  []string       `poculum:"tags"`
+		Attrs map[string]int `poculum:"attrs"`
+		Inner *inner         `poculum:"inner"`
+		Count int            `poculum:"count"`
+	}
+
+	out := doc{Tags: []string{"old"}, Attrs: map[string]int{"old": 1}, Inner: &inner{}, Count: 3}
+	if err := Unmarshal(MustDump(map[string]any{"tags": nil, "attrs": nil, "inner": nil, "count": nil}), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Tags != nil || out.Attrs != nil || out.Inner != nil || out.Count != 0 {
+		t.Fatalf("nil: got %#v", out)
+	}
+
+	if err := Unmarshal(MustDump(map[string]any{"tags": []any{}, "attrs": map[string]any{}}), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Tags == nil || len(out.Tags) != 0 || out.Attrs == nil || len(out.Attrs) != 0 {
+		t.Fatalf("empty: got %#v", out)
+	}
+
+	strict := NewPoculum(WithStrictNil())
+	if err := strict.Unmarshal(MustDump(map[string]any{"tags": nil, "inner": nil}), &out); err != nil {
+		t.Fatalf("nullable fields: %v", err)
+	}
+	err := strict.Unmarshal(MustDump(map[string]any{"count": nil}), &out)
+	if !IsKind(err, TypeMismatch) || !strings.Contains(err.Error(), "count") {
+		t.Fatalf("expected TypeMismatch for count, got %v", err)
+	}
+	var ids []int
+	if err := strict.Unmarshal(MustDump([]any{1, nil}), &ids); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch for list item, got %v", err)
+	}
+}
+
+func TestSetMapFromList(t *testing.T) {
+	type user struct {
+		Roles map[string]struct{} `poculum:"roles"`
+		IDs   map[uint16]struct{} `poculum:"ids"`
+	}
+	var u user
+	data := MustDump(map[string]any{"roles": []any{"admin", "dev", "admin"}, "ids": []any{7, 9}})
+	if err := Unmarshal(data, &u); err != nil {
+		t.Fatal(err)
+	}
+	want := user{Roles: map[string]struct{}{"admin": {}, "dev": {}}, IDs: map[uint16]struct{}{7: {}, 9: {}}}
+	if !reflect.DeepEqual(u, want) {
+		t.Fatalf("got %#v", u)
+	}
+
+	// map[T]struct{} 编码为 Set，可以解码回来
+	var back user
+	if err := Unmarshal(MustDump(u), &back); err != nil || !reflect.DeepEqual(back, want) {
+		t.Fatalf("round trip: got %#v, %v", back, err)
+	}
+
+	if err := Unmarshal(MustDump(map[string]any{"ids": []any{70000}}), &u); !IsKind(err, ValueOutOfRange) {
+		t.Fatalf("expected overflow error, got %v", err)
+	}
+}
+
+type testRetryConfig struct {
+	Attempts int           `poculum:"attempts,default=3"`
+	Backoff  time.Duration `poculum:"backoff"`
+	Codes    []int         `poculum:"codes"`
+	Name     string        `poculum:"name"`
+}
+
+func (c *testRetryConfig) Defaults() {
+	c.Attempts = 10 // default 标签优先
+	c.Backoff = time.Second
+	c.Codes = []int{502, 503}
+}
+
+func TestDefaulter(t *testing.T) {
+	var cfg testRetryConfig
+	if err := Unmarshal(MustDump(map[string]any{"name": "api"}), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := testRetryConfig{Attempts: 3, Backoff: time.Second, Codes: []int{502, 503}, Name: "api"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+
+	// 数据中存在的字段不使用默认值，即使是零值
+	cfg = testRetryConfig{}
+	if err := Unmarshal(MustDump(map[string]any{"backoff": 0, "codes": []any{}}), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Backoff != 0 || len(cfg.Codes) != 0 || cfg.Attempts != 3 {
+		t.Fatalf("got %+v", cfg)
+	}
+
+	// 每次解码得到新的默认切片
+	var a, b testRetryConfig
+	Unmarshal(MustDump(map[string]any{}), &a)
+	Unmarshal(MustDump(map[string]any{}), &b)
+	a.Codes[0] = 0
+	if b.Codes[0] != 502 {
+		t.Fatal("default slices are shared")
+	}
+}
+
+func TestIntBools(t *testing.T) {
+	type flags struct {
+		Active  bool  `poculum:"active"`
+		Deleted bool  `poculum:"deleted"`
+		Legacy  *bool `poculum:"legacy"`
+	}
+	data := MustDump(map[string]any{"active": uint8(1), "deleted": uint8(0), "legacy": 1})
+
+	var f flags
+	if err := Unmarshal(data, &f); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch without the option, got %v", err)
+	}
+	poc := NewPoculum(WithIntBools())
+	f = flags{}
+	if err := poc.Unmarshal(data, &f); err != nil || !f.Active || f.Deleted || f.Legacy == nil || !*f.Legacy {
+		t.Fatalf("got %+v, %v", f, err)
+	}
+	for _, n := range []any{uint8(2), int8(-1)} {
+		if err := poc.Unmarshal(MustDump(map[string]any{"active": n}), &f); !IsKind(err, ValueOutOfRange) {
+			t.Fatalf("%v: expected ValueOutOfRange, got %v", n, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// encodeSyncMap 使用 Range 得到 *sync.Map 的快照并编码为 map
+//
+// 键都是字符串时编码为普通的 map，都是整数时编码为整数键的 map，其它情况返回错误。
+// 不同类型的键转换后相同（例如 1 和 uint(1)）时返回错误，而不是丢弃其中一个值。
+// 快照期间其它 goroutine 的修改是否可见取决于 Range 的语义
+func (poc *Poculum) encodeSyncMap(m *sync.Map, buf *bytes.Buffer, depth int) error {
+	if m == nil {
+		return buf.WriteByte(typeNil)
+	}
+
+	byName := make(map[string]any)
+	ints := make(map[int64]any)
+	uints := make(map[uint64]any)
+	var err error
+	m.Range(func(key, value any) bool {
+		kv := reflect.ValueOf(key)
+		var dup bool
+		switch {
+		case kv.Kind() == reflect.String:
+			_, dup = byName[kv.String()]
+			byName[kv.String()] = value
+		case kv.CanInt():
+			_, dup = ints[kv.Int()]
+			ints[kv.Int()] = value
+		case kv.CanUint():
+			_, dup = uints[kv.Uint()]
+			uints[kv.Uint()] = value
+		default:
+			err = newError(UnsupportedType, fmt.Sprintf("sync.Map key must be a string or an integer, got %T", key))
+			return false
+		}
+		if dup {
+			err = syncMapDuplicate(key)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(ints) == 0 && len(uints) == 0:
+		return poc.encodeMap(byName, buf, depth)
+	case len(byName) > 0:
+		return newError(UnsupportedType, "sync.Map mixes string and integer keys")
+	case len(uints) == 0:
+		return poc.encodeIntMap(reflect.ValueOf(ints), buf, depth)
+	}
+	for k, v := range ints {
+		if k < 0 {
+			return newError(UnsupportedType, "sync.Map mixes negative and unsigned integer keys")
+		}
+		if _, ok := uints[uint64(k)]; ok {
+			return syncMapDuplicate(k)
+		}
+		uints[uint64(k)] = v
+	}
+	return poc.encodeIntMap(reflect.ValueOf(uints), buf, depth)
+}
+
+// syncMapDuplicate 不同类型的键编码后相同
+func syncMapDuplicate(key any) error {
+	return newError(UnsupportedType, fmt.Sprintf("sync.Map has several keys equal to %v", key))
+}
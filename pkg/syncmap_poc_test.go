@@ -0,0 +1,55 @@
+package poculum
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSyncMap(t *testing.T) {
+	var cache sync.Map
+	cache.Store("a", uint8(1))
+	cache.Store("b", "two")
+	data, err := DumpPoculum(&cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != uint8(1) || got["b"] != "two" {
+		t.Fatalf("got %v", got)
+	}
+
+	var ids sync.Map
+	ids.Store(3, "x")
+	if data, err = DumpPoculum(&ids); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := LoadPoculum(data); value.(map[int64]any)[3] != "x" {
+		t.Fatalf("got %#v", value)
+	}
+
+	ids.Store("mixed", true)
+	if _, err := DumpPoculum(&ids); err == nil {
+		t.Fatal("expected error for mixed key types")
+	}
+
+	// 不同类型但数值相同的键不能静默覆盖
+	var collide sync.Map
+	collide.Store(1, "int")
+	collide.Store(uint(1), "uint")
+	if _, err := DumpPoculum(&collide); !IsKind(err, UnsupportedType) {
+		t.Fatalf("expected UnsupportedType for colliding keys, got %v", err)
+	}
+	collide.Delete(uint(1))
+	collide.Store(int8(1), "int8")
+	if _, err := DumpPoculum(&collide); !IsKind(err, UnsupportedType) {
+		t.Fatalf("expected UnsupportedType for colliding keys, got %v", err)
+	}
+	collide.Delete(int8(1))
+	collide.Store(uint(2), "uint")
+	if _, err := DumpPoculum(&collide); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,246 @@
+package poculum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"unsafe"
+)
+
+// 张量扩展类型
+/*
+	张量编码为带有张量扩展标识的 list：[元素类型, 形状, 数据]
+元素类型为 uint8，形状为无符号整数的 list，数据为行优先存放的字节数据。
+与 poculum 其它部分不同，张量数据使用小端序，与常见的机器学习框架一致，
+在小端序的机器上可以不复制直接把数据视为 []float32 或者 []float64
+*/
+
+// DType 张量的元素类型
+type DType uint8
+
+const (
+	DTypeFloat32 DType = 1
+	DTypeFloat64 DType = 2
+	DTypeInt8    DType = 3
+	DTypeInt16   DType = 4
+	DTypeInt32   DType = 5
+	DTypeInt64   DType = 6
+	DTypeUInt8   DType = 7
+	DTypeUInt16  DType = 8
+	DTypeUInt32  DType = 9
+	DTypeUInt64  DType = 10
+)
+
+// Size 返回元素类型占用的字节数，未知类型返回 0
+func (d DType) Size() int {
+	switch d {
+	case DTypeInt8, DTypeUInt8:
+		return 1
+	case DTypeInt16, DTypeUInt16:
+		return 2
+	case DTypeFloat32, DTypeInt32, DTypeUInt32:
+		return 4
+	case DTypeFloat64, DTypeInt64, DTypeUInt64:
+		return 8
+	}
+	return 0
+}
+
+// Tensor N 维张量，编码为张量扩展类型，解码得到 *Tensor
+type Tensor struct {
+	DType DType
+	Shape []int
+	Data  []byte // 行优先、小端序的数据
+}
+
+// hostLittleEndian 当前机器是否是小端序
+var hostLittleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// NewTensorFloat32 使用 float32 数据创建张量，数据会被复制
+func NewTensorFloat32(shape []int, data []float32) (*Tensor, error) {
+	t := &Tensor{DType: DTypeFloat32, Shape: append([]int(nil), shape...), Data: make([]byte, len(data)*4)}
+	for i, f := range data {
+		binary.LittleEndian.PutUint32(t.Data[i*4:], math.Float32bits(f))
+	}
+	return t, t.Validate()
+}
+
+// NewTensorFloat64 使用 float64 数据创建张量，数据会被复制
+func NewTensorFloat64(shape []int, data []float64) (*Tensor, error) {
+	t := &Tensor{DType: DTypeFloat64, Shape: append([]int(nil), shape...), Data: make([]byte, len(data)*8)}
+	for i, f := range data {
+		binary.LittleEndian.PutUint64(t.Data[i*8:], math.Float64bits(f))
+	}
+	return t, t.Validate()
+}
+
+// Len 返回元素个数，即形状中各维度的乘积
+func (t *Tensor) Len() int {
+	n := 1
+	for _, dim := range t.Shape {
+		n *= dim
+	}
+	return n
+}
+
+// Validate 检查元素类型、形状与数据长度是否一致
+func (t *Tensor) Validate() error {
+	size := t.DType.Size()
+	if size == 0 {
+		return newError(InvalidTensor, fmt.Sprintf("Unknown tensor dtype: %d", t.DType))
+	}
+	n := 1
+	for _, dim := range t.Shape {
+		if dim < 0 || (dim > 0 && n > math.MaxInt/dim) {
+			return newError(InvalidTensor, fmt.Sprintf("Invalid tensor shape: %v", t.Shape))
+		}
+		n *= dim
+	}
+	if n > math.MaxInt/size || n*size != len(t.Data) {
+		return newError(InvalidTensor, fmt.Sprintf("Tensor data is %d bytes, shape %v of dtype %d needs %d", len(t.Data), t.Shape, t.DType, n*size))
+	}
+	return nil
+}
+
+// aligned 判断数据是否可以直接视为元素大小为 size 的切片
+func (t *Tensor) aligned(size int) bool {
+	return hostLittleEndian && len(t.Data) > 0 && uintptr(unsafe.Pointer(&t.Data[0]))%uintptr(size) == 0
+}
+
+// Float32s 把数据视为 []float32，在小端序的机器上并且数据对齐时不复制，返回的切片与 Data 共享内存
+func (t *Tensor) Float32s() ([]float32, error) {
+	if t.DType != DTypeFloat32 {
+		return nil, newError(TypeMismatch, fmt.Sprintf("Tensor dtype is %d, not float32", t.DType))
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	n := len(t.Data) / 4
+	if t.aligned(4) {
+		return unsafe.Slice((*float32)(unsafe.Pointer(&t.Data[0])), n), nil
+	}
+	out := make([]float32, n)
+	for i := range out {
+		out[i] = math.Float32frombits(binary.LittleEndian.Uint32(t.Data[i*4:]))
+	}
+	return out, nil
+}
+
+// Float64s 把数据视为 []float64，在小端序的机器上并且数据对齐时不复制，返回的切片与 Data 共享内存
+func (t *Tensor) Float64s() ([]float64, error) {
+	if t.DType != DTypeFloat64 {
+		return nil, newError(TypeMismatch, fmt.Sprintf("Tensor dtype is %d, not float64", t.DType))
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	n := len(t.Data) / 8
+	if t.aligned(8) {
+		return unsafe.Slice((*float64)(unsafe.Pointer(&t.Data[0])), n), nil
+	}
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.Float64frombits(binary.LittleEndian.Uint64(t.Data[i*8:]))
+	}
+	return out, nil
+}
+
+// encodeTensor 编码张量
+func (poc *Poculum) encodeTensor(t *Tensor, buf *bytes.Buffer, depth int) error {
+	if poc.noPacked {
+		return newError(UnsupportedType, "Peer does not support packed arrays, cannot encode Tensor")
+	}
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extTensor)
+	writeListHeader(buf, 3)
+	buf.WriteByte(typeUInt8)
+	buf.WriteByte(byte(t.DType))
+	// 形状直接写入，不经过钩子、稀疏编码等针对 list 的处理
+	writeListHeader(buf, len(t.Shape))
+	for _, dim := range t.Shape {
+		writeCompactUint(buf, uint64(dim))
+	}
+	return poc.encodeBytes(t.Data, buf)
+}
+
+// readTensorUint 直接读取张量头部的无符号整数，不经过解码钩子与数值 list 的转换
+func readTensorUint(reader *bytes.Reader, what string) (uint64, error) {
+	h, err := readHeader(reader)
+	if err != nil {
+		return 0, err
+	}
+	if h.typeByte < typeUInt8 || h.typeByte > typeUInt64 {
+		return 0, newError(InvalidTensor, fmt.Sprintf("Tensor %s must be an unsigned integer, got %s", what, typeName(h.typeByte)))
+	}
+	var b [8]byte
+	if _, err := io.ReadFull(reader, b[:h.length]); err != nil {
+		return 0, newError(InsufficientData, fmt.Sprintf("tensor %s", what))
+	}
+	return readUintBytes(b[:h.length]), nil
+}
+
+// decodeTensor 解码张量
+func (poc *Poculum) decodeTensor(reader *bytes.Reader, depth int) (*Tensor, error) {
+	items, err := expectList(reader, "Tensor")
+	if err != nil {
+		return nil, err
+	}
+	if items != 3 {
+		return nil, newError(InvalidTensor, fmt.Sprintf("Tensor must contain 3 items, got %d", items))
+	}
+
+	dtype, err := readTensorUint(reader, "dtype")
+	if err != nil {
+		return nil, err
+	}
+	if dtype > math.MaxUint8 {
+		return nil, newError(InvalidTensor, fmt.Sprintf("Invalid tensor dtype: %d", dtype))
+	}
+
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isList() {
+		return nil, newError(InvalidTensor, fmt.Sprintf("Tensor shape must be a list, got %s", typeName(h.typeByte)))
+	}
+	if err := poc.checkItems(reader, h.length, "Tensor shape"); err != nil {
+		return nil, err
+	}
+	shape := make([]int, h.length)
+	for i := range shape {
+		n, err := readTensorUint(reader, "dimension")
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt32 {
+			return nil, newError(InvalidTensor, fmt.Sprintf("Invalid tensor dimension: %d", n))
+		}
+		shape[i] = int(n)
+	}
+
+	if h, err = readHeader(reader); err != nil {
+		return nil, err
+	}
+	if h.typeByte != typeBytes8 && h.typeByte != typeBytes16 && h.typeByte != typeBytes32 {
+		return nil, newError(InvalidTensor, fmt.Sprintf("Tensor data must be bytes, got %s", typeName(h.typeByte)))
+	}
+	data, err := poc.decodeBytes(reader, h.length)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tensor{DType: DType(dtype), Shape: shape, Data: data}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,68 @@
+package poculum
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTensor(t *testing.T) {
+	in, err := NewTensorFloat32([]int{2, 3}, []float32{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := DumpPoculum(map[string]any{"embedding": in})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Embedding Tensor `poculum:"embedding"`
+	}
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	values, err := out.Embedding.Float32s()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 6 || values[5] != 6 || out.Embedding.Shape[1] != 3 {
+		t.Fatalf("unexpected tensor: %v %v", out.Embedding.Shape, values)
+	}
+
+	if _, err := DumpPoculum(&Tensor{DType: DTypeFloat64, Shape: []int{2}, Data: make([]byte, 8)}); err == nil {
+		t.Fatal("expected error for mismatched shape")
+	}
+}
+
+func TestTensorShapeIgnoresListOptions(t *testing.T) {
+	in, err := NewTensorFloat64([]int{2, 2}, []float64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	poc := NewPoculum(WithNumericLists[float64](), WithSparseLists(0.1), WithHooks(Hooks{
+		BeforeEncode: func(v any) any {
+			if list, ok := v.([]any); ok {
+				return append(list, "hooked")
+			}
+			return v
+		},
+		AfterDecode: func(v any) any {
+			if _, ok := v.([]any); ok {
+				return "hooked"
+			}
+			return v
+		},
+	}))
+	data, err := poc.Dump(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := poc.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, ok := value.(*Tensor)
+	if !ok || !reflect.DeepEqual(out.Shape, []int{2, 2}) {
+		t.Fatalf("got %#v", value)
+	}
+}
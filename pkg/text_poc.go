@@ -0,0 +1,100 @@
+package poculum
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// 文本形式
+/*
+	需要把编码数据放进 JSON、YAML、环境变量或者 URL 时使用文本形式：
+
+	s, err := poculum.DumpBase64URL(cursor)    // 用于 URL 参数，没有填充
+	v, err := poculum.LoadBase64(os.Getenv("POCULUM_CONFIG"))
+
+LoadHex 接受 FromHex 支持的所有写法，LoadBase64 接受标准与 URL 安全的字母表，填充可有可无
+*/
+
+// DumpHex 使用默认实例编码为十六进制文本
+func DumpHex(value any) (string, error) {
+	return Default().DumpHex(value)
+}
+
+// DumpHex 编码为小写的十六进制文本
+func (poc *Poculum) DumpHex(value any) (string, error) {
+	data, err := poc.dump(value)
+	if err != nil {
+		return "", err
+	}
+	return ToHex(data), nil
+}
+
+// LoadHex 使用默认实例解码十六进制文本
+func LoadHex(s string) (any, error) {
+	return Default().LoadHex(s)
+}
+
+// LoadHex 解码十六进制文本
+func (poc *Poculum) LoadHex(s string) (any, error) {
+	data, err := FromHex(s)
+	if err != nil {
+		return nil, err
+	}
+	return poc.load(data)
+}
+
+// DumpBase64 使用默认实例编码为标准的 Base64 文本
+func DumpBase64(value any) (string, error) {
+	return Default().DumpBase64(value)
+}
+
+// DumpBase64 编码为标准的 Base64 文本（带填充）
+func (poc *Poculum) DumpBase64(value any) (string, error) {
+	data, err := poc.dump(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DumpBase64URL 使用默认实例编码为 URL 安全的 Base64 文本
+func DumpBase64URL(value any) (string, error) {
+	return Default().DumpBase64URL(value)
+}
+
+// DumpBase64URL 编码为 URL 安全、没有填充的 Base64 文本，可以直接用于 URL 与文件名
+func (poc *Poculum) DumpBase64URL(value any) (string, error) {
+	data, err := poc.dump(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// LoadBase64 使用默认实例解码 Base64 文本
+func LoadBase64(s string) (any, error) {
+	return Default().LoadBase64(s)
+}
+
+// LoadBase64 解码 Base64 文本，接受标准与 URL 安全的字母表，填充可有可无，忽略首尾的空白
+func (poc *Poculum) LoadBase64(s string) (any, error) {
+	data, err := fromBase64(s)
+	if err != nil {
+		return nil, err
+	}
+	return poc.load(data)
+}
+
+// fromBase64 解析任意变体的 Base64 文本
+func fromBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	enc := base64.RawStdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.RawURLEncoding
+	}
+	data, err := enc.DecodeString(s)
+	if err != nil {
+		return nil, wrapError(InvalidText, "Invalid base64", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,41 @@
+package poculum
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextForms(t *testing.T) {
+	value := map[string]any{"id": uint8(1), "blob": bytes.Repeat([]byte{0xFB, 0xFF}, 4)}
+
+	h, err := DumpHex(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadHex(h); err != nil || !reflect.DeepEqual(got, value) {
+		t.Fatalf("LoadHex = %v, %v", got, err)
+	}
+
+	std, err := DumpBase64(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := DumpBase64URL(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(raw, "+/=") || !strings.ContainsAny(std, "+/") {
+		t.Fatalf("unexpected alphabets: %s %s", std, raw)
+	}
+	for _, s := range []string{std, raw, " " + strings.TrimRight(std, "=") + "\n"} {
+		if got, err := LoadBase64(s); err != nil || !reflect.DeepEqual(got, value) {
+			t.Fatalf("LoadBase64(%q) = %v, %v", s, got, err)
+		}
+	}
+
+	if _, err := LoadBase64("not base64!"); !IsKind(err, InvalidText) {
+		t.Fatalf("expected InvalidText, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 时间戳扩展类型
+/*
+	time.Time 编码为带有时间戳扩展标识的 list：[Unix 秒数, 纳秒]，
+保留时区偏移时为 [Unix 秒数, 纳秒, 相对 UTC 偏移的秒数]。
+时区的处理方式由 WithTimeZone 设置，编码与解码都使用同一个策略：
+
+	TimeUTC            编码时转换为 UTC，解码得到 UTC 时间，数据中的偏移被忽略（默认）
+	TimePreserveOffset 编码时写入偏移，解码时使用 time.FixedZone 还原偏移（时区名称不保留）
+	TimeRequireUTC     编码非 UTC 的时间或者解码带有非零偏移的数据时返回 NonUTCTime 错误
+
+编码时总是去掉单调时钟读数，使用 WithTimePrecision 可以把时间四舍五入到指定精度，
+这样相同的时刻总是得到相同的字节，便于计算哈希与比较
+*/
+
+// TimePolicy 时间戳的时区处理策略
+type TimePolicy uint8
+
+const (
+	TimeUTC            TimePolicy = iota // 统一转换为 UTC
+	TimePreserveOffset                   // 保留相对 UTC 的偏移
+	TimeRequireUTC                       // 只接受 UTC 时间
+)
+
+// WithTimeZone 设置时间戳的时区处理策略
+func WithTimeZone(policy TimePolicy) ConfigOption {
+	return func(poc *Poculum) {
+		poc.timePolicy = policy
+	}
+}
+
+// WithTimePrecision 编码时把时间四舍五入到 precision 的整数倍，例如 time.Millisecond，
+// precision 不大于 0 时保留纳秒精度
+func WithTimePrecision(precision time.Duration) ConfigOption {
+	return func(poc *Poculum) {
+		poc.timePrecision = precision
+	}
+}
+
+// encodeTime 编码时间戳
+func (poc *Poculum) encodeTime(t time.Time, buf *bytes.Buffer, depth int) error {
+	// Round(0) 去掉单调时钟读数
+	t = t.Round(0)
+	if poc.timePrecision > 0 {
+		t = t.Round(poc.timePrecision)
+	}
+
+	_, offset := t.Zone()
+	if poc.timePolicy == TimeRequireUTC && offset != 0 {
+		return newError(NonUTCTime, fmt.Sprintf("Time is not UTC: %s", t))
+	}
+
+	// 秒数按照 int64 处理，32 位平台上也不会截断，宽度的选择与 Go 的 int 相同
+	var sec any = t.Unix()
+	switch secs := t.Unix(); {
+	case poc.fixedInts:
+	case secs >= 0 && secs <= math.MaxUint32:
+		sec = uint32(secs)
+	case secs < 0 && secs >= math.MinInt32:
+		sec = int32(secs)
+	}
+	items := []any{sec, compactUint(t.Nanosecond())}
+	if poc.timePolicy == TimePreserveOffset && offset != 0 {
+		items = append(items, offset)
+	}
+
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extTime)
+	writeListHeader(buf, len(items))
+	for _, item := range items {
+		if err := poc.encodeValue(item, buf, depth+2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeTime 解码时间戳
+func (poc *Poculum) decodeTime(reader *bytes.Reader, depth int) (time.Time, error) {
+	items, err := expectList(reader, "Time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	if items != 2 && items != 3 {
+		return time.Time{}, newError(InvalidTime, fmt.Sprintf("Time must contain 2 or 3 items, got %d", items))
+	}
+
+	values := make([]int64, items)
+	for i := range values {
+		value, err := poc.decodeValue(reader, depth+1)
+		if err != nil {
+			return time.Time{}, err
+		}
+		n, ok := toInt64(value)
+		if !ok {
+			return time.Time{}, newError(InvalidTime, fmt.Sprintf("Time item %d must be an integer, got %T", i, value))
+		}
+		values[i] = n
+	}
+	if values[1] < 0 || values[1] >= int64(time.Second) {
+		return time.Time{}, newError(InvalidTime, fmt.Sprintf("Time nanoseconds out of range: %d", values[1]))
+	}
+
+	t := time.Unix(values[0], values[1]).UTC()
+	if items == 2 || values[2] == 0 {
+		return t, nil
+	}
+
+	offset := values[2]
+	if offset < math.MinInt32 || offset > math.MaxInt32 {
+		return time.Time{}, newError(InvalidTime, fmt.Sprintf("Time offset out of range: %d", offset))
+	}
+	switch poc.timePolicy {
+	case TimePreserveOffset:
+		return t.In(time.FixedZone("", int(offset))), nil
+	case TimeRequireUTC:
+		return time.Time{}, newError(NonUTCTime, fmt.Sprintf("Time has offset %ds", offset))
+	}
+	return t, nil
+}
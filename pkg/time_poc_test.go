@@ -0,0 +1,100 @@
+package poculum
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeZonePolicy(t *testing.T) {
+	local := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.FixedZone("CST", 8*3600))
+
+	utc := NewPoculum()
+	data, err := utc.Dump(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got time.Time
+	if err := utc.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(local) || got.Location() != time.UTC {
+		t.Fatalf("TimeUTC: got %v", got)
+	}
+
+	preserve := NewPoculum(WithTimeZone(TimePreserveOffset))
+	data, err = preserve.Dump(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := preserve.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, offset := got.Zone(); !got.Equal(local) || offset != 8*3600 {
+		t.Fatalf("TimePreserveOffset: got %v", got)
+	}
+
+	strict := NewPoculum(WithTimeZone(TimeRequireUTC))
+	if _, err := strict.Dump(local); err == nil {
+		t.Fatal("TimeRequireUTC: expected error encoding non-UTC time")
+	}
+	if _, err := strict.Load(data); err == nil {
+		t.Fatal("TimeRequireUTC: expected error decoding offset")
+	}
+	if _, err := strict.Dump(local.UTC()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTimePrecision(t *testing.T) {
+	// 单调时钟读数不会被编码，解码结果等于去掉单调时钟读数的原值
+	now := time.Now()
+	data, err := DumpPoculum(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded time.Time
+	if err := Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(now.Round(0)) {
+		t.Fatalf("got %v, want %v", decoded, now.Round(0))
+	}
+
+	poc := NewPoculum(WithTimePrecision(time.Millisecond))
+	data, err = poc.Dump(time.Unix(100, 123456789))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got time.Time
+	if err := poc.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Nanosecond() != 123000000 {
+		t.Fatalf("got %d ns, want 123000000", got.Nanosecond())
+	}
+}
+
+func TestTimeWideSeconds(t *testing.T) {
+	for _, want := range []time.Time{
+		time.Date(1800, 1, 1, 0, 0, 0, 5, time.UTC),
+		time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2200, 1, 1, 0, 0, 0, 5, time.UTC),
+	} {
+		data, err := DumpPoculum(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got time.Time
+		if err := Unmarshal(data, &got); err != nil || !got.Equal(want) {
+			t.Fatalf("%v: got %v, %v", want, got, err)
+		}
+	}
+
+	// 秒数的宽度与 Go 的 int 相同
+	if data := MustDump(time.Unix(1<<40, 0)); data[3] != typeInt64 {
+		t.Fatalf("seconds beyond uint32 encoded as 0x%02x", data[3])
+	}
+	if data := MustDump(time.Unix(1700000000, 0)); data[3] != typeUInt32 {
+		t.Fatalf("seconds within uint32 encoded as 0x%02x", data[3])
+	}
+}
@@ -0,0 +1,235 @@
+package poculum
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 追踪信息
+/*
+	InjectTrace 与 ExtractTrace 按照 W3C Trace Context 与 W3C Baggage 的格式读写信封的消息头，
+异步的消息处理也可以加入分布式追踪：
+
+	traceparent  00-<32 位十六进制的 trace ID>-<16 位十六进制的 span ID>-<2 位十六进制的标志>
+	tracestate   厂商相关的状态，原样传递
+	baggage      k1=v1,k2=v2，值使用百分号编码
+
+消费者通常使用 Child 创建属于同一个 trace 的新 span，再注入到发出的消息中。
+不使用追踪系统时，可以只用 correlation-id 消息头把请求与响应、重试的消息关联起来
+*/
+
+// 追踪信息使用的消息头
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+	HeaderBaggage     = "baggage"
+
+	HeaderCorrelationID = "correlation-id"
+)
+
+// TraceFlagSampled traceparent 中表示已采样的标志
+const TraceFlagSampled = 0x01
+
+// TraceContext 分布式追踪的上下文
+type TraceContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+	State   string            // tracestate 的原始内容
+	Baggage map[string]string // 随请求传递的键值对
+}
+
+// IsValid 判断 trace ID 与 span ID 是否都不为全 0
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID != [16]byte{} && tc.SpanID != [8]byte{}
+}
+
+// Sampled 判断是否设置了采样标志
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&TraceFlagSampled != 0
+}
+
+// Traceparent 返回 traceparent 消息头的值
+func (tc TraceContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(tc.TraceID[:]), hex.EncodeToString(tc.SpanID[:]), tc.Flags)
+}
+
+// Child 返回属于同一个 trace 的新 span，span ID 随机生成，其余字段不变
+func (tc TraceContext) Child() TraceContext {
+	child := tc
+	for child.SpanID == [8]byte{} || child.SpanID == tc.SpanID {
+		rand.Read(child.SpanID[:])
+	}
+	return child
+}
+
+// NewTraceContext 创建随机 trace ID 与 span ID 的上下文，用于开始新的 trace
+func NewTraceContext(sampled bool) TraceContext {
+	var tc TraceContext
+	for tc.TraceID == [16]byte{} {
+		rand.Read(tc.TraceID[:])
+	}
+	if sampled {
+		tc.Flags = TraceFlagSampled
+	}
+	return tc.Child()
+}
+
+// ParseTraceparent 解析 traceparent 消息头。高于 00 的版本只解析前四个字段，版本 ff 不合法
+func ParseTraceparent(s string) (TraceContext, error) {
+	var tc TraceContext
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return tc, fmt.Errorf("invalid traceparent %q", s)
+	}
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || len(version) != 1 || version[0] == 0xFF || (version[0] == 0 && len(parts) != 4) {
+		return tc, fmt.Errorf("invalid traceparent version in %q", s)
+	}
+	if !isLowerHex(parts[1], 32) || !isLowerHex(parts[2], 16) || !isLowerHex(parts[3], 2) {
+		return tc, fmt.Errorf("invalid traceparent %q", s)
+	}
+	hex.Decode(tc.TraceID[:], []byte(parts[1]))
+	hex.Decode(tc.SpanID[:], []byte(parts[2]))
+	var flags [1]byte
+	hex.Decode(flags[:], []byte(parts[3]))
+	tc.Flags = flags[0]
+	if !tc.IsValid() {
+		return tc, fmt.Errorf("traceparent %q has an all-zero ID", s)
+	}
+	return tc, nil
+}
+
+// isLowerHex 判断 s 是否是长度为 n 的小写十六进制字符串
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// CorrelationID 返回关联 ID，没有时返回空字符串
+func (e *Envelope) CorrelationID() string {
+	return e.Headers[HeaderCorrelationID]
+}
+
+// SetCorrelationID 设置关联 ID，id 为空时删除
+func (e *Envelope) SetCorrelationID(id string) {
+	if id == "" {
+		delete(e.Headers, HeaderCorrelationID)
+		return
+	}
+	if e.Headers == nil {
+		e.Headers = map[string]string{}
+	}
+	e.Headers[HeaderCorrelationID] = id
+}
+
+// InjectTrace 把追踪信息写入消息头，tc 不合法时删除已有的追踪信息
+func (e *Envelope) InjectTrace(tc TraceContext) {
+	if e.Headers == nil {
+		e.Headers = map[string]string{}
+	}
+	delete(e.Headers, HeaderTraceparent)
+	delete(e.Headers, HeaderTracestate)
+	delete(e.Headers, HeaderBaggage)
+	if !tc.IsValid() {
+		return
+	}
+
+	e.Headers[HeaderTraceparent] = tc.Traceparent()
+	if tc.State != "" {
+		e.Headers[HeaderTracestate] = tc.State
+	}
+	if len(tc.Baggage) > 0 {
+		keys := make([]string, 0, len(tc.Baggage))
+		for k := range tc.Baggage {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		members := make([]string, len(keys))
+		for i, k := range keys {
+			members[i] = k + "=" + escapeBaggage(tc.Baggage[k])
+		}
+		e.Headers[HeaderBaggage] = strings.Join(members, ",")
+	}
+}
+
+// ExtractTrace 从消息头中读取追踪信息，没有 traceparent 时返回 false，traceparent 不合法时返回错误。
+// baggage 中无法解析的条目以及条目的属性被忽略
+func (e *Envelope) ExtractTrace() (TraceContext, bool, error) {
+	parent, ok := e.Headers[HeaderTraceparent]
+	if !ok {
+		return TraceContext{}, false, nil
+	}
+	tc, err := ParseTraceparent(parent)
+	if err != nil {
+		return TraceContext{}, false, err
+	}
+	tc.State = e.Headers[HeaderTracestate]
+
+	for _, member := range strings.Split(e.Headers[HeaderBaggage], ",") {
+		member, _, _ = strings.Cut(member, ";")
+		key, value, ok := strings.Cut(member, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		value, err := unescapeBaggage(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		if tc.Baggage == nil {
+			tc.Baggage = map[string]string{}
+		}
+		tc.Baggage[key] = value
+	}
+	return tc, true, nil
+}
+
+// escapeBaggage 对 baggage 的值进行百分号编码，只保留 W3C Baggage 允许的可见字符
+func escapeBaggage(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c > 0x20 && c < 0x7F && !strings.ContainsRune(`",;\%`, rune(c)) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// unescapeBaggage 解码百分号编码
+func unescapeBaggage(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+3 > len(s) {
+			return "", fmt.Errorf("invalid escape in %q", s)
+		}
+		c, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in %q", s)
+		}
+		b.WriteByte(c[0])
+		i += 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,49 @@
+package poculum
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvelopeTrace(t *testing.T) {
+	env := &Envelope{}
+	if _, ok, err := env.ExtractTrace(); ok || err != nil {
+		t.Fatalf("empty envelope: %v, %v", ok, err)
+	}
+
+	tc := NewTraceContext(true)
+	tc.State = "vendor=abc"
+	tc.Baggage = map[string]string{"user": "ann lee", "tenant": "a,b"}
+	env.InjectTrace(tc)
+	if !strings.HasPrefix(env.Headers[HeaderTraceparent], "00-") || env.Headers[HeaderBaggage] != "tenant=a%2Cb,user=ann%20lee" {
+		t.Fatalf("headers = %v", env.Headers)
+	}
+
+	got, ok, err := env.ExtractTrace()
+	if !ok || err != nil || !reflect.DeepEqual(got, tc) || !got.Sampled() {
+		t.Fatalf("got %+v, %v, %v", got, ok, err)
+	}
+	child := got.Child()
+	if child.TraceID != tc.TraceID || child.SpanID == tc.SpanID {
+		t.Fatal("child must keep trace ID and use a new span ID")
+	}
+
+	parsed, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil || parsed.Traceparent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("got %v, %v", parsed, err)
+	}
+	for _, bad := range []string{
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := ParseTraceparent(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"); err != nil {
+		t.Errorf("future version should parse: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package poculum
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// 流式转换
+/*
+	Transform 从 src 中依次读取首尾相接的值，对每个值以及其中每个 list 元素、map 的值调用 fn，
+按照 fn 的结果写入 dst，不构造 Go 对象，适合清洗归档的数据：
+
+	err := poculum.Transform(dst, src, func(path string, v poculum.Raw) (poculum.Raw, bool, error) {
+		if strings.HasSuffix(path, ".password") {
+			return nil, false, nil // 删除
+		}
+		return nil, true, nil // 保留，继续处理其中的元素
+	})
+
+fn 的返回值：
+	keep 为 false   删除该值，map 中删除整个键值对，list 中删除该元素，顶层值写入 nil
+	Raw 不为 nil    使用 Raw 替换该值，不再处理其中的元素
+	Raw 为 nil      保留该值，是 list 或者 map 时继续处理其中的元素
+
+path 使用与 Get 相同的语法，顶层值为 ""，list 元素使用原数据中的下标。
+扩展类型（Set、稀疏 list 等）作为一个整体交给 fn，不处理其中的元素
+*/
+
+// TransformFunc Transform 对每个值调用的函数
+type TransformFunc func(path string, v Raw) (Raw, bool, error)
+
+// Transform 使用默认实例把 src 中的值经过 fn 转换后写入 dst
+func Transform(dst io.Writer, src io.Reader, fn TransformFunc) error {
+	return Default().Transform(dst, src, fn)
+}
+
+// Transform 把 src 中的值经过 fn 转换后写入 dst，每次只在内存中保存一个顶层值
+func (poc *Poculum) Transform(dst io.Writer, src io.Reader, fn TransformFunc) error {
+	dec := poc.NewDecoder(src)
+	var buf bytes.Buffer
+	for {
+		raw, err := dec.ReadRaw()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		buf.Reset()
+		kept, err := poc.transformValue(bytes.NewReader(raw), &buf, "", 0, fn)
+		if err != nil {
+			return err
+		}
+		if !kept {
+			buf.WriteByte(typeNil)
+		}
+		if _, err := dst.Write(buf.Bytes()); err != nil {
+			return wrapError(IOError, "write transformed value", err)
+		}
+	}
+}
+
+// transformValue 转换 reader 中的下一个值并写入 buf，值被删除时返回 false
+func (poc *Poculum) transformValue(reader *bytes.Reader, buf *bytes.Buffer, path string, depth int, fn TransformFunc) (bool, error) {
+	raw, err := poc.readRaw(reader, depth)
+	if err != nil {
+		return false, err
+	}
+	replaced, keep, err := fn(path, raw)
+	if err != nil || !keep {
+		return false, err
+	}
+	if replaced != nil {
+		if err := poc.validRaw(replaced); err != nil {
+			return false, err
+		}
+		buf.Write(replaced)
+		return true, nil
+	}
+
+	sub := bytes.NewReader(raw)
+	h, err := readHeader(sub)
+	if err != nil {
+		return false, err
+	}
+	if !h.isContainer() {
+		buf.Write(raw)
+		return true, nil
+	}
+
+	var body bytes.Buffer
+	count := 0
+	for i := 0; i < h.length; i++ {
+		mark := body.Len()
+		itemPath := joinPath(path, strconv.Itoa(i))
+		if h.isMap() {
+			key, err := poc.readKey(sub)
+			if err != nil {
+				return false, err
+			}
+			if err := poc.encodeString(key, &body); err != nil {
+				return false, err
+			}
+			itemPath = joinPath(path, key)
+		}
+
+		kept, err := poc.transformValue(sub, &body, itemPath, depth+1, fn)
+		if err != nil {
+			return false, err
+		}
+		if !kept {
+			body.Truncate(mark)
+			continue
+		}
+		count++
+	}
+
+	if h.isMap() {
+		writeMapHeader(buf, count)
+	} else {
+		writeListHeader(buf, count)
+	}
+	buf.Write(body.Bytes())
+	return true, nil
+}
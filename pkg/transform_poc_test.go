@@ -0,0 +1,55 @@
+package poculum
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	var src bytes.Buffer
+	for _, v := range []any{
+		map[string]any{"user": "a", "password": "x", "tags": []any{"keep", "drop", "keep"}},
+		map[string]any{"user": "b", "password": "y", "n": uint8(1)},
+	} {
+		src.Write(MustDump(v))
+	}
+
+	replacement := MustDump(uint8(2))
+	var dst bytes.Buffer
+	err := Transform(&dst, &src, func(path string, v Raw) (Raw, bool, error) {
+		switch {
+		case path == "password":
+			return nil, false, nil
+		case path == "n":
+			return replacement, true, nil
+		case strings.HasPrefix(path, "tags.") && string(v) == string(MustDump("drop")):
+			return nil, false, nil
+		}
+		return nil, true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dec := NewDecoder(&dst)
+	var got []any
+	for {
+		var v any
+		if err := dec.Decode(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	want := []any{
+		map[string]any{"user": "a", "tags": []any{"keep", "keep"}},
+		map[string]any{"user": "b", "n": uint8(2)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v", got)
+	}
+}
@@ -0,0 +1,189 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// 日志截断
+/*
+	TruncateForLog 把文档缩小到指定的字节数以内，结果仍然是合法的编码数据，可以解码后写入日志：
+
+	short, truncated := poculum.TruncateForLog(payload, 512)
+	logger.Info("request", "body", poculum.MustLoad(short), "truncated", truncated)
+
+被省略的内容替换为字符串标记：
+
+	过长的字符串        保留开头，后面加上 "…(+N bytes)"
+	过长的字节数据      "<N bytes>"
+	过长的 list        保留前几个元素，最后一个元素为 "…(+N items)"
+	过长的 map         保留前几项，最后一项为 "…": "+N entries"
+	过深的 list 与 map  "<list of N items>" 与 "<map of N entries>"
+	过大的扩展类型      "<ext 0xNN, N bytes>"
+
+从较宽松的限制开始逐步收紧，直到结果不超过 maxBytes。数据不合法时返回描述它的字符串标记
+*/
+
+// truncateLimits TruncateForLog 依次尝试的限制
+var truncateLimits = []struct{ str, items, depth int }{
+	{1024, 64, 16},
+	{256, 16, 8},
+	{64, 8, 4},
+	{16, 4, 3},
+	{8, 2, 2},
+	{8, 1, 1},
+	{0, 0, 0},
+}
+
+// TruncateForLog 返回不超过 maxBytes 的合法文档，第二个返回值表示是否有内容被省略。
+// data 本来就不超过 maxBytes 时原样返回；maxBytes 太小时结果可能仍然超过它，此时只包含一个标记
+func TruncateForLog(data []byte, maxBytes int) ([]byte, bool) {
+	if len(data) <= maxBytes {
+		if err := Default().validRaw(data); err == nil {
+			return data, false
+		}
+	}
+
+	var out []byte
+	for _, limits := range truncateLimits {
+		t := truncator{poc: Default(), data: data, reader: bytes.NewReader(data), maxString: limits.str, maxItems: limits.items, maxDepth: limits.depth}
+		if err := t.value(0); err != nil {
+			var buf bytes.Buffer
+			Default().encodeString(fmt.Sprintf("<invalid poculum data, %d bytes: %v>", len(data), err), &buf)
+			return buf.Bytes(), true
+		}
+		out = t.buf.Bytes()
+		if len(out) <= maxBytes {
+			break
+		}
+	}
+	return out, true
+}
+
+// truncator 按照一组限制重写文档
+type truncator struct {
+	poc       *Poculum
+	data      []byte
+	reader    *bytes.Reader
+	buf       bytes.Buffer
+	maxString int // 字符串、字节数据与扩展类型的最大字节数
+	maxItems  int // list 与 map 保留的最多元素个数
+	maxDepth  int // 展开的最大嵌套深度
+}
+
+func (t *truncator) offset() int {
+	return len(t.data) - t.reader.Len()
+}
+
+// marker 写入字符串标记
+func (t *truncator) marker(format string, args ...any) {
+	t.poc.encodeString(fmt.Sprintf(format, args...), &t.buf)
+}
+
+// value 重写下一个值
+func (t *truncator) value(depth int) error {
+	start := t.offset()
+	h, err := readHeader(t.reader)
+	if err != nil {
+		return err
+	}
+	body := t.offset()
+	kind, _ := TypeOf(t.data[start:])
+
+	switch {
+	case h.typeByte == typeExt:
+		t.reader.Seek(int64(start), io.SeekStart)
+		if err := t.poc.skipValue(t.reader, depth); err != nil {
+			return err
+		}
+		if size := t.offset() - start; size > t.maxString {
+			t.marker("<ext 0x%02x, %d bytes>", h.tag, size)
+			return nil
+		}
+		t.buf.Write(t.data[start:t.offset()])
+		return nil
+
+	case h.isContainer():
+		if depth >= t.maxDepth {
+			t.reader.Seek(int64(start), io.SeekStart)
+			if err := t.poc.skipValue(t.reader, depth); err != nil {
+				return err
+			}
+			if h.isMap() {
+				t.marker("<map of %d entries>", h.length)
+			} else {
+				t.marker("<list of %d items>", h.length)
+			}
+			return nil
+		}
+		kept, extra := min(h.length, t.maxItems), max(h.length-t.maxItems, 0)
+		items := kept
+		if extra > 0 {
+			items++
+		}
+		if h.isMap() {
+			writeMapHeader(&t.buf, items)
+		} else {
+			writeListHeader(&t.buf, items)
+		}
+		for i := 0; i < h.length; i++ {
+			if i >= kept {
+				if h.isMap() {
+					if err := t.poc.skipValue(t.reader, depth+1); err != nil {
+						return err
+					}
+				}
+				if err := t.poc.skipValue(t.reader, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			if h.isMap() {
+				keyStart := t.offset()
+				if err := t.poc.skipValue(t.reader, depth+1); err != nil {
+					return err
+				}
+				t.buf.Write(t.data[keyStart:t.offset()])
+			}
+			if err := t.value(depth + 1); err != nil {
+				return err
+			}
+		}
+		if extra > 0 && h.isMap() {
+			t.marker("…")
+			t.marker("+%d entries", extra)
+		} else if extra > 0 {
+			t.marker("…(+%d items)", extra)
+		}
+		return nil
+
+	case kind == String || kind == Bytes:
+		if h.length > t.reader.Len() {
+			return newError(InsufficientData, typeName(h.typeByte)+" data")
+		}
+		t.reader.Seek(int64(body+h.length), io.SeekStart)
+		if h.length <= t.maxString {
+			t.buf.Write(t.data[start:t.offset()])
+		} else if kind == Bytes {
+			t.marker("<%d bytes>", h.length)
+		} else {
+			cut := t.maxString
+			for cut > 0 && !utf8.RuneStart(t.data[body+cut]) {
+				cut--
+			}
+			// 可信配置写出的字符串可能不是合法的 UTF-8，标记必须是合法的字符串
+			t.marker("%s…(+%d bytes)", strings.ToValidUTF8(string(t.data[body:body+cut]), "\uFFFD"), h.length-cut)
+		}
+		return nil
+	}
+
+	t.reader.Seek(int64(start), io.SeekStart)
+	if err := t.poc.skipValue(t.reader, depth); err != nil {
+		return err
+	}
+	t.buf.Write(t.data[start:t.offset()])
+	return nil
+}
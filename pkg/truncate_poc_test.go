@@ -0,0 +1,47 @@
+package poculum
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTruncateForLog(t *testing.T) {
+	small := MustDump(map[string]any{"id": 1})
+	if out, truncated := TruncateForLog(small, 100); truncated || !bytes.Equal(out, small) {
+		t.Fatalf("small document changed: %x", out)
+	}
+
+	items := make([]any, 1000)
+	for i := range items {
+		items[i] = map[string]any{"n": i, "note": strings.Repeat("é", 100)}
+	}
+	big := MustDump(map[string]any{
+		"text":  strings.Repeat("x", 5000),
+		"blob":  make([]byte, 5000),
+		"items": items,
+	})
+	for _, limit := range []int{4096, 512, 64} {
+		out, truncated := TruncateForLog(big, limit)
+		if !truncated || len(out) > limit {
+			t.Fatalf("limit %d: got %d bytes, truncated=%v", limit, len(out), truncated)
+		}
+		if _, err := LoadPoculum(out); err != nil {
+			t.Fatalf("limit %d: invalid output: %v", limit, err)
+		}
+	}
+
+	out, _ := TruncateForLog(big, 4096)
+	m := MustLoad(out).(map[string]any)
+	if m["blob"] != "<5000 bytes>" || !strings.Contains(m["text"].(string), "x…(+") {
+		t.Fatalf("markers: blob=%v text=%.40v", m["blob"], m["text"])
+	}
+	list := m["items"].([]any)
+	if last := list[len(list)-1]; !strings.HasPrefix(last.(string), "…(+") {
+		t.Fatalf("list marker: %v", last)
+	}
+
+	if out, truncated := TruncateForLog([]byte{0x71, 0x32}, 100); !truncated || !strings.HasPrefix(MustLoad(out).(string), "<invalid poculum data") {
+		t.Fatalf("invalid data: %x", out)
+	}
+}
@@ -0,0 +1,124 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Tuple 固定元素个数的元组，编码为带有元组扩展标识的 list，解码时也会得到 Tuple
+//
+// 需要确定元素类型时使用 Tuple2、Tuple3、Tuple4，元素个数不符时解码返回错误
+type Tuple []any
+
+// Tuple2 两个元素的元组，例如坐标或者键值对
+type Tuple2[A, B any] struct {
+	V1 A
+	V2 B
+}
+
+// Tuple3 三个元素的元组
+type Tuple3[A, B, C any] struct {
+	V1 A
+	V2 B
+	V3 C
+}
+
+// Tuple4 四个元素的元组
+type Tuple4[A, B, C, D any] struct {
+	V1 A
+	V2 B
+	V3 C
+	V4 D
+}
+
+// NewTuple2 创建两个元素的元组
+func NewTuple2[A, B any](v1 A, v2 B) Tuple2[A, B] {
+	return Tuple2[A, B]{v1, v2}
+}
+
+// NewTuple3 创建三个元素的元组
+func NewTuple3[A, B, C any](v1 A, v2 B, v3 C) Tuple3[A, B, C] {
+	return Tuple3[A, B, C]{v1, v2, v3}
+}
+
+// NewTuple4 创建四个元素的元组
+func NewTuple4[A, B, C, D any](v1 A, v2 B, v3 C, v4 D) Tuple4[A, B, C, D] {
+	return Tuple4[A, B, C, D]{v1, v2, v3, v4}
+}
+
+func (t Tuple2[A, B]) tupleItems() []any       { return []any{t.V1, t.V2} }
+func (t Tuple3[A, B, C]) tupleItems() []any    { return []any{t.V1, t.V2, t.V3} }
+func (t Tuple4[A, B, C, D]) tupleItems() []any { return []any{t.V1, t.V2, t.V3, t.V4} }
+
+func (t *Tuple2[A, B]) tupleTargets() []reflect.Value {
+	return []reflect.Value{reflect.ValueOf(&t.V1).Elem(), reflect.ValueOf(&t.V2).Elem()}
+}
+
+func (t *Tuple3[A, B, C]) tupleTargets() []reflect.Value {
+	return []reflect.Value{reflect.ValueOf(&t.V1).Elem(), reflect.ValueOf(&t.V2).Elem(), reflect.ValueOf(&t.V3).Elem()}
+}
+
+func (t *Tuple4[A, B, C, D]) tupleTargets() []reflect.Value {
+	return []reflect.Value{reflect.ValueOf(&t.V1).Elem(), reflect.ValueOf(&t.V2).Elem(), reflect.ValueOf(&t.V3).Elem(), reflect.ValueOf(&t.V4).Elem()}
+}
+
+// typedTuple 由 Tuple2、Tuple3、Tuple4 实现，用于编码
+type typedTuple interface {
+	tupleItems() []any
+}
+
+// tupleTarget 由 *Tuple2、*Tuple3、*Tuple4 实现，用于解码
+type tupleTarget interface {
+	tupleTargets() []reflect.Value
+}
+
+// encodeTuple 编码元组
+func (poc *Poculum) encodeTuple(items []any, buf *bytes.Buffer, depth int) error {
+	if len(items) > poc.maxContainerItems {
+		return newError(DataTooLarge, fmt.Sprintf("Tuple too long: %d items (max %d)", len(items), poc.maxContainerItems))
+	}
+
+	// 元组中的 list 总是使用普通的编码，不使用稀疏编码
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extTuple)
+	writeListHeader(buf, len(items))
+	for _, item := range items {
+		if err := poc.encodeValue(item, buf, depth+2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeTuple 解码元组中的 list
+func (poc *Poculum) decodeTuple(reader *bytes.Reader, depth int) (Tuple, error) {
+	length, err := expectList(reader, "Tuple")
+	if err != nil {
+		return nil, err
+	}
+	items, err := poc.decodeArray(reader, length, depth)
+	if err != nil {
+		return nil, err
+	}
+	return Tuple(items), nil
+}
+
+// assignTuple 把解码得到的元组或者 list 赋给 Tuple2、Tuple3、Tuple4
+func (poc *Poculum) assignTuple(target tupleTarget, src any, path string) error {
+	items, ok := asList(src)
+	if !ok {
+		return newError(TypeMismatch, fmt.Sprintf("%s: cannot decode %T into tuple", displayPath(path), src))
+	}
+	targets := target.tupleTargets()
+	if len(items) != len(targets) {
+		return newError(TypeMismatch, fmt.Sprintf("%s: tuple has %d items, expected %d", displayPath(path), len(items), len(targets)))
+	}
+	for i, item := range items {
+		if err := poc.assign(targets[i], item, joinPath(path, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
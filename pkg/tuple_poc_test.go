@@ -0,0 +1,32 @@
+package poculum
+
+import (
+	"testing"
+)
+
+func TestTuple(t *testing.T) {
+	type segment struct {
+		From Tuple2[float64, float64] `poculum:"from"`
+		To   Tuple2[float64, float64] `poculum:"to"`
+	}
+	in := segment{From: NewTuple2(1.5, 2.5), To: NewTuple2(-1.0, 0.0)}
+	data, err := DumpPoculum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, _ := LoadPoculum(data)
+	if _, ok := value.(map[string]any)["from"].(Tuple); !ok {
+		t.Fatalf("unexpected value: %#v", value)
+	}
+
+	var out segment
+	if err := Unmarshal(data, &out); err != nil || out != in {
+		t.Fatalf("got %+v, %v", out, err)
+	}
+
+	data, _ = DumpPoculum(map[string]any{"from": Tuple{1.0, 2.0, 3.0}})
+	if err := Unmarshal(data, &out); err == nil {
+		t.Fatal("expected arity error")
+	}
+}
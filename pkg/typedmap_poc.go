@@ -0,0 +1,177 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// 类型确定的 map
+/*
+	值的类型都相同的 map 可以直接解码为对应类型的 Go map，不经过 map[string]any 与 Unmarshal 的反射：
+
+	labels, err := poculum.DecodeStringMap(data)   // map[string]string
+	counts, err := poculum.DecodeInt64Map(data)    // map[string]int64
+
+整数按照 Unmarshal 的规则转换，超出范围时返回错误；整数可以解码为 float64。
+WithKeyMapper 对键生效，AfterDecode 钩子不调用
+*/
+
+// decodeTypedMap 把 data 中顶层的 map 解码为 map[string]V，conv 从 reader 中解码一个值
+func decodeTypedMap[V any](poc *Poculum, data []byte, conv func(reader *bytes.Reader, path string) (V, error)) (map[string]V, error) {
+	if poc.maxInputSize > 0 && len(data) > poc.maxInputSize {
+		return nil, newError(DataTooLarge, fmt.Sprintf("Input too large: %d bytes (max %d)", len(data), poc.maxInputSize))
+	}
+
+	reader := bytes.NewReader(data)
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isMap() {
+		return nil, newError(TypeMismatch, fmt.Sprintf("expected map, got %s", typeName(h.typeByte)))
+	}
+	if h.length > poc.maxContainerItems {
+		return nil, newError(DataTooLarge, fmt.Sprintf("Object length too large: %d items (max %d)", h.length, poc.maxContainerItems))
+	}
+
+	m := make(map[string]V, min(h.length, reader.Len()/2))
+	for i := 0; i < h.length; i++ {
+		key, err := poc.readKey(reader)
+		if err != nil {
+			return nil, err
+		}
+		if poc.keyMapper != nil {
+			mapped := poc.keyMapper(key)
+			if _, dup := m[mapped]; dup {
+				return nil, newError(DuplicateItem, fmt.Sprintf("Duplicate key %q after mapping %q", mapped, key))
+			}
+			key = mapped
+		}
+		if m[key], err = conv(reader, key); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// decodeScalar 解码 map 中的一个值，由调用方检查类型
+func (poc *Poculum) decodeScalar(reader *bytes.Reader) (any, error) {
+	return poc.decodeValue(reader, 1)
+}
+
+// DecodeStringMap 把值都是字符串的 map 解码为 map[string]string
+func (poc *Poculum) DecodeStringMap(data []byte) (map[string]string, error) {
+	return decodeTypedMap(poc, data, func(reader *bytes.Reader, path string) (string, error) {
+		h, err := readHeader(reader)
+		if err != nil {
+			return "", err
+		}
+		isString := (h.typeByte >= typeFixStringBase && h.typeByte <= typeFixStringBase+15) ||
+			h.typeByte == typeString16 || h.typeByte == typeString32
+		if !isString {
+			return "", newError(TypeMismatch, fmt.Sprintf("%s: expected string, got %s", path, typeName(h.typeByte)))
+		}
+		return poc.decodeString(reader, h.length)
+	})
+}
+
+// DecodeInt64Map 把值都是整数的 map 解码为 map[string]int64
+func (poc *Poculum) DecodeInt64Map(data []byte) (map[string]int64, error) {
+	return decodeTypedMap(poc, data, func(reader *bytes.Reader, path string) (int64, error) {
+		v, err := poc.decodeScalar(reader)
+		if err != nil {
+			return 0, err
+		}
+		n, ok := toInt64(v)
+		if !ok {
+			if isInteger(v) {
+				return 0, newError(ValueOutOfRange, fmt.Sprintf("%s: %v overflows int64", path, v))
+			}
+			return 0, newError(TypeMismatch, fmt.Sprintf("%s: expected integer, got %T", path, v))
+		}
+		return n, nil
+	})
+}
+
+// DecodeUint64Map 把值都是非负整数的 map 解码为 map[string]uint64
+func (poc *Poculum) DecodeUint64Map(data []byte) (map[string]uint64, error) {
+	return decodeTypedMap(poc, data, func(reader *bytes.Reader, path string) (uint64, error) {
+		v, err := poc.decodeScalar(reader)
+		if err != nil {
+			return 0, err
+		}
+		n, ok := toUint64(v)
+		if !ok {
+			if isInteger(v) {
+				return 0, newError(ValueOutOfRange, fmt.Sprintf("%s: %v overflows uint64", path, v))
+			}
+			return 0, newError(TypeMismatch, fmt.Sprintf("%s: expected integer, got %T", path, v))
+		}
+		return n, nil
+	})
+}
+
+// DecodeFloat64Map 把值都是数字的 map 解码为 map[string]float64
+func (poc *Poculum) DecodeFloat64Map(data []byte) (map[string]float64, error) {
+	return decodeTypedMap(poc, data, func(reader *bytes.Reader, path string) (float64, error) {
+		v, err := poc.decodeScalar(reader)
+		if err != nil {
+			return 0, err
+		}
+		switch f := v.(type) {
+		case float64:
+			return f, nil
+		case float32:
+			return float64(f), nil
+		}
+		if n, ok := toInt64(v); ok {
+			return float64(n), nil
+		}
+		if n, ok := v.(uint64); ok {
+			return float64(n), nil
+		}
+		return 0, newError(TypeMismatch, fmt.Sprintf("%s: expected number, got %T", path, v))
+	})
+}
+
+// DecodeBoolMap 把值都是布尔值的 map 解码为 map[string]bool
+func (poc *Poculum) DecodeBoolMap(data []byte) (map[string]bool, error) {
+	return decodeTypedMap(poc, data, func(reader *bytes.Reader, path string) (bool, error) {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return false, newError(InsufficientData, "No type byte")
+		}
+		switch b {
+		case typeTrue:
+			return true, nil
+		case typeFalse:
+			return false, nil
+		}
+		return false, newError(TypeMismatch, fmt.Sprintf("%s: expected bool, got %s", path, typeName(b)))
+	})
+}
+
+// DecodeStringMap 使用默认实例把 map 解码为 map[string]string
+func DecodeStringMap(data []byte) (map[string]string, error) {
+	return Default().DecodeStringMap(data)
+}
+
+// DecodeInt64Map 使用默认实例把 map 解码为 map[string]int64
+func DecodeInt64Map(data []byte) (map[string]int64, error) {
+	return Default().DecodeInt64Map(data)
+}
+
+// DecodeUint64Map 使用默认实例把 map 解码为 map[string]uint64
+func DecodeUint64Map(data []byte) (map[string]uint64, error) {
+	return Default().DecodeUint64Map(data)
+}
+
+// DecodeFloat64Map 使用默认实例把 map 解码为 map[string]float64
+func DecodeFloat64Map(data []byte) (map[string]float64, error) {
+	return Default().DecodeFloat64Map(data)
+}
+
+// DecodeBoolMap 使用默认实例把 map 解码为 map[string]bool
+func DecodeBoolMap(data []byte) (map[string]bool, error) {
+	return Default().DecodeBoolMap(data)
+}
@@ -0,0 +1,35 @@
+package poculum
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeTypedMaps(t *testing.T) {
+	data, _ := DumpPoculum(map[string]any{"a": uint8(1), "b": int64(-2), "c": uint32(3)})
+	ints, err := DecodeInt64Map(data)
+	if err != nil || !reflect.DeepEqual(ints, map[string]int64{"a": 1, "b": -2, "c": 3}) {
+		t.Fatalf("got %v, %v", ints, err)
+	}
+	floats, err := DecodeFloat64Map(data)
+	if err != nil || floats["b"] != -2 {
+		t.Fatalf("got %v, %v", floats, err)
+	}
+	if _, err := DecodeUint64Map(data); !IsKind(err, ValueOutOfRange) {
+		t.Fatalf("expected ValueOutOfRange, got %v", err)
+	}
+	if _, err := DecodeStringMap(data); !IsKind(err, TypeMismatch) {
+		t.Fatalf("expected TypeMismatch, got %v", err)
+	}
+
+	data, _ = DumpPoculum(map[string]string{"x": "1", "y": "2"})
+	strs, err := DecodeStringMap(data)
+	if err != nil || !reflect.DeepEqual(strs, map[string]string{"x": "1", "y": "2"}) {
+		t.Fatalf("got %v, %v", strs, err)
+	}
+	data, _ = DumpPoculum(map[string]bool{"on": true, "off": false})
+	bools, err := DecodeBoolMap(data)
+	if err != nil || !bools["on"] || bools["off"] || len(bools) != 2 {
+		t.Fatalf("got %v, %v", bools, err)
+	}
+}
@@ -0,0 +1,117 @@
+package poculum
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Union 用一个判别字段区分多种消息类型
+/*
+	每种消息类型都是一个 struct，编码时在 map 中额外写入判别字段，值为注册时使用的名称：
+
+	u := poculum.NewUnion("kind")
+	u.Register("login", LoginEvent{})
+	u.Register("logout", LogoutEvent{})
+
+	data, err := u.Dump(LoginEvent{User: "a"})  // {"kind": "login", "user": "a"}
+	value, err := u.Load(data)                   // value 为 *LoginEvent
+*/
+type Union struct {
+	poc           *Poculum
+	discriminator string
+	variants      map[string]reflect.Type
+	names         map[reflect.Type]string
+}
+
+// NewUnion 创建使用 discriminator 作为判别字段的 Union
+func (poc *Poculum) NewUnion(discriminator string) *Union {
+	return &Union{
+		poc:           poc,
+		discriminator: discriminator,
+		variants:      make(map[string]reflect.Type),
+		names:         make(map[reflect.Type]string),
+	}
+}
+
+// NewUnion 使用默认配置创建 Union
+func NewUnion(discriminator string) *Union {
+	return Default().NewUnion(discriminator)
+}
+
+// Register 注册一种消息类型，prototype 是该类型的 struct 值或者指针，name 是写入判别字段的名称
+func (u *Union) Register(name string, prototype any) error {
+	t := reflect.TypeOf(prototype)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return newError(InvalidUnion, fmt.Sprintf("Union variant must be a struct, got %T", prototype))
+	}
+	if _, dup := u.variants[name]; dup {
+		return newError(InvalidUnion, fmt.Sprintf("Duplicate union variant %q", name))
+	}
+	if _, dup := u.names[t]; dup {
+		return newError(InvalidUnion, fmt.Sprintf("Type %s is already registered", t))
+	}
+	for _, f := range cachedFields(t) {
+		if f.name == u.discriminator {
+			return newError(InvalidUnion, fmt.Sprintf("Field %s.%s conflicts with discriminator %q", t, f.name, u.discriminator))
+		}
+	}
+
+	u.variants[name] = t
+	u.names[t] = name
+	return nil
+}
+
+// Dump 编码一个已注册类型的值，并写入判别字段
+func (u *Union) Dump(value any) ([]byte, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, newError(UnknownVariant, "Cannot encode nil as union value")
+	}
+	name, ok := u.names[rv.Type()]
+	if !ok {
+		return nil, newError(UnknownVariant, fmt.Sprintf("Type %T is not registered in union", value))
+	}
+
+	values, err := structToMap(rv)
+	if err != nil {
+		return nil, err
+	}
+	if u.poc.redactor != nil {
+		u.poc.redactFields(rv.Type(), values)
+	}
+	values[u.discriminator] = name
+	return u.poc.dump(values)
+}
+
+// Load 根据判别字段解码出对应类型的值，返回指向该类型的指针
+func (u *Union) Load(data []byte) (any, error) {
+	value, err := u.poc.load(data)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return nil, newError(TypeMismatch, fmt.Sprintf("Union value must be a map, got %T", value))
+	}
+
+	name, ok := obj[u.discriminator].(string)
+	if !ok {
+		return nil, newError(UnknownVariant, fmt.Sprintf("Missing or invalid discriminator %q", u.discriminator))
+	}
+	t, ok := u.variants[name]
+	if !ok {
+		return nil, newError(UnknownVariant, fmt.Sprintf("Unknown union variant %q", name))
+	}
+
+	dst := reflect.New(t)
+	if err := u.poc.assignStruct(dst.Elem(), obj, ""); err != nil {
+		return nil, err
+	}
+	return dst.Interface(), nil
+}
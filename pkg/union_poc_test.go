@@ -0,0 +1,60 @@
+package poculum
+
+import (
+	"testing"
+)
+
+func TestUnion(t *testing.T) {
+	type login struct {
+		User string `poculum:"user"`
+	}
+	type logout struct {
+		User   string `poculum:"user"`
+		Reason string `poculum:"reason"`
+	}
+
+	u := NewUnion("kind")
+	if err := u.Register("login", login{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Register("logout", &logout{}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := u.Dump(&logout{User: "a", Reason: "idle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := u.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := value.(*logout); !ok || got.Reason != "idle" {
+		t.Fatalf("unexpected value: %#v", value)
+	}
+
+	data, _ = DumpPoculum(map[string]any{"kind": "unknown"})
+	if _, err := u.Load(data); err == nil {
+		t.Fatal("expected error for unknown variant")
+	}
+}
+
+func TestUnionRedaction(t *testing.T) {
+	type account struct {
+		Name string `poculum:"name"`
+		SSN  string `poculum:"ssn,redact"`
+	}
+
+	u := NewPoculum(WithRedaction(nil)).NewUnion("kind")
+	if err := u.Register("account", account{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := u.Dump(account{Name: "ann", SSN: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := MustLoad(data).(map[string]any)
+	if m["ssn"] != Redacted || m["name"] != "ann" || m["kind"] != "account" {
+		t.Fatalf("got %v", m)
+	}
+}
@@ -0,0 +1,558 @@
+package poculum
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// joinPath 拼接字段路径，用于错误信息
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// displayPath 返回用于错误信息的路径，顶层值显示为 (root)
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+func mismatchError(path string, src any, dst reflect.Value) error {
+	return newError(TypeMismatch, fmt.Sprintf("%s: cannot decode %T into %s", displayPath(path), src, dst.Type()))
+}
+
+func overflowError(path string, src any, dst reflect.Value) error {
+	var bounds string
+	switch {
+	case dst.CanInt():
+		low, high := intRange(dst.Type())
+		bounds = fmt.Sprintf(" (range %d..%d)", low, high)
+	case dst.CanUint():
+		bounds = fmt.Sprintf(" (range 0..%d)", uintMax(dst.Type()))
+	}
+	return newError(ValueOutOfRange, fmt.Sprintf("%s: value %v overflows %s%s", displayPath(path), src, dst.Type(), bounds))
+}
+
+// intRange 返回有符号整数类型的取值范围
+func intRange(t reflect.Type) (int64, int64) {
+	bits := t.Bits()
+	return -1 << (bits - 1), 1<<(bits-1) - 1
+}
+
+// uintMax 返回无符号整数类型的最大值
+func uintMax(t reflect.Type) uint64 {
+	return math.MaxUint64 >> (64 - t.Bits())
+}
+
+// WithIntBools 解码到 bool 时接受整数 0 与 1，用于读取旧的 MessageBox 编码器生成的没有 true、false 类型的数据。
+// 其它整数返回 ValueOutOfRange 错误，只影响解码到 bool 字段，Load 仍然得到整数
+func WithIntBools() ConfigOption {
+	return func(poc *Poculum) {
+		poc.intBools = true
+	}
+}
+
+// WithSaturatingInts 解码到整数字段时超出范围的值取最接近的边界值，而不是返回 ValueOutOfRange 错误，
+// 例如 300 解码到 uint8 得到 255，-1 得到 0。适合允许传感器读数溢出的遥测数据
+func WithSaturatingInts() ConfigOption {
+	return func(poc *Poculum) {
+		poc.saturateInts = true
+	}
+}
+
+// isInteger 判断解码得到的值是否是整数
+func isInteger(src any) bool {
+	switch src.(type) {
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return true
+	}
+	return false
+}
+
+// toInt64 把解码得到的整数转换为 int64，src 不是整数或者超出 int64 范围时返回 false
+func toInt64(src any) (int64, bool) {
+	switch v := src.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// toUint64 把解码得到的整数转换为 uint64，src 不是整数或者是负数时返回 false
+func toUint64(src any) (uint64, bool) {
+	if v, ok := src.(uint64); ok {
+		return v, true
+	}
+	n, ok := toInt64(src)
+	if !ok || n < 0 {
+		return 0, false
+	}
+	return uint64(n), true
+}
+
+// assign 把解码得到的值 src 赋给 dst
+func (poc *Poculum) assign(dst reflect.Value, src any, path string) error {
+	if ok, err := poc.assignCustom(dst, src, path); ok {
+		return err
+	}
+	if dst.CanAddr() {
+		if target, ok := dst.Addr().Interface().(optionalTarget); ok {
+			return poc.assignOption(target, src, path)
+		}
+		if target, ok := dst.Addr().Interface().(tupleTarget); ok && src != nil {
+			return poc.assignTuple(target, src, path)
+		}
+		if ok, err := poc.assignScanner(dst, src, path); ok {
+			return err
+		}
+	}
+	if src == nil {
+		return poc.assignNil(dst, path)
+	}
+	if info, ok := poc.enums[dst.Type()]; ok {
+		return poc.assignEnum(info, dst, src, path)
+	}
+
+	// 解码得到的值类型与目标一致时直接赋值，例如 *Tensor
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) && dst.Kind() != reflect.Interface {
+		dst.Set(sv)
+		return nil
+	}
+	if sv.Kind() == reflect.Pointer && sv.Elem().Type() == dst.Type() {
+		dst.Set(sv.Elem())
+		return nil
+	}
+	if assignNetIP(dst, src) {
+		return nil
+	}
+	if raw, ok := src.(json.RawMessage); ok && dst.Kind() != reflect.Interface {
+		return assignJSON(dst, raw, path)
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		if !sv.Type().AssignableTo(dst.Type()) {
+			return mismatchError(path, src, dst)
+		}
+		dst.Set(sv)
+	case reflect.Pointer:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return poc.assign(dst.Elem(), src, path)
+	case reflect.Bool:
+		b, ok := src.(bool)
+		if !ok && poc.intBools && isInteger(src) {
+			n, isUint := toUint64(src)
+			if !isUint || n > 1 {
+				return overflowError(path, src, dst)
+			}
+			b, ok = n == 1, true
+		}
+		if !ok {
+			return mismatchError(path, src, dst)
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isInteger(src) {
+			return mismatchError(path, src, dst)
+		}
+		n, ok := toInt64(src)
+		if !ok || dst.OverflowInt(n) {
+			if !poc.saturateInts {
+				return overflowError(path, src, dst)
+			}
+			// 只有大于 math.MaxInt64 的 uint64 无法转换为 int64
+			low, high := intRange(dst.Type())
+			n = min(max(n, low), high)
+			if !ok {
+				n = high
+			}
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !isInteger(src) {
+			return mismatchError(path, src, dst)
+		}
+		n, ok := toUint64(src)
+		if !ok || dst.OverflowUint(n) {
+			if !poc.saturateInts {
+				return overflowError(path, src, dst)
+			}
+			// 只有负数无法转换为 uint64
+			n = min(n, uintMax(dst.Type()))
+			if !ok {
+				n = 0
+			}
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch v := src.(type) {
+		case float32:
+			f = float64(v)
+		case float64:
+			f = v
+		default:
+			if n, ok := toInt64(src); ok {
+				f = float64(n)
+			} else if n, ok := toUint64(src); ok {
+				f = float64(n)
+			} else {
+				return mismatchError(path, src, dst)
+			}
+		}
+		dst.SetFloat(f)
+	case reflect.String:
+		s, ok := src.(string)
+		if !ok {
+			return mismatchError(path, src, dst)
+		}
+		dst.SetString(s)
+	case reflect.Slice:
+		if b, ok := src.([]byte); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		list, ok := asList(src)
+		if !ok {
+			return mismatchError(path, src, dst)
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(list), len(list))
+		for i, item := range list {
+			if err := poc.assign(slice.Index(i), item, joinPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case reflect.Array:
+		list, ok := asList(src)
+		if !ok {
+			return mismatchError(path, src, dst)
+		}
+		if len(list) > dst.Len() {
+			return newError(ValueOutOfRange, fmt.Sprintf("%s: %d items do not fit in %s", displayPath(path), len(list), dst.Type()))
+		}
+		for i := 0; i < dst.Len(); i++ {
+			if i >= len(list) {
+				dst.Index(i).Set(reflect.Zero(dst.Type().Elem()))
+				continue
+			}
+			if err := poc.assign(dst.Index(i), list[i], joinPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if isSetMap(dst.Type()) {
+			switch items := src.(type) {
+			case Set:
+				return poc.assignSetMap(dst, items, path)
+			case []any:
+				return poc.assignSetMap(dst, items, path)
+			}
+		}
+		if sv.Kind() == reflect.Map && isIntKind(sv.Type().Key().Kind()) && isIntKind(dst.Type().Key().Kind()) {
+			return poc.assignIntMap(dst, sv, path)
+		}
+		obj, ok := src.(map[string]any)
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return mismatchError(path, src, dst)
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), len(obj))
+		elemType := dst.Type().Elem()
+		for key, item := range obj {
+			elem := reflect.New(elemType).Elem()
+			if err := poc.assign(elem, item, joinPath(path, key)); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(m)
+	case reflect.Struct:
+		obj, ok := src.(map[string]any)
+		if !ok {
+			return mismatchError(path, src, dst)
+		}
+		return poc.assignStruct(dst, obj, path)
+	default:
+		return mismatchError(path, src, dst)
+	}
+
+	return nil
+}
+
+// asList 把解码得到的 list、Set、Tuple 或者 []bool 转换为 []any
+func asList(src any) ([]any, bool) {
+	switch v := src.(type) {
+	case []any:
+		return v, true
+	case Set:
+		return []any(v), true
+	case Tuple:
+		return []any(v), true
+	case []bool:
+		list := make([]any, len(v))
+		for i, b := range v {
+			list[i] = b
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// assignSetMap 把集合或者 list 解码到 map[T]struct{} 中，list 中重复的元素只保留一个
+func (poc *Poculum) assignSetMap(dst reflect.Value, items []any, path string) error {
+	m := reflect.MakeMapWithSize(dst.Type(), len(items))
+	present := reflect.New(dst.Type().Elem()).Elem()
+	for i, item := range items {
+		key := reflect.New(dst.Type().Key()).Elem()
+		if err := poc.assign(key, item, joinPath(path, strconv.Itoa(i))); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, present)
+	}
+	dst.Set(m)
+	return nil
+}
+
+// WithCaseInsensitiveKeys 解码到 struct 时键名不区分大小写，完全相同的键优先。
+// 只比较大小写，created_at 与 createdAt 仍然是不同的键
+func WithCaseInsensitiveKeys() ConfigOption {
+	return func(poc *Poculum) {
+		poc.foldKeys = true
+	}
+}
+
+// foldedKeys 把 map 的键按照小写分组，用于不区分大小写的匹配
+func foldedKeys(obj map[string]any) map[string][]string {
+	folded := make(map[string][]string, len(obj))
+	for key := range obj {
+		lower := strings.ToLower(key)
+		folded[lower] = append(folded[lower], key)
+	}
+	return folded
+}
+
+// lookupFold 不区分大小写地查找键，多个键只有大小写不同时返回错误
+func lookupFold(obj map[string]any, folded map[string][]string, name, path string) (any, bool, error) {
+	keys := folded[strings.ToLower(name)]
+	switch len(keys) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return obj[keys[0]], true, nil
+	}
+	sort.Strings(keys)
+	return nil, false, newError(ValidationError, fmt.Sprintf("%s: ambiguous keys %q", path, keys))
+}
+
+// Defaulter 由需要默认值的 struct 的指针实现，Defaults 在零值上设置字段的默认值。
+// 解码时数据中不存在并且没有 default 标签的字段使用 Defaults 设置的非零值，适合切片、map、time.Duration 等标签无法表示的默认值
+type Defaulter interface {
+	Defaults()
+}
+
+var defaulterType = reflect.TypeOf((*Defaulter)(nil)).Elem()
+
+// structDefaults 返回 Defaults 设置了默认值的 struct，t 没有实现 Defaulter 时返回无效的 reflect.Value
+func structDefaults(t reflect.Type) reflect.Value {
+	if !reflect.PointerTo(t).Implements(defaulterType) {
+		return reflect.Value{}
+	}
+	defaults := reflect.New(t)
+	defaults.Interface().(Defaulter).Defaults()
+	return defaults.Elem()
+}
+
+// assignStruct 把解码得到的 map 赋给 struct，键不存在时依次尝试 alias，都不存在时使用 default 标签或者 Defaults 的值。
+// 启用 WithCaseInsensitiveKeys 时，精确匹配的键名与 alias 都不存在才进行不区分大小写的匹配
+func (poc *Poculum) assignStruct(dst reflect.Value, obj map[string]any, path string) error {
+	var folded map[string][]string
+	defaults := structDefaults(dst.Type())
+	for _, f := range cachedFields(dst.Type()) {
+		fieldPath := joinPath(path, f.name)
+		if f.tagErr != nil {
+			return newError(InvalidTag, fmt.Sprintf("%s: %v", fieldPath, f.tagErr))
+		}
+
+		value, found := obj[f.name]
+		for i := 0; !found && i < len(f.aliases); i++ {
+			value, found = obj[f.aliases[i]]
+		}
+		if !found && poc.foldKeys {
+			if folded == nil {
+				folded = foldedKeys(obj)
+			}
+			names := append([]string{f.name}, f.aliases...)
+			for i := 0; !found && i < len(names); i++ {
+				var err error
+				if value, found, err = lookupFold(obj, folded, names[i], fieldPath); err != nil {
+					return err
+				}
+			}
+		}
+
+		if f.required && (!found || value == nil) {
+			return newError(ValidationError, fmt.Sprintf("%s: required field is missing", fieldPath))
+		}
+		if !found && !f.hasDefault {
+			if defaults.IsValid() {
+				if value, ok := fieldByIndex(defaults, f.index); ok && !value.IsZero() {
+					fieldByIndexAlloc(dst, f.index).Set(value)
+				}
+			}
+			continue
+		}
+
+		// 只在需要写入时分配嵌入的指针
+		field := fieldByIndexAlloc(dst, f.index)
+		if !found {
+			if err := setDefault(field, f.defaultTag); err != nil {
+				return newError(InvalidTag, fmt.Sprintf("%s: %v", fieldPath, err))
+			}
+		} else {
+			if f.format != "" && value != nil {
+				parsed, err := parseFormat(f.format, value, field.Type())
+				if err != nil {
+					return newError(TypeMismatch, fmt.Sprintf("%s: %v", fieldPath, err))
+				}
+				value = parsed
+			}
+			if err := poc.assign(field, value, fieldPath); err != nil {
+				return err
+			}
+		}
+
+		if err := checkBounds(field, f, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBounds 检查字段是否满足 min、max 标签的限制
+func checkBounds(field reflect.Value, f fieldInfo, path string) error {
+	if f.min == nil && f.max == nil {
+		return nil
+	}
+	for field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	var value float64
+	what := "value"
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(field.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = field.Float()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		value = float64(field.Len())
+		what = "length"
+	default:
+		return newError(InvalidTag, fmt.Sprintf("%s: min/max is not supported for %s", path, field.Type()))
+	}
+
+	if f.min != nil && value < *f.min {
+		return newError(ValidationError, fmt.Sprintf("%s: %s %v is less than min %v", path, what, value, *f.min))
+	}
+	if f.max != nil && value > *f.max {
+		return newError(ValidationError, fmt.Sprintf("%s: %s %v is greater than max %v", path, what, value, *f.max))
+	}
+	return nil
+}
+
+// setDefault 把标签中的默认值赋给字段
+func setDefault(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(field.Type().Elem())
+		if err := setDefault(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.String:
+		field.SetString(value)
+	default:
+		return fmt.Errorf("default value is not supported for %s", field.Type())
+	}
+	return nil
+}
+
+// Unmarshal 把 data 解码到 dst 指向的值中，dst 必须是非 nil 的指针
+//
+// map 可以解码到 struct 或者 map[string]T 中，整数可以解码到任意宽度的整数或者浮点数字段中，
+// 超出目标类型范围时返回错误
+func (poc *Poculum) Unmarshal(data []byte, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return newError(InvalidTarget, fmt.Sprintf("Unmarshal target must be a non-nil pointer, got %T", dst))
+	}
+
+	value, err := poc.load(data)
+	if err != nil {
+		return err
+	}
+	return poc.assign(rv.Elem(), value, "")
+}
+
+// Unmarshal 使用默认配置把 data 解码到 dst 指向的值中
+func Unmarshal(data []byte, dst any) error {
+	return Default().Unmarshal(data, dst)
+}
@@ -0,0 +1,40 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+)
+
+// URL 扩展类型
+/*
+	*url.URL 编码为带有 URL 扩展标识的字符串，解码时使用 url.Parse 校验并得到 *url.URL，
+无法解析的字符串返回 InvalidURL 错误。nil 的 *url.URL 编码为 nil
+*/
+
+// encodeURL 编码 URL
+func (poc *Poculum) encodeURL(u *url.URL, buf *bytes.Buffer) error {
+	if u == nil {
+		return buf.WriteByte(typeNil)
+	}
+	buf.WriteByte(typeExt)
+	buf.WriteByte(extURL)
+	return poc.encodeString(u.String(), buf)
+}
+
+// decodeURL 解码 URL
+func (poc *Poculum) decodeURL(reader *bytes.Reader, depth int) (*url.URL, error) {
+	value, err := poc.decodeValue(reader, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, newError(TypeMismatch, fmt.Sprintf("URL must contain a string, got %T", value))
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, newError(InvalidURL, err.Error())
+	}
+	return u, nil
+}
@@ -0,0 +1,33 @@
+package poculum
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	type Link struct {
+		Href *url.URL
+		Base url.URL
+	}
+	href, _ := url.Parse("https://example.com/a?b=c#d")
+	base, _ := url.Parse("https://example.com/")
+	data, err := DumpPoculum(Link{Href: href, Base: *base})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out Link
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Href.String() != href.String() || out.Base.String() != base.String() {
+		t.Fatalf("got %v %v", out.Href, &out.Base)
+	}
+
+	str, _ := DumpPoculum("://bad")
+	bad := append([]byte{typeExt, extURL}, str...)
+	if _, err := LoadPoculum(bad); err == nil {
+		t.Fatal("expected error for invalid URL")
+	}
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package poculum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// watchFile 使用 inotify 监视 path 所在的目录，path 被写入、替换、创建或者删除时发送通知，
+// 多个通知在被接收之前合并为一个。出错后通道被关闭
+func watchFile(path string) (<-chan struct{}, func(), error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, nil, os.NewSyscallError("inotify_init1", err)
+	}
+	dir, name := filepath.Split(filepath.Clean(path))
+	if dir == "" {
+		dir = "."
+	}
+	const mask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_ATTRIB
+	if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		syscall.Close(fd)
+		return nil, nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	// 非阻塞的描述符由 runtime 轮询，Close 可以中断阻塞的 Read
+	f := os.NewFile(uintptr(fd), "inotify")
+	events := make(chan struct{}, 1)
+	go func() {
+		defer close(events)
+		buf := make([]byte, 64<<10)
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+				// struct inotify_event { int wd; uint32 mask; uint32 cookie; uint32 len; char name[]; }
+				evMask := binary.NativeEndian.Uint32(buf[off+4:])
+				nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+				off += syscall.SizeofInotifyEvent
+				evName := string(bytes.TrimRight(buf[off:min(off+nameLen, n)], "\x00"))
+				off += nameLen
+				if evName == name || evMask&syscall.IN_Q_OVERFLOW != 0 {
+					select {
+					case events <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}
+	}()
+	return events, func() { f.Close() }, nil
+}
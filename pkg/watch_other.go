@@ -0,0 +1,10 @@
+//go:build !linux
+
+package poculum
+
+import "errors"
+
+// watchFile 这个平台不支持文件系统通知，Watcher 使用轮询
+func watchFile(path string) (<-chan struct{}, func(), error) {
+	return nil, nil, errors.ErrUnsupported
+}
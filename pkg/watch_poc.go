@@ -0,0 +1,207 @@
+package poculum
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// 配置热加载
+/*
+	Watch 先把 path 处的文件解码到 dst 中，之后定期检查文件，内容变化时重新解码：
+
+	var cfg Config
+	w, err := poculum.Watch("app.poc", &cfg, func() { log.Print("config reloaded") })
+	defer w.Close()
+
+	w.RLock()
+	port := cfg.Port
+	w.RUnlock()
+
+新的内容先解码到一个新的值中，struct 标签的 required 等规则通过、并且实现了 Validate() error 的值校验通过之后，
+才在 Watcher 的写锁中整体替换 dst，读取方持有读锁时看到的总是一份完整的配置。
+解码或者校验失败时保留旧的配置，错误可以通过 Err 获取，文件被修复之后会再次加载。
+
+文件变化通过文件系统通知发现（Linux 上使用 inotify），监视的是文件所在的目录，
+SaveFile 等重命名覆盖的写入方式以及编辑器先删除再创建的保存方式都可以被发现。
+不支持通知的平台，或者通知不可靠的网络文件系统，使用轮询（见 WithPollInterval）
+*/
+
+// defaultPollInterval 无法使用文件系统通知时轮询的间隔
+const defaultPollInterval = time.Second
+
+// WatchOption 配置 Watcher 的选项
+type WatchOption func(*Watcher)
+
+// WithPollInterval 不使用文件系统通知，每隔 d 检查一次文件的修改时间与大小，
+// 适合 NFS 等不产生通知的文件系统
+func WithPollInterval(d time.Duration) WatchOption {
+	return func(w *Watcher) {
+		w.interval = d
+	}
+}
+
+// Watcher 监视配置文件，嵌入的读写锁保护被替换的 dst
+type Watcher struct {
+	sync.RWMutex
+
+	path     string
+	dst      reflect.Value
+	onChange func()
+	poc      *Poculum
+	interval time.Duration // 轮询的间隔，为 0 时使用文件系统通知
+
+	events <-chan struct{} // 文件系统通知，为 nil 时轮询
+	stop   func()          // 停止文件系统通知
+
+	mu      sync.Mutex // 保护 err
+	err     error
+	data    []byte
+	modTime time.Time
+	size    int64
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// Watch 使用默认实例把 path 处的文件解码到 dst 中，并在文件变化时重新解码
+func Watch(path string, dst any, onChange func(), opts ...WatchOption) (*Watcher, error) {
+	return Default().Watch(path, dst, onChange, opts...)
+}
+
+// Watch 把 path 处的文件解码到 dst 中，并在文件变化时重新解码，替换 dst 之后调用 onChange（可以为 nil）。
+// dst 必须是非 nil 的指针，第一次加载失败时返回错误，不启动监视
+func (poc *Poculum) Watch(path string, dst any, onChange func(), opts ...WatchOption) (*Watcher, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, newError(TypeMismatch, fmt.Sprintf("Watch target must be a non-nil pointer, got %T", dst))
+	}
+
+	w := &Watcher{
+		path:     path,
+		dst:      rv.Elem(),
+		onChange: onChange,
+		poc:      poc,
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.interval <= 0 {
+		// 在第一次加载之前开始监视，不会错过两者之间的修改
+		var err error
+		if w.events, w.stop, err = watchFile(path); err != nil {
+			w.interval = defaultPollInterval
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err == nil {
+		_, err = w.reload(info)
+	}
+	if err != nil {
+		if w.stop != nil {
+			w.stop()
+		}
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+// Err 返回最近一次重新加载的错误，加载成功后清空
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close 停止监视，等待正在进行的加载结束
+func (w *Watcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+	return nil
+}
+
+func (w *Watcher) run() {
+	defer w.wg.Done()
+	var tick <-chan time.Time
+	if w.events == nil {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	} else {
+		defer w.stop()
+	}
+
+	for {
+		notified := false
+		select {
+		case <-w.done:
+			return
+		case <-tick:
+		case _, ok := <-w.events:
+			if !ok {
+				// 通知出错后改为轮询
+				w.events = nil
+				ticker := time.NewTicker(defaultPollInterval)
+				defer ticker.Stop()
+				tick = ticker.C
+			}
+			notified = ok
+		}
+		w.check(notified)
+	}
+}
+
+// check 检查文件，没有收到通知时修改时间与大小都没有变化的文件不会重新读取
+func (w *Watcher) check(notified bool) {
+	info, err := os.Stat(w.path)
+	if err == nil && !notified && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return
+	}
+	changed := false
+	if err == nil {
+		changed, err = w.reload(info)
+	}
+	w.mu.Lock()
+	w.err = err
+	w.mu.Unlock()
+	if changed && w.onChange != nil {
+		w.onChange()
+	}
+}
+
+// reload 读取文件，内容变化并且校验通过时替换 dst，返回是否替换
+func (w *Watcher) reload(info os.FileInfo) (bool, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, err
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	if w.data != nil && bytes.Equal(data, w.data) {
+		return false, nil
+	}
+
+	fresh := reflect.New(w.dst.Type())
+	if err := w.poc.Unmarshal(data, fresh.Interface()); err != nil {
+		return false, err
+	}
+	if v, ok := fresh.Interface().(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return false, wrapError(ValidationError, fmt.Sprintf("Invalid config %s", w.path), err)
+		}
+	}
+
+	w.Lock()
+	w.dst.Set(fresh.Elem())
+	w.Unlock()
+	w.data = data
+	return true, nil
+}
@@ -0,0 +1,67 @@
+package poculum
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	t.Run("notify", func(t *testing.T) { testWatch(t) })
+	t.Run("poll", func(t *testing.T) { testWatch(t, WithPollInterval(5*time.Millisecond)) })
+}
+
+func testWatch(t *testing.T, opts ...WatchOption) {
+	type Config struct {
+		Addr    string `poculum:"addr,required"`
+		Workers int    `poculum:"workers,default=4"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.poc")
+	if err := SaveFile(path, map[string]any{"addr": ":80"}); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Config
+	changed := make(chan struct{}, 1)
+	w, err := Watch(path, &cfg, func() { changed <- struct{}{} }, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if cfg.Addr != ":80" || cfg.Workers != 4 {
+		t.Fatalf("initial config %+v", cfg)
+	}
+
+	if err := SaveFile(path, map[string]any{"addr": ":8080", "workers": 8}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not detected")
+	}
+	w.RLock()
+	got := cfg
+	w.RUnlock()
+	if got.Addr != ":8080" || got.Workers != 8 {
+		t.Fatalf("reloaded config %+v", got)
+	}
+
+	// 缺少必填字段的配置不会替换
+	if err := SaveFile(path, map[string]any{"workers": 16}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Err() == nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !IsKind(w.Err(), ValidationError) {
+		t.Fatalf("expected validation error, got %v", w.Err())
+	}
+	w.RLock()
+	got = cfg
+	w.RUnlock()
+	if got.Workers != 8 {
+		t.Fatalf("invalid config was applied: %+v", got)
+	}
+}